@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"sf_test/config"
+	"sf_test/internal/auth"
+	"sf_test/internal/core"
+	"sf_test/internal/db"
+)
+
+const apiKeysUsage = `Usage: main api-keys create [-workspace <slug>] -name <name> -scopes <scope,...>
+
+Creates an API key and prints it. The key is shown only once; store it safely.
+Use this to create the first key, which can then manage others over the API.
+
+Scopes: ` + "%s" + `
+`
+
+// runAPIKeysCommand implements the "api-keys" subcommand and returns the exit code.
+func runAPIKeysCommand(cfg *config.Config, args []string) int {
+	usage := func() { fmt.Fprintf(os.Stderr, apiKeysUsage, strings.Join(auth.Scopes, ", ")) }
+	if len(args) == 0 || args[0] != "create" {
+		usage()
+		return 2
+	}
+
+	flags := flag.NewFlagSet("api-keys create", flag.ContinueOnError)
+	name := flags.String("name", "", "name describing who uses the key")
+	scopes := flags.String("scopes", "", "comma-separated scopes granted to the key")
+	workspace := flags.String("workspace", "default", "slug of the workspace the key belongs to")
+	flags.Usage = usage
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	var granted []string
+	for _, scope := range strings.Split(*scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			granted = append(granted, scope)
+		}
+	}
+
+	dbConn, err := openDB(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer dbConn.Close()
+
+	ctx, err := workspaceContext(context.Background(), dbConn, *workspace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create API key: %v\n", err)
+		return 1
+	}
+	service := core.NewAPIKeyService(db.NewAPIKeyRepository(dbConn))
+	key, err := service.CreateAPIKey(ctx, *name, granted)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create API key: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Created API key %d (%s) with scopes %s:\n%s\n", key.ID, key.Name, strings.Join(key.Scopes, ","), key.Key)
+	return 0
+}
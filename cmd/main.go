@@ -1,17 +1,27 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 
 	"sf_test/config"
 	"sf_test/internal/api"
+	"sf_test/internal/auth"
+	"sf_test/internal/clock"
 	"sf_test/internal/core"
 	"sf_test/internal/db"
+	"sf_test/pkg/email"
 	"sf_test/pkg/logger"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -28,52 +38,155 @@ func main() {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
 
+	// Subcommands run against the database and exit instead of serving HTTP
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "sequences":
+			os.Exit(runSequencesCommand(cfg, os.Args[2:]))
+		case "api-keys":
+			os.Exit(runAPIKeysCommand(cfg, os.Args[2:]))
+		case "workspaces":
+			os.Exit(runWorkspacesCommand(cfg, os.Args[2:]))
+		case "migrate":
+			os.Exit(runMigrateCommand(cfg, os.Args[2:]))
+		default:
+			log.Fatalf("Unknown command %q", os.Args[1])
+		}
+	}
+
 	appLogger.Info("Starting application...")
 
 	// Database connection
-	dbConn, err := db.NewDB(
-		"postgres://" + cfg.Database.User + ":" + cfg.Database.Password +
-			"@" + cfg.Database.Host + ":" + strconv.Itoa(cfg.Database.Port) +
-			"/" + cfg.Database.DBName + "?sslmode=" + cfg.Database.SSLMode,
-	)
+	dbConn, err := openDB(cfg)
 	if err != nil {
 		appLogger.Error(err)
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	if cfg.App.DoMigrations {
-		err = dbConn.MigrateDB()
-		if err != nil {
-			appLogger.Error(err)
-			log.Fatalf("Failed to migrate database: %v", err)
-		}
+	// Serving against an outdated or edited schema fails in confusing ways, so
+	// refuse to start
+	migrator, err := db.NewMigrator(dbConn)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	pending, err := migrator.Pending(context.Background())
+	if err != nil {
+		appLogger.Error(err)
+		log.Fatalf("Failed to check migrations: %v", err)
+	}
+	if len(pending) > 0 {
+		log.Fatalf("Database has %d pending migrations, starting with %s; run `main migrate up` first", len(pending), pending[0])
 	}
 
 	defer dbConn.Close()
 
 	appLogger.Info("Connected to the database")
 
+	// Row-level security is the second line of defence between workspaces
+	bypasses, err := dbConn.BypassesRowSecurity(context.Background())
+	if err != nil {
+		appLogger.Error(err)
+		log.Fatalf("Failed to check the database role: %v", err)
+	}
+	if bypasses {
+		appLogger.Error(fmt.Errorf("database user %q is a superuser or has BYPASSRLS, so row-level security does not isolate workspaces", cfg.Database.User))
+	}
+
 	// Initialize repositories
 	sequenceRepo := db.NewSequenceRepository(dbConn)
 	stepRepo := db.NewStepRepository(dbConn)
+	contactRepo := db.NewContactRepository(dbConn)
+	enrollmentRepo := db.NewEnrollmentRepository(dbConn)
+	jobRepo := db.NewJobRepository(dbConn)
+	searchRepo := db.NewSearchRepository(dbConn)
+	idempotencyRepo := db.NewIdempotencyRepository(dbConn)
+	apiKeyRepo := db.NewAPIKeyRepository(dbConn)
+	workspaceRepo := db.NewWorkspaceRepository(dbConn)
+	userRepo := db.NewUserRepository(dbConn)
+	teamRepo := db.NewTeamRepository(dbConn)
+	auditRepo := db.NewAuditRepository(dbConn)
+	sendRepo := db.NewSendRepository(dbConn)
+	holidayRepo := db.NewHolidayRepository(dbConn)
 
 	// Initialize services
-	sequenceService := core.NewSequenceService(sequenceRepo)
-	stepService := core.NewStepService(stepRepo)
+	systemClock := clock.System
+	authorizer := core.NewAuthorizer(teamRepo)
+	sequenceService := core.NewSequenceService(dbConn, sequenceRepo, stepRepo, auditRepo, holidayRepo, authorizer, systemClock)
+	stepService := core.NewStepService(dbConn, stepRepo, sequenceRepo, auditRepo, authorizer)
+	contactService := core.NewContactService(dbConn, contactRepo, enrollmentRepo, sequenceRepo, jobRepo, holidayRepo, authorizer, systemClock)
+	jobService := core.NewJobService(jobRepo)
+	searchService := core.NewSearchService(searchRepo, authorizer)
+	batchService := core.NewBatchService(dbConn, sequenceService, stepService)
+	idempotencyService := core.NewIdempotencyService(idempotencyRepo, cfg.App.IdempotencyTTL)
+	go purgeIdempotencyKeys(idempotencyService, appLogger)
+	apiKeyService := core.NewAPIKeyService(apiKeyRepo)
+	workspaceService := core.NewWorkspaceService(workspaceRepo)
+	teamService := core.NewTeamService(userRepo, teamRepo, authorizer)
+	auditService := core.NewAuditService(auditRepo, authorizer)
+	enrollmentService := core.NewEnrollmentService(enrollmentRepo, sendRepo, sequenceRepo, holidayRepo, authorizer, systemClock)
+	holidayService := core.NewHolidayService(holidayRepo, authorizer)
+
+	// Deliver sequence emails, woken by the database when sends become due.
+	// workerStopped is closed once the worker has returned.
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	defer stopWorker()
+	workerStopped := make(chan struct{})
+	if !cfg.Sends.Enabled {
+		close(workerStopped)
+	} else {
+		listener, err := dbConn.Listen(db.SendsDueChannel)
+		if err != nil {
+			appLogger.Error(err)
+			log.Fatalf("Failed to listen for sends: %v", err)
+		}
+		emailClient := email.NewEmailClient(cfg.Email.SMTPHost, cfg.Email.SMTPPort, cfg.Email.Username, cfg.Email.Password, cfg.Email.SenderEmail)
+		sendWorker := core.NewSendWorker(dbConn, sendRepo, stepRepo, enrollmentRepo, workspaceRepo, holidayRepo, emailClient, systemClock, core.SendWorkerConfig{
+			PollInterval: cfg.Sends.PollInterval,
+			MaxAttempts:  cfg.Sends.MaxAttempts,
+			RetryDelay:   cfg.Sends.RetryDelay,
+		})
+		go func() {
+			defer close(workerStopped)
+			sendWorker.Run(workerCtx, listener.Notifications())
+		}()
+		appLogger.Info("Send worker started")
+	}
 
 	// Initialize handlers
 	sequenceHandler := api.NewSequenceHandler(sequenceService)
 	stepHandler := api.NewStepHandler(stepService)
-	generalHandler := api.NewGeneralHandler(cfg.App.Version)
+	generalHandler := api.NewGeneralHandler(cfg.App.Version, systemClock)
+	contactHandler := api.NewContactHandler(contactService)
+	jobHandler := api.NewJobHandler(jobService)
+	searchHandler := api.NewSearchHandler(searchService)
+	batchHandler := api.NewBatchHandler(batchService)
+	apiKeyHandler := api.NewAPIKeyHandler(apiKeyService)
+	teamHandler := api.NewTeamHandler(teamService)
+	auditHandler := api.NewAuditHandler(auditService)
+	enrollmentHandler := api.NewEnrollmentHandler(enrollmentService)
+	holidayHandler := api.NewHolidayHandler(holidayService)
 	// Create router and routes
 	router := api.NewRouter(&api.Routes{
-		SequenceHandler: sequenceHandler,
-		StepHandler:     stepHandler,
-		GeneralHandler:  generalHandler,
+		SequenceHandler:   sequenceHandler,
+		StepHandler:       stepHandler,
+		GeneralHandler:    generalHandler,
+		ContactHandler:    contactHandler,
+		JobHandler:        jobHandler,
+		SearchHandler:     searchHandler,
+		BatchHandler:      batchHandler,
+		APIKeyHandler:     apiKeyHandler,
+		TeamHandler:       teamHandler,
+		AuditHandler:      auditHandler,
+		EnrollmentHandler: enrollmentHandler,
+		HolidayHandler:    holidayHandler,
+		Auth:              api.NewAuthMiddleware(authenticators(cfg, apiKeyService)...),
+		Tenant:            api.NewTenantMiddleware(workspaceService),
+		Idempotency:       api.NewIdempotencyMiddleware(idempotencyService),
 	})
 
 	// Add Prometheus metrics endpoint if enabled
 	if cfg.Metrics.Enabled {
+		prometheus.MustRegister(collectors.NewDBStatsCollector(dbConn.Conn, cfg.Database.DBName))
 		router.Handle(cfg.Metrics.Path, promhttp.Handler())
 		appLogger.Info("Prometheus metrics enabled at " + cfg.Metrics.Path)
 		go func() {
@@ -93,11 +206,95 @@ func main() {
 		IdleTimeout:  30 * time.Second,
 	}
 
+	// Stop on SIGINT or SIGTERM, letting requests, contact imports and the
+	// send in progress finish first
+	stop, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-stop.Done()
+		appLogger.Info("Shutting down")
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.App.ShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			appLogger.Error(err)
+		}
+		// No request can start an import any more
+		if err := contactService.Shutdown(ctx); err != nil {
+			appLogger.Error(fmt.Errorf("contact imports did not finish in time and were failed: %w", err))
+		}
+		stopWorker()
+		select {
+		case <-workerStopped:
+		case <-ctx.Done():
+			appLogger.Error(fmt.Errorf("send worker did not stop in time: %w", ctx.Err()))
+		}
+	}()
+
 	appLogger.Info("Starting server on port " + strconv.Itoa(cfg.App.Port))
 	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		appLogger.Error(err)
 		log.Fatalf("Server error: %v", err)
 	}
+	<-stopped
 
 	appLogger.Info("Server stopped")
 }
+
+// openDB connects to the database and its replicas with the configured pool
+// settings, waiting for the primary to come up.
+func openDB(cfg *config.Config) (*db.DB, error) {
+	return db.NewDB(databaseURL(cfg), db.Options{
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+		ConnectTimeout:  cfg.Database.ConnectTimeout,
+		QueryTimeout:    cfg.Database.QueryTimeout,
+
+		Replicas:             cfg.Database.Replicas,
+		ReplicaCheckInterval: cfg.Database.ReplicaCheckInterval,
+	})
+}
+
+// databaseURL builds the PostgreSQL connection string from the configuration.
+func databaseURL(cfg *config.Config) string {
+	return "postgres://" + cfg.Database.User + ":" + cfg.Database.Password +
+		"@" + cfg.Database.Host + ":" + strconv.Itoa(cfg.Database.Port) +
+		"/" + cfg.Database.DBName + "?sslmode=" + cfg.Database.SSLMode
+}
+
+// authenticators returns the authenticators for the configured credential kinds.
+func authenticators(cfg *config.Config, apiKeyService core.APIKeyService) []api.Authenticator {
+	authenticators := []api.Authenticator{apiKeyService}
+	jwtCfg := cfg.Auth.JWT
+	if !jwtCfg.Enabled {
+		return authenticators
+	}
+
+	var keys *auth.KeySet
+	if jwtCfg.JWKSFile != "" {
+		keys = auth.NewFileKeySet(jwtCfg.JWKSFile, jwtCfg.RefreshInterval)
+	} else {
+		keys = auth.NewURLKeySet(jwtCfg.JWKSURL, nil, jwtCfg.RefreshInterval)
+	}
+	return append(authenticators, auth.NewJWTAuthenticator(keys, auth.JWTConfig{
+		Issuer:         jwtCfg.Issuer,
+		Audience:       jwtCfg.Audience,
+		Leeway:         jwtCfg.Leeway,
+		UserClaim:      jwtCfg.UserClaim,
+		WorkspaceClaim: jwtCfg.WorkspaceClaim,
+		RolesClaim:     jwtCfg.RolesClaim,
+		RoleScopes:     jwtCfg.RoleScopes,
+	}))
+}
+
+// purgeIdempotencyKeys periodically removes expired idempotency records.
+func purgeIdempotencyKeys(service core.IdempotencyService, appLogger *logger.Logger) {
+	for range time.Tick(time.Hour) {
+		if _, err := service.PurgeExpired(context.Background()); err != nil {
+			appLogger.Error(err)
+		}
+	}
+}
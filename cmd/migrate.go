@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"sf_test/config"
+	"sf_test/internal/db"
+)
+
+const migrateUsage = `Usage: main migrate <up|down|status|redo> [-steps <n>]
+
+Manages the database schema. The server refuses to start while migrations
+are pending, so run "main migrate up" before deploying a new release.
+
+  up      apply every pending migration
+  down    revert the last -steps migrations (default 1)
+  status  list migrations and whether they are applied
+  redo    revert and re-apply the last applied migration, leaving later
+          pending ones pending
+`
+
+// runMigrateCommand implements the "migrate" subcommand and returns the exit code.
+func runMigrateCommand(cfg *config.Config, args []string) int {
+	if len(args) == 0 || (args[0] != "up" && args[0] != "down" && args[0] != "status" && args[0] != "redo") {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	steps := flags.Int("steps", 1, "number of migrations to revert")
+	flags.Usage = func() { fmt.Fprint(os.Stderr, migrateUsage) }
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *steps < 1 {
+		fmt.Fprintln(os.Stderr, "-steps must be at least 1")
+		return 2
+	}
+
+	dbConn, err := openDB(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer dbConn.Close()
+
+	migrator, err := db.NewMigrator(dbConn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load migrations: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read migration status: %v\n", err)
+			return 1
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			switch {
+			case status.Modified:
+				state += " (modified since applied)"
+			case status.Unknown:
+				state += " (unknown to this release)"
+			}
+			fmt.Printf("%s\t%s\n", status.Migration, state)
+		}
+		return 0
+	case "up":
+		applied, err := migrator.Up(ctx)
+		printMigrations("Applied", applied)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to migrate: %v\n", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database is up to date.")
+		}
+		return 0
+	case "down":
+		reverted, err := migrator.Down(ctx, *steps)
+		printMigrations("Reverted", reverted)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to revert: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	reverted, err := migrator.Down(ctx, 1)
+	printMigrations("Reverted", reverted)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to revert: %v\n", err)
+		return 1
+	}
+	if len(reverted) == 0 {
+		fmt.Println("No migration is applied.")
+		return 0
+	}
+	// Re-apply only the reverted migration, not any pending after it
+	applied, err := migrator.UpTo(ctx, reverted[0].Version)
+	printMigrations("Applied", applied)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to migrate: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func printMigrations(verb string, migrations []db.Migration) {
+	for _, migration := range migrations {
+		fmt.Printf("%s %s.\n", verb, migration)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"sf_test/config"
+	"sf_test/internal/core"
+	"sf_test/internal/db"
+)
+
+const sequencesUsage = `Usage: main sequences <plan|apply> [-prune] [-workspace <slug>] <file or directory>...
+
+Reads sequence definitions from YAML or JSON files and reconciles them with the
+database. Sequences are matched by their "key", steps by their subject.
+
+  plan    print the changes that apply would make
+  apply   make the changes in a single transaction
+`
+
+// runSequencesCommand implements the "sequences" subcommand and returns the exit code.
+func runSequencesCommand(cfg *config.Config, args []string) int {
+	if len(args) == 0 || (args[0] != "plan" && args[0] != "apply") {
+		fmt.Fprint(os.Stderr, sequencesUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("sequences "+args[0], flag.ContinueOnError)
+	prune := flags.Bool("prune", false, "delete stored sequences with a key that is not defined")
+	workspace := flags.String("workspace", "default", "slug of the workspace holding the sequences")
+	flags.Usage = func() { fmt.Fprint(os.Stderr, sequencesUsage) }
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprint(os.Stderr, sequencesUsage)
+		return 2
+	}
+
+	definitions, err := core.LoadSequenceDefinitions(flags.Args()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load definitions: %v\n", err)
+		return 1
+	}
+
+	dbConn, err := openDB(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer dbConn.Close()
+
+	planner := core.NewSequencePlanner(dbConn, db.NewSequenceRepository(dbConn), db.NewStepRepository(dbConn), db.NewAuditRepository(dbConn))
+
+	ctx, err := workspaceContext(context.Background(), dbConn, *workspace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to %s sequences: %v\n", args[0], err)
+		return 1
+	}
+	var plan *core.Plan
+	if args[0] == "apply" {
+		plan, err = planner.Apply(ctx, definitions, *prune)
+	} else {
+		plan, err = planner.Plan(ctx, definitions, *prune)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to %s sequences: %v\n", args[0], err)
+		return 1
+	}
+
+	plan.Print(os.Stdout)
+	if args[0] == "apply" && !plan.Empty() {
+		fmt.Println("Apply complete.")
+	}
+	return 0
+}
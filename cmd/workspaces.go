@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"sf_test/config"
+	"sf_test/internal/core"
+	"sf_test/internal/db"
+	"sf_test/internal/tenant"
+)
+
+const workspacesUsage = `Usage: main workspaces <create|list> [-slug <slug> -name <name>]
+
+Manages workspaces. Each workspace's sequences, contacts and jobs are only
+visible to API keys and tokens of that workspace.
+
+  create  create a workspace
+  list    list all workspaces
+`
+
+// runWorkspacesCommand implements the "workspaces" subcommand and returns the exit code.
+func runWorkspacesCommand(cfg *config.Config, args []string) int {
+	if len(args) == 0 || (args[0] != "create" && args[0] != "list") {
+		fmt.Fprint(os.Stderr, workspacesUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("workspaces "+args[0], flag.ContinueOnError)
+	slug := flags.String("slug", "", "identifier of the workspace, used in token claims")
+	name := flags.String("name", "", "display name of the workspace")
+	flags.Usage = func() { fmt.Fprint(os.Stderr, workspacesUsage) }
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	dbConn, err := openDB(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer dbConn.Close()
+
+	service := core.NewWorkspaceService(db.NewWorkspaceRepository(dbConn))
+	ctx := context.Background()
+	if args[0] == "list" {
+		workspaces, err := service.ListWorkspaces(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to list workspaces: %v\n", err)
+			return 1
+		}
+		for _, workspace := range workspaces {
+			fmt.Printf("%d\t%s\t%s\n", workspace.ID, workspace.Slug, workspace.Name)
+		}
+		return 0
+	}
+
+	workspace, err := service.CreateWorkspace(ctx, *slug, *name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create workspace: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Created workspace %d (%s).\n", workspace.ID, workspace.Slug)
+	return 0
+}
+
+// workspaceContext returns ctx bound to the workspace with the given slug.
+func workspaceContext(ctx context.Context, dbConn *db.DB, slug string) (context.Context, error) {
+	workspace, err := db.NewWorkspaceRepository(dbConn).GetBySlug(ctx, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("unknown workspace %q", slug)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tenant.WithWorkspace(ctx, workspace.ID), nil
+}
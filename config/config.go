@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -13,13 +14,18 @@ type Config struct {
 	Database DatabaseConfig `mapstructure:"database"`
 	Email    EmailConfig    `mapstructure:"email"`
 	Metrics  MetricsConfig  `mapstructure:"metrics"`
+	Auth     AuthConfig     `mapstructure:"auth"`
+	Sends    SendsConfig    `mapstructure:"sends"`
 }
 
 // AppConfig holds general app-related configurations.
 type AppConfig struct {
-	Port         int    `mapstructure:"port"`
-	Version      string `mapstructure:"version"`
-	DoMigrations bool   `mapstructure:"do_migrations"`
+	Port           int           `mapstructure:"port"`
+	Version        string        `mapstructure:"version"`
+	IdempotencyTTL time.Duration `mapstructure:"idempotency_ttl"`
+	// ShutdownTimeout is how long a stopping server waits for requests and
+	// contact imports in progress before failing the imports.
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
 }
 
 // DatabaseConfig holds database connection details.
@@ -30,6 +36,19 @@ type DatabaseConfig struct {
 	Password string `mapstructure:"password"`
 	DBName   string `mapstructure:"dbname"`
 	SSLMode  string `mapstructure:"sslmode"`
+
+	MaxOpenConns    int           `mapstructure:"max_open_conns"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
+	// ConnectTimeout is how long startup waits for the database to come up.
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+	// QueryTimeout bounds each query that has no deadline of its own.
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+	// Replicas are connection strings of read replicas that serve read-only
+	// queries while they pass health checks, made every ReplicaCheckInterval.
+	Replicas             []string      `mapstructure:"replicas"`
+	ReplicaCheckInterval time.Duration `mapstructure:"replica_check_interval"`
 }
 
 // EmailConfig holds email client configurations.
@@ -48,15 +67,58 @@ type MetricsConfig struct {
 	Port    int    `mapstructure:"port"`
 }
 
+// SendsConfig holds the settings of the worker delivering sequence emails.
+type SendsConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	MaxAttempts  int           `mapstructure:"max_attempts"`
+	RetryDelay   time.Duration `mapstructure:"retry_delay"`
+}
+
+// AuthConfig holds authentication configurations.
+type AuthConfig struct {
+	JWT JWTConfig `mapstructure:"jwt"`
+}
+
+// JWTConfig holds the settings for accepting JWTs issued by an OIDC provider.
+// The key set is read from JWKSFile, or fetched from JWKSURL when no file is set.
+type JWTConfig struct {
+	Enabled         bool                `mapstructure:"enabled"`
+	JWKSFile        string              `mapstructure:"jwks_file"`
+	JWKSURL         string              `mapstructure:"jwks_url"`
+	RefreshInterval time.Duration       `mapstructure:"refresh_interval"`
+	Issuer          string              `mapstructure:"issuer"`
+	Audience        string              `mapstructure:"audience"`
+	Leeway          time.Duration       `mapstructure:"leeway"`
+	UserClaim       string              `mapstructure:"user_claim"`
+	WorkspaceClaim  string              `mapstructure:"workspace_claim"`
+	RolesClaim      string              `mapstructure:"roles_claim"`
+	RoleScopes      map[string][]string `mapstructure:"role_scopes"`
+}
+
 // LoadConfig initializes the application configuration from file and environment variables.
 func LoadConfig(configPath string) (*Config, error) {
 	v := viper.New()
 
 	// Set default configurations
 	v.SetDefault("app.port", 8080)
+	v.SetDefault("app.idempotency_ttl", "24h")
+	v.SetDefault("app.shutdown_timeout", "30s")
+	v.SetDefault("database.max_open_conns", 25)
+	v.SetDefault("database.max_idle_conns", 25)
+	v.SetDefault("database.conn_max_lifetime", "30m")
+	v.SetDefault("database.conn_max_idle_time", "5m")
+	v.SetDefault("database.connect_timeout", "30s")
+	v.SetDefault("database.query_timeout", "5s")
+	v.SetDefault("database.replica_check_interval", "10s")
+	v.SetDefault("auth.jwt.refresh_interval", "1h")
+	v.SetDefault("auth.jwt.leeway", "1m")
 	v.SetDefault("metrics.enabled", true)
 	v.SetDefault("metrics.path", "/metrics")
 	v.SetDefault("metrics.port", 9090)
+	v.SetDefault("sends.poll_interval", "5m")
+	v.SetDefault("sends.max_attempts", 5)
+	v.SetDefault("sends.retry_delay", "1m")
 
 	// Automatically read environment variables (app-specific prefix)
 	v.SetEnvPrefix("APP")
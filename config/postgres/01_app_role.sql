@@ -0,0 +1,7 @@
+-- Run by the database container when it initializes an empty data directory.
+-- The API connects as sf_app, which owns the tables its migrations create.
+-- Being neither a superuser nor BYPASSRLS, it is still held to the
+-- row-level security policies the tables force on their owner.
+CREATE ROLE sf_app LOGIN PASSWORD 'secret' NOSUPERUSER NOBYPASSRLS NOCREATEDB NOCREATEROLE;
+GRANT CONNECT, TEMPORARY ON DATABASE sf_test TO sf_app;
+GRANT USAGE, CREATE ON SCHEMA public TO sf_app;
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"sf_test/internal/core"
+
+	"github.com/gorilla/mux"
+)
+
+type APIKeyHandler struct {
+	apiKeyService core.APIKeyService
+}
+
+func NewAPIKeyHandler(service core.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: service}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
+		return
+	}
+
+	key, err := h.apiKeyService.CreateAPIKey(r.Context(), payload.Name, payload.Scopes)
+	if err != nil {
+		WriteError(w, r, err, "Failed to create API key")
+		return
+	}
+
+	// The key is only ever returned here, so it must not be cached anywhere.
+	w.Header().Set("Cache-Control", "no-store")
+	WriteResponse(w, http.StatusCreated, SuccessResponse(key, "API key created successfully; store the key now, it will not be shown again"))
+}
+
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyService.ListAPIKeys(r.Context())
+	if err != nil {
+		WriteError(w, r, err, "Failed to fetch API keys")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(keys, "API keys fetched successfully"))
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid ID")
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(r.Context(), id); err != nil {
+		WriteError(w, r, err, "Failed to revoke API key")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(nil, "API key revoked successfully"))
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sf_test/internal/core"
+	"sf_test/internal/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAPIKeyRouter(handler *APIKeyHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/api-keys", handler.CreateAPIKey).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/api-keys", handler.ListAPIKeys).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/api-keys/{id}", handler.RevokeAPIKey).Methods(http.MethodDelete)
+	return router
+}
+
+func TestCreateAPIKey_Success(t *testing.T) {
+	mockService := &APIKeyServiceMock{
+		CreateAPIKeyFunc: func(ctx context.Context, name string, scopes []string) (*models.CreatedAPIKey, error) {
+			return &models.CreatedAPIKey{
+				APIKey: models.APIKey{ID: 1, Name: name, Prefix: "sfk_0123abcd", Scopes: scopes},
+				Key:    "sfk_secret",
+			}, nil
+		},
+	}
+	router := setupAPIKeyRouter(NewAPIKeyHandler(mockService))
+
+	body := []byte(`{"name":"ci","scopes":["sequences:read"]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/api-keys", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, []string{"sequences:read"}, mockService.CreateAPIKeyCalls()[0].Scopes)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "sfk_secret", data["key"])
+	assert.NotContains(t, data, "hash")
+}
+
+func TestCreateAPIKey_ValidationError(t *testing.T) {
+	mockService := &APIKeyServiceMock{
+		CreateAPIKeyFunc: func(ctx context.Context, name string, scopes []string) (*models.CreatedAPIKey, error) {
+			return nil, &core.ValidationError{Message: `unknown scope "everything"`}
+		},
+	}
+	router := setupAPIKeyRouter(NewAPIKeyHandler(mockService))
+
+	body := []byte(`{"name":"ci","scopes":["everything"]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/api-keys", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestListAPIKeys_Success(t *testing.T) {
+	mockService := &APIKeyServiceMock{
+		ListAPIKeysFunc: func(ctx context.Context) ([]*models.APIKey, error) {
+			return []*models.APIKey{{ID: 1, Name: "ci", Scopes: []string{"sequences:read"}}}, nil
+		},
+	}
+	router := setupAPIKeyRouter(NewAPIKeyHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/api-keys", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	assert.Len(t, response["data"].([]interface{}), 1)
+}
+
+func TestRevokeAPIKey_NotFound(t *testing.T) {
+	mockService := &APIKeyServiceMock{
+		RevokeAPIKeyFunc: func(ctx context.Context, id int64) error {
+			return &core.NotFoundError{Resource: "api key", ID: id}
+		},
+	}
+	router := setupAPIKeyRouter(NewAPIKeyHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/api-keys/7", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, int64(7), mockService.RevokeAPIKeyCalls()[0].ID)
+}
+
+func TestRevokeAPIKey_InvalidID(t *testing.T) {
+	router := setupAPIKeyRouter(NewAPIKeyHandler(&APIKeyServiceMock{}))
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/api-keys/abc", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
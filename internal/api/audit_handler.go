@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"sf_test/internal/core"
+)
+
+type AuditHandler struct {
+	auditService core.AuditService
+}
+
+func NewAuditHandler(service core.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: service}
+}
+
+// ListAudit returns a page of audit entries. Besides the shared list
+// parameters it filters on actor, action, resourceType, resourceId and
+// requestId.
+func (h *AuditHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		WriteBadRequest(w, r, "Invalid query parameter", err.Error())
+		return
+	}
+	query := r.URL.Query()
+	opts.Filters.Actor = query.Get("actor")
+	opts.Filters.Action = query.Get("action")
+	opts.Filters.ResourceType = query.Get("resourceType")
+	opts.Filters.RequestID = query.Get("requestId")
+	if idStr := query.Get("resourceId"); idStr != "" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id <= 0 {
+			WriteBadRequest(w, r, "Invalid query parameter", "resourceId must be a positive integer")
+			return
+		}
+		opts.Filters.ResourceID = id
+	}
+
+	entries, next, err := h.auditService.ListAudit(r.Context(), opts)
+	if err != nil {
+		WriteError(w, r, err, "Failed to fetch audit log")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, PageResponse(entries, opts, next, "Audit log fetched successfully"))
+}
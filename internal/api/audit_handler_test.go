@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sf_test/internal/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAuditRouter(handler *AuditHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/audit", handler.ListAudit).Methods(http.MethodGet)
+	return router
+}
+
+func TestListAudit_Filters(t *testing.T) {
+	mockService := &AuditServiceMock{
+		ListAuditFunc: func(ctx context.Context, opts models.ListOptions) ([]*models.AuditEntry, string, error) {
+			return []*models.AuditEntry{{
+				ID: 1, Actor: "user:42", Action: models.AuditActionUpdate, ResourceType: models.AuditResourceStep, ResourceID: 9,
+				Changes: map[string]models.AuditChange{"content": {Before: json.RawMessage(`"a"`), After: json.RawMessage(`"b"`)}},
+			}}, "next", nil
+		},
+	}
+	router := setupAuditRouter(NewAuditHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/audit?resourceType=step&resourceId=9&actor=user:42&action=update&requestId=abc&limit=10", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	filters := mockService.ListAuditCalls()[0].Opts.Filters
+	assert.Equal(t, "step", filters.ResourceType)
+	assert.Equal(t, int64(9), filters.ResourceID)
+	assert.Equal(t, "user:42", filters.Actor)
+	assert.Equal(t, "update", filters.Action)
+	assert.Equal(t, "abc", filters.RequestID)
+
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	entry := response["data"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"before": "a", "after": "b"}, entry["changes"].(map[string]interface{})["content"])
+	assert.Equal(t, "next", response["meta"].(map[string]interface{})["nextCursor"])
+}
+
+func TestListAudit_InvalidResourceID(t *testing.T) {
+	mockService := &AuditServiceMock{}
+	router := setupAuditRouter(NewAuditHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/audit?resourceId=abc", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, mockService.ListAuditCalls())
+}
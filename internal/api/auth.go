@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"sf_test/internal/auth"
+	"sf_test/internal/core"
+)
+
+// Authenticator resolves a credential to a principal. Authenticators return
+// auth.ErrUnsupportedCredential for credentials of a kind they do not handle.
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*auth.Principal, error)
+}
+
+// AuthMiddleware resolves the credentials of a request to a principal and
+// places it in the request context. Requests without credentials continue
+// anonymously and are turned away by RequireScope on protected routes.
+type AuthMiddleware struct {
+	authenticators []Authenticator
+}
+
+// NewAuthMiddleware returns a middleware offering each credential to the
+// authenticators in order until one accepts its kind.
+func NewAuthMiddleware(authenticators ...Authenticator) *AuthMiddleware {
+	return &AuthMiddleware{authenticators: authenticators}
+}
+
+// Authenticate is the middleware handler.
+func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		credential := requestCredential(r)
+		if credential == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := m.authenticate(r.Context(), credential)
+		if err != nil {
+			WriteError(w, r, err, "Failed to authenticate")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+func (m *AuthMiddleware) authenticate(ctx context.Context, credential string) (*auth.Principal, error) {
+	for _, authenticator := range m.authenticators {
+		principal, err := authenticator.Authenticate(ctx, credential)
+		if errors.Is(err, auth.ErrUnsupportedCredential) {
+			continue
+		}
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return nil, &core.UnauthenticatedError{Message: err.Error()}
+		}
+		return principal, err
+	}
+	return nil, &core.UnauthenticatedError{Message: "unsupported credential"}
+}
+
+// requestCredential returns the bearer token or X-API-Key header of r.
+func requestCredential(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	header := r.Header.Get("Authorization")
+	if len(header) > len("Bearer ") && strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return strings.TrimSpace(header[len("Bearer "):])
+	}
+	return ""
+}
+
+// RequireScope only lets requests through whose principal was granted scope.
+func RequireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.PrincipalFrom(r.Context())
+		if !ok {
+			WriteError(w, r, &core.UnauthenticatedError{Message: "authentication is required"}, "Failed to authenticate")
+			return
+		}
+		if !principal.HasScope(scope) {
+			WriteError(w, r, &core.ForbiddenError{Message: "missing required scope " + scope}, "Failed to authorize")
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sf_test/internal/auth"
+	"sf_test/internal/core"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func authTestHandler(authenticators ...Authenticator) http.Handler {
+	protected := RequireScope(auth.ScopeSequencesRead, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return NewAuthMiddleware(authenticators...).Authenticate(protected)
+}
+
+const (
+	testValidKey   = "sfk_0123456789abcdef0123456789abcdef0123456789abcdef"
+	testRevokedKey = "sfk_fedcba9876543210fedcba9876543210fedcba9876543210"
+)
+
+// tokenAuthenticator accepts credentials that look like JWTs.
+type tokenAuthenticator struct{}
+
+func (tokenAuthenticator) Authenticate(ctx context.Context, credential string) (*auth.Principal, error) {
+	switch credential {
+	case "header.valid.signature":
+		return &auth.Principal{Subject: "user:42", UserID: "42", Scopes: []string{auth.ScopeSequencesRead}}, nil
+	case "header.expired.signature":
+		return nil, fmt.Errorf("%w: token has expired", auth.ErrInvalidToken)
+	}
+	return nil, auth.ErrUnsupportedCredential
+}
+
+func validKeyService(scopes ...string) *APIKeyServiceMock {
+	return &APIKeyServiceMock{
+		AuthenticateFunc: func(ctx context.Context, secret string) (*auth.Principal, error) {
+			if !auth.IsAPIKey(secret) {
+				return nil, auth.ErrUnsupportedCredential
+			}
+			if secret != testValidKey {
+				return nil, &core.UnauthenticatedError{Message: "invalid API key"}
+			}
+			return &auth.Principal{Subject: "api_key:1", Scopes: scopes}, nil
+		},
+	}
+}
+
+func TestAuth_MissingCredentials(t *testing.T) {
+	mockService := validKeyService(auth.ScopeSequencesRead)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sequences", nil)
+	rec := httptest.NewRecorder()
+
+	authTestHandler(mockService).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "Bearer")
+	assert.Empty(t, mockService.AuthenticateCalls())
+}
+
+func TestAuth_APIKeyHeader(t *testing.T) {
+	mockService := validKeyService(auth.ScopeSequencesRead)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sequences", nil)
+	req.Header.Set("X-API-Key", testValidKey)
+	rec := httptest.NewRecorder()
+
+	authTestHandler(mockService).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestAuth_BearerToken(t *testing.T) {
+	mockService := validKeyService(auth.ScopeSequencesRead)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sequences", nil)
+	req.Header.Set("Authorization", "bearer "+testValidKey)
+	rec := httptest.NewRecorder()
+
+	authTestHandler(mockService).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, testValidKey, mockService.AuthenticateCalls()[0].Secret)
+}
+
+func TestAuth_InvalidKey(t *testing.T) {
+	mockService := validKeyService(auth.ScopeSequencesRead)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sequences", nil)
+	req.Header.Set("X-API-Key", testRevokedKey)
+	rec := httptest.NewRecorder()
+
+	authTestHandler(mockService).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAuth_MissingScope(t *testing.T) {
+	mockService := validKeyService(auth.ScopeJobsRead)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sequences", nil)
+	req.Header.Set("X-API-Key", testValidKey)
+	rec := httptest.NewRecorder()
+
+	authTestHandler(mockService).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestAuth_ChainedAuthenticators(t *testing.T) {
+	handler := authTestHandler(validKeyService(auth.ScopeSequencesRead), tokenAuthenticator{})
+
+	tests := []struct {
+		credential string
+		statusCode int
+	}{
+		{testValidKey, http.StatusNoContent},
+		{"header.valid.signature", http.StatusNoContent},
+		{"header.expired.signature", http.StatusUnauthorized},
+		{"not-a-credential", http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/sequences", nil)
+		req.Header.Set("Authorization", "Bearer "+test.credential)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, test.statusCode, rec.Code, "Expected status code %d for %s", test.statusCode, test.credential)
+	}
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"sf_test/internal/core"
+	"sf_test/internal/models"
+)
+
+type BatchHandler struct {
+	batchService core.BatchService
+}
+
+func NewBatchHandler(service core.BatchService) *BatchHandler {
+	return &BatchHandler{batchService: service}
+}
+
+func (h *BatchHandler) ExecuteBatch(w http.ResponseWriter, r *http.Request) {
+	var req models.BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
+		return
+	}
+
+	results, err := h.batchService.Execute(r.Context(), &req)
+	if err != nil {
+		WriteError(w, r, err, "Failed to execute batch")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(results, "Batch executed successfully"))
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sf_test/internal/core"
+	"sf_test/internal/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupBatchRouter(handler *BatchHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/batch", handler.ExecuteBatch).Methods(http.MethodPost)
+	return router
+}
+
+func TestExecuteBatch_Success(t *testing.T) {
+	mockService := &BatchServiceMock{
+		ExecuteFunc: func(ctx context.Context, req *models.BatchRequest) ([]models.BatchResult, error) {
+			return []models.BatchResult{
+				{Index: 0, Op: "create", Resource: "step", ID: 10},
+				{Index: 1, Op: "delete", Resource: "step", ID: 4},
+			}, nil
+		},
+	}
+	handler := NewBatchHandler(mockService)
+	router := setupBatchRouter(handler)
+
+	body := []byte(`{"operations":[
+		{"op":"create","resource":"step","body":{"sequenceId":1,"subject":"Welcome","content":"Hi"}},
+		{"op":"delete","resource":"step","id":4,"version":2}
+	]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/batch", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	operations := mockService.ExecuteCalls()[0].Req.Operations
+	assert.Len(t, operations, 2)
+	assert.Equal(t, int64(2), operations[1].Version)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	assert.Equal(t, "Batch executed successfully", response["message"])
+	assert.Len(t, response["data"].([]interface{}), 2)
+}
+
+func TestExecuteBatch_InvalidBody(t *testing.T) {
+	mockService := &BatchServiceMock{}
+	handler := NewBatchHandler(mockService)
+	router := setupBatchRouter(handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/batch", bytes.NewReader([]byte("invalid json")))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Len(t, mockService.ExecuteCalls(), 0)
+}
+
+func TestExecuteBatch_FailedOperation(t *testing.T) {
+	mockService := &BatchServiceMock{
+		ExecuteFunc: func(ctx context.Context, req *models.BatchRequest) ([]models.BatchResult, error) {
+			return nil, &core.BatchError{Index: 2, Err: &core.PreconditionFailedError{Message: "step 4 has been modified since version was read"}}
+		},
+	}
+	handler := NewBatchHandler(mockService)
+	router := setupBatchRouter(handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/batch", bytes.NewReader([]byte(`{"operations":[]}`)))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	assert.Equal(t, float64(2), response["operation"])
+}
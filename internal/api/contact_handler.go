@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"sf_test/internal/core"
+)
+
+// maxContactImportSize caps the size of an uploaded contact CSV.
+const maxContactImportSize = 50 << 20
+
+type ContactHandler struct {
+	contactService core.ContactService
+}
+
+func NewContactHandler(service core.ContactService) *ContactHandler {
+	return &ContactHandler{contactService: service}
+}
+
+// ImportContacts accepts a multipart upload with a "file" CSV, a "mapping"
+// JSON object of column header to contact field and an optional "sequenceId".
+func (h *ContactHandler) ImportContacts(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxContactImportSize)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		WriteBadRequest(w, r, "Invalid request body", "file is required")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
+		return
+	}
+
+	req := &core.ContactImportRequest{CSV: data}
+	if err := json.Unmarshal([]byte(r.FormValue("mapping")), &req.Mapping); err != nil {
+		WriteBadRequest(w, r, "Invalid request body", "mapping must be a JSON object of column to field")
+		return
+	}
+	if sequenceIDStr := r.FormValue("sequenceId"); sequenceIDStr != "" {
+		req.SequenceID, err = strconv.ParseInt(sequenceIDStr, 10, 64)
+		if err != nil || req.SequenceID <= 0 {
+			WriteBadRequest(w, r, "Invalid request body", "Invalid sequenceId")
+			return
+		}
+	}
+
+	job, err := h.contactService.ImportContacts(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err, "Failed to start contact import")
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/jobs/%d", job.ID))
+	WriteResponse(w, http.StatusAccepted, SuccessResponse(job, "Contact import started"))
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sf_test/internal/core"
+	"sf_test/internal/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupContactRouter(handler *ContactHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/contacts/import", handler.ImportContacts).Methods(http.MethodPost)
+	return router
+}
+
+func newContactImportRequest(t *testing.T, fields map[string]string, csvData string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, value := range fields {
+		_ = writer.WriteField(name, value)
+	}
+	if csvData != "" {
+		part, err := writer.CreateFormFile("file", "contacts.csv")
+		if err != nil {
+			t.Fatalf("Failed to create form file: %v", err)
+		}
+		_, _ = part.Write([]byte(csvData))
+	}
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/contacts/import", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestImportContacts_Success(t *testing.T) {
+	mockService := &ContactServiceMock{
+		ImportContactsFunc: func(ctx context.Context, req *core.ContactImportRequest) (*models.Job, error) {
+			return &models.Job{ID: 5, Status: models.JobStatusPending, TotalItems: 1}, nil
+		},
+	}
+	handler := NewContactHandler(mockService)
+	router := setupContactRouter(handler)
+
+	req := newContactImportRequest(t, map[string]string{
+		"mapping":    `{"E-mail": "email", "Name": "firstName"}`,
+		"sequenceId": "3",
+	}, "E-mail,Name\njane@example.com,Jane\n")
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "/api/v1/jobs/5", rec.Header().Get("Location"))
+	calls := mockService.ImportContactsCalls()
+	assert.Len(t, calls, 1)
+	assert.Equal(t, int64(3), calls[0].Req.SequenceID)
+	assert.Equal(t, "email", calls[0].Req.Mapping["E-mail"])
+	assert.Contains(t, string(calls[0].Req.CSV), "jane@example.com")
+}
+
+func TestImportContacts_MissingFile(t *testing.T) {
+	mockService := &ContactServiceMock{}
+	handler := NewContactHandler(mockService)
+	router := setupContactRouter(handler)
+
+	req := newContactImportRequest(t, map[string]string{"mapping": `{"email": "email"}`}, "")
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Len(t, mockService.ImportContactsCalls(), 0)
+}
+
+func TestImportContacts_InvalidMapping(t *testing.T) {
+	mockService := &ContactServiceMock{}
+	handler := NewContactHandler(mockService)
+	router := setupContactRouter(handler)
+
+	req := newContactImportRequest(t, map[string]string{"mapping": "email"}, "email\njane@example.com\n")
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	assert.Equal(t, "Invalid request body", response["title"])
+}
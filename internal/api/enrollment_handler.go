@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	// Timezone names are resolved without relying on the host's zoneinfo.
+	_ "time/tzdata"
+
+	"sf_test/internal/core"
+
+	"github.com/gorilla/mux"
+)
+
+type EnrollmentHandler struct {
+	enrollmentService core.EnrollmentService
+}
+
+func NewEnrollmentHandler(service core.EnrollmentService) *EnrollmentHandler {
+	return &EnrollmentHandler{enrollmentService: service}
+}
+
+// GetTimeline returns when each step of an enrollment was or will be sent,
+// in the IANA timezone given by the timezone parameter, UTC by default.
+func (h *EnrollmentHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id <= 0 {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid ID")
+		return
+	}
+	loc, err := parseTimezone(r)
+	if err != nil {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid timezone")
+		return
+	}
+
+	timeline, err := h.enrollmentService.GetTimeline(r.Context(), id, loc)
+	if err != nil {
+		WriteError(w, r, err, "Failed to fetch enrollment timeline")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(timeline, "Enrollment timeline fetched successfully"))
+}
+
+// PreviewTimeline returns when each step of a sequence would be sent to a
+// contact enrolled now, in the timezone given like for GetTimeline. The
+// country parameter selects the contact's holidays.
+func (h *EnrollmentHandler) PreviewTimeline(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id <= 0 {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid ID")
+		return
+	}
+	loc, err := parseTimezone(r)
+	if err != nil {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid timezone")
+		return
+	}
+
+	timeline, err := h.enrollmentService.PreviewTimeline(r.Context(), id, r.URL.Query().Get("country"), loc)
+	if err != nil {
+		WriteError(w, r, err, "Failed to preview sequence timeline")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(timeline, "Sequence timeline previewed successfully"))
+}
+
+func parseTimezone(r *http.Request) (*time.Location, error) {
+	name := r.URL.Query().Get("timezone")
+	if name == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(name)
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"sf_test/internal/core"
+	"sf_test/internal/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupEnrollmentRouter(handler *EnrollmentHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/enrollments/{id}/timeline", handler.GetTimeline).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/sequences/{id}/timeline", handler.PreviewTimeline).Methods(http.MethodGet)
+	return router
+}
+
+func TestGetTimeline_Success(t *testing.T) {
+	plannedAt := time.Date(2024, 3, 6, 9, 0, 0, 0, time.UTC)
+	mockService := &EnrollmentServiceMock{
+		GetTimelineFunc: func(ctx context.Context, id int64, loc *time.Location) (*models.Timeline, error) {
+			planned := plannedAt.In(loc)
+			return &models.Timeline{EnrollmentID: id, Timezone: loc.String(), Steps: []models.TimelineStep{
+				{StepID: 1, Status: models.TimelineStatusPlanned, PlannedAt: &planned},
+			}}, nil
+		},
+	}
+	router := setupEnrollmentRouter(NewEnrollmentHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/enrollments/3/timeline?timezone=America/New_York", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "America/New_York", data["timezone"])
+	step := data["steps"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "2024-03-06T04:00:00-05:00", step["plannedAt"])
+}
+
+func TestGetTimeline_Errors(t *testing.T) {
+	mockService := &EnrollmentServiceMock{
+		GetTimelineFunc: func(ctx context.Context, id int64, loc *time.Location) (*models.Timeline, error) {
+			return nil, &core.NotFoundError{Resource: "enrollment", ID: id}
+		},
+	}
+	router := setupEnrollmentRouter(NewEnrollmentHandler(mockService))
+
+	for path, code := range map[string]int{
+		"/api/v1/enrollments/abc/timeline":                   http.StatusBadRequest,
+		"/api/v1/enrollments/3/timeline?timezone=Nowhere/At": http.StatusBadRequest,
+		"/api/v1/enrollments/3/timeline":                     http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, code, rec.Code, path)
+	}
+	assert.Len(t, mockService.GetTimelineCalls(), 1)
+	assert.Equal(t, time.UTC, mockService.GetTimelineCalls()[0].Loc)
+}
+
+func TestPreviewTimeline_Success(t *testing.T) {
+	mockService := &EnrollmentServiceMock{
+		PreviewTimelineFunc: func(ctx context.Context, sequenceID int64, country string, loc *time.Location) (*models.Timeline, error) {
+			return &models.Timeline{SequenceID: sequenceID, Country: country, Timezone: loc.String()}, nil
+		},
+	}
+	router := setupEnrollmentRouter(NewEnrollmentHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sequences/7/timeline?timezone=Europe/Berlin&country=DE", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int64(7), mockService.PreviewTimelineCalls()[0].SequenceID)
+	assert.Equal(t, "Europe/Berlin", mockService.PreviewTimelineCalls()[0].Loc.String())
+	assert.Equal(t, "DE", mockService.PreviewTimelineCalls()[0].Country)
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"sf_test/internal/core"
+	"sf_test/internal/models"
+)
+
+// versionETag is the strong entity tag of a resource at the given version.
+func versionETag(version int64) string {
+	return fmt.Sprintf("%q", strconv.FormatInt(version, 10))
+}
+
+// stepsETag is a weak entity tag for a page of steps. It changes whenever a
+// step on the page is added, removed or modified, or the page boundary moves.
+func stepsETag(steps []*models.Step, next string) string {
+	h := sha256.New()
+	for _, step := range steps {
+		fmt.Fprintf(h, "%d:%d,", step.ID, step.Version)
+	}
+	h.Write([]byte(next))
+	return `W/"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`
+}
+
+// ifMatchVersion returns the version named by the If-Match header, or 0 when
+// the request is unconditional. An If-Match that cannot name a version of the
+// resource can never match, so it fails the precondition.
+func ifMatchVersion(r *http.Request) (int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	// If-Match uses strong comparison, so weak tags never match.
+	version, err := strconv.ParseInt(strings.Trim(header, `"`), 10, 64)
+	if strings.HasPrefix(header, "W/") || !strings.HasPrefix(header, `"`) || err != nil || version <= 0 {
+		return 0, &core.PreconditionFailedError{Message: "If-Match does not match the current version"}
+	}
+	return version, nil
+}
+
+// notModified sets the ETag header and, when the request's If-None-Match
+// matches it, writes 304 Not Modified and reports true.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		// If-None-Match uses weak comparison.
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
@@ -5,10 +5,12 @@ import (
 	"net/http"
 	"os"
 	"runtime"
+	"sf_test/internal/clock"
 	"time"
 )
 
 type GeneralHandler struct {
+	clock     clock.Clock
 	startTime time.Time
 	version   string
 }
@@ -27,9 +29,10 @@ type InfoResponse struct {
 	NumGoroutine int    `json:"numGoroutine"`
 }
 
-func NewGeneralHandler(version string) *GeneralHandler {
+func NewGeneralHandler(version string, clk clock.Clock) *GeneralHandler {
 	return &GeneralHandler{
-		startTime: time.Now(),
+		clock:     clk,
+		startTime: clk.Now(),
 		version:   version,
 	}
 }
@@ -38,7 +41,7 @@ func NewGeneralHandler(version string) *GeneralHandler {
 func (h *GeneralHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	health := HealthResponse{
 		Status:    "ok",
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Timestamp: h.clock.Now().UTC().Format(time.RFC3339),
 	}
 	WriteResponse(w, http.StatusOK, SuccessResponse(health, "Health check successful"))
 }
@@ -51,7 +54,7 @@ func (h *GeneralHandler) GetAPIInfo(w http.ResponseWriter, r *http.Request) {
 	info := InfoResponse{
 		Version:      h.version,
 		GoVersion:    runtime.Version(),
-		Uptime:       time.Since(h.startTime).String(),
+		Uptime:       h.clock.Now().Sub(h.startTime).String(),
 		Environment:  getEnvironment(),
 		TotalMemory:  mem.TotalAlloc,
 		NumGoroutine: runtime.NumGoroutine(),
@@ -141,7 +144,7 @@ func (h *GeneralHandler) HomePage(w http.ResponseWriter, r *http.Request) {
 		Version:     h.version,
 		Environment: getEnvironment(),
 		GoVersion:   runtime.Version(),
-		Uptime:      time.Since(h.startTime).String(),
+		Uptime:      h.clock.Now().Sub(h.startTime).String(),
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
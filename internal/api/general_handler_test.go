@@ -7,11 +7,13 @@ import (
 	"os"
 	"testing"
 	"time"
+
+	"sf_test/internal/clock"
 )
 
 func TestNewGeneralHandler(t *testing.T) {
 	version := "1.0.0"
-	handler := NewGeneralHandler(version)
+	handler := NewGeneralHandler(version, clock.System)
 
 	if handler.version != version {
 		t.Errorf("Expected version %s, got %s", version, handler.version)
@@ -23,7 +25,7 @@ func TestNewGeneralHandler(t *testing.T) {
 }
 
 func TestHealthCheck(t *testing.T) {
-	handler := NewGeneralHandler("1.0.0")
+	handler := NewGeneralHandler("1.0.0", clock.System)
 	req, err := http.NewRequest("GET", "/api/v1/health", nil)
 	if err != nil {
 		t.Fatalf("Could not create request: %v", err)
@@ -47,7 +49,9 @@ func TestHealthCheck(t *testing.T) {
 }
 
 func TestGetAPIInfo(t *testing.T) {
-	handler := NewGeneralHandler("1.0.0")
+	fakeClock := clock.NewFake(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC))
+	handler := NewGeneralHandler("1.0.0", fakeClock)
+	fakeClock.Advance(90 * time.Minute)
 	req, err := http.NewRequest("GET", "/api/v1/info", nil)
 	if err != nil {
 		t.Fatalf("Could not create request: %v", err)
@@ -78,10 +82,14 @@ func TestGetAPIInfo(t *testing.T) {
 	if info["version"] != "1.0.0" {
 		t.Errorf("Expected version '1.0.0', got %v", info["version"])
 	}
+
+	if info["uptime"] != "1h30m0s" {
+		t.Errorf("Expected uptime '1h30m0s', got %v", info["uptime"])
+	}
 }
 
 func TestHomePage(t *testing.T) {
-	handler := NewGeneralHandler("1.0.0")
+	handler := NewGeneralHandler("1.0.0", clock.System)
 	req, err := http.NewRequest("GET", "/", nil)
 	if err != nil {
 		t.Fatalf("Could not create request: %v", err)
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"io"
+	"net/http"
+
+	"sf_test/internal/core"
+)
+
+// maxHolidayImportSize caps the size of an uploaded iCalendar file.
+const maxHolidayImportSize = 5 << 20
+
+type HolidayHandler struct {
+	holidayService core.HolidayService
+}
+
+func NewHolidayHandler(service core.HolidayService) *HolidayHandler {
+	return &HolidayHandler{holidayService: service}
+}
+
+// ImportHolidays accepts a multipart upload with a "file" in iCalendar format
+// and an optional "country". The file replaces the country's holidays, or
+// the workspace-wide ones without a country.
+func (h *HolidayHandler) ImportHolidays(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxHolidayImportSize)
+	if err := r.ParseMultipartForm(maxHolidayImportSize); err != nil {
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		WriteBadRequest(w, r, "Invalid request body", "file is required")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := h.holidayService.ImportHolidays(r.Context(), r.FormValue("country"), data)
+	if err != nil {
+		WriteError(w, r, err, "Failed to import holidays")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(result, "Holidays imported successfully"))
+}
+
+func (h *HolidayHandler) ListHolidays(w http.ResponseWriter, r *http.Request) {
+	holidays, err := h.holidayService.ListHolidays(r.Context())
+	if err != nil {
+		WriteError(w, r, err, "Failed to fetch holidays")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(holidays, "Holidays fetched successfully"))
+}
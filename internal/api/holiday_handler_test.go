@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"sf_test/internal/core"
+	"sf_test/internal/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupHolidayRouter(handler *HolidayHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/holidays/import", handler.ImportHolidays).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/holidays", handler.ListHolidays).Methods(http.MethodGet)
+	return router
+}
+
+func newHolidayImportRequest(t *testing.T, country, ics string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if country != "" {
+		_ = writer.WriteField("country", country)
+	}
+	if ics != "" {
+		part, err := writer.CreateFormFile("file", "holidays.ics")
+		if err != nil {
+			t.Fatalf("Failed to create form file: %v", err)
+		}
+		_, _ = part.Write([]byte(ics))
+	}
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/holidays/import", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestImportHolidays_Success(t *testing.T) {
+	mockService := &HolidayServiceMock{
+		ImportHolidaysFunc: func(ctx context.Context, country string, ics []byte) (*models.HolidayImportResult, error) {
+			return &models.HolidayImportResult{Country: country, Imported: 2}, nil
+		},
+	}
+	router := setupHolidayRouter(NewHolidayHandler(mockService))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, newHolidayImportRequest(t, "DE", "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	call := mockService.ImportHolidaysCalls()[0]
+	assert.Equal(t, "DE", call.Country)
+	assert.Equal(t, "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n", string(call.Ics))
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	assert.Equal(t, float64(2), response["data"].(map[string]interface{})["imported"])
+}
+
+func TestImportHolidays_Errors(t *testing.T) {
+	mockService := &HolidayServiceMock{
+		ImportHolidaysFunc: func(ctx context.Context, country string, ics []byte) (*models.HolidayImportResult, error) {
+			return nil, &core.ValidationError{Message: "invalid iCalendar file"}
+		},
+	}
+	router := setupHolidayRouter(NewHolidayHandler(mockService))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, newHolidayImportRequest(t, "", ""))
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "a file is required")
+	assert.Empty(t, mockService.ImportHolidaysCalls())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, newHolidayImportRequest(t, "", "hello"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Len(t, mockService.ImportHolidaysCalls(), 1)
+}
+
+func TestListHolidays_Success(t *testing.T) {
+	mockService := &HolidayServiceMock{
+		ListHolidaysFunc: func(ctx context.Context) ([]*models.Holiday, error) {
+			return []*models.Holiday{{ID: 1, Date: time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC), Name: "Christmas"}}, nil
+		},
+	}
+	router := setupHolidayRouter(NewHolidayHandler(mockService))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/holidays", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	holiday := response["data"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "Christmas", holiday["name"])
+}
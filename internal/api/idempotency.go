@@ -0,0 +1,145 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"sf_test/internal/auth"
+	"sf_test/internal/core"
+	"sf_test/internal/models"
+)
+
+// maxIdempotentBodySize bounds the request bodies buffered for hashing.
+const maxIdempotentBodySize = 10 << 20
+
+// replayedHeaders are the response headers stored with the response body.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// IdempotencyMiddleware makes POST handlers safe to retry. A request carrying
+// an Idempotency-Key header is answered with the stored response when the key
+// has been seen with the same body, and rejected with 422 when the body differs.
+type IdempotencyMiddleware struct {
+	service core.IdempotencyService
+}
+
+func NewIdempotencyMiddleware(service core.IdempotencyService) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{service: service}
+}
+
+// Wrap applies idempotency handling to next.
+func (m *IdempotencyMiddleware) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+		if err != nil {
+			WriteBadRequest(w, r, "Invalid request body", err.Error())
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(hash[:])
+		// Keys are scoped to the caller so clients cannot collide or replay
+		// each other's responses.
+		scope := r.Method + " " + r.URL.Path
+		if principal, ok := auth.PrincipalFrom(r.Context()); ok {
+			scope = principal.Subject + " " + scope
+			if principal.WorkspaceID != 0 {
+				scope = "workspace:" + strconv.FormatInt(principal.WorkspaceID, 10) + " " + scope
+			}
+		}
+
+		record, err := m.service.Begin(r.Context(), scope, key, requestHash)
+		if errors.Is(err, core.ErrIdempotencyKeyReused) {
+			WriteProblem(w, r, ProblemDetails{
+				Type:   "/problems/idempotency-key-reused",
+				Title:  "Idempotency key reused",
+				Status: http.StatusUnprocessableEntity,
+				Detail: err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			WriteError(w, r, err, "Failed to process idempotency key")
+			return
+		}
+		if record != nil {
+			replay(w, record)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+
+		// The request outlives the client's connection, so store the
+		// outcome regardless of whether the client is still waiting.
+		ctx := context.WithoutCancel(r.Context())
+		if rec.status >= http.StatusInternalServerError {
+			// Server errors are not final; let a retry run the request again.
+			if err := m.service.Release(ctx, scope, key); err != nil {
+				log.Printf("Failed to release idempotency key: %v", err)
+			}
+			return
+		}
+		headers := make(map[string]string)
+		for _, name := range replayedHeaders {
+			if value := rec.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		err = m.service.Complete(ctx, &models.IdempotencyRecord{
+			Scope:       scope,
+			Key:         key,
+			RequestHash: requestHash,
+			StatusCode:  rec.status,
+			Headers:     headers,
+			Body:        rec.body.Bytes(),
+		})
+		if err != nil {
+			log.Printf("Failed to store idempotent response: %v", err)
+		}
+	}
+}
+
+// replay writes a stored response.
+func replay(w http.ResponseWriter, record *models.IdempotencyRecord) {
+	for name, value := range record.Headers {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set("Idempotent-Replayed", strconv.FormatBool(true))
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+// responseRecorder passes a response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sf_test/internal/core"
+	"sf_test/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupIdempotentHandler(service core.IdempotencyService, calls *int, status int) http.HandlerFunc {
+	return NewIdempotencyMiddleware(service).Wrap(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("Location", "/api/v1/sequences/1")
+		WriteResponse(w, status, SuccessResponse(map[string]int64{"id": 1}, "Sequence created successfully"))
+	})
+}
+
+func TestIdempotency_StoresFirstResponse(t *testing.T) {
+	mockService := &IdempotencyServiceMock{
+		BeginFunc: func(ctx context.Context, scope, key, requestHash string) (*models.IdempotencyRecord, error) {
+			return nil, nil
+		},
+		CompleteFunc: func(ctx context.Context, record *models.IdempotencyRecord) error {
+			return nil
+		},
+	}
+	calls := 0
+	handler := setupIdempotentHandler(mockService, &calls, http.StatusCreated)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sequences", bytes.NewReader([]byte(`{"name":"Test"}`)))
+	req.Header.Set("Idempotency-Key", "abc")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "POST /api/v1/sequences", mockService.BeginCalls()[0].Scope)
+
+	stored := mockService.CompleteCalls()[0].Record
+	assert.Equal(t, http.StatusCreated, stored.StatusCode)
+	assert.Equal(t, rec.Body.Bytes(), stored.Body)
+	assert.Equal(t, "/api/v1/sequences/1", stored.Headers["Location"])
+	assert.Equal(t, mockService.BeginCalls()[0].RequestHash, stored.RequestHash)
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	mockService := &IdempotencyServiceMock{
+		BeginFunc: func(ctx context.Context, scope, key, requestHash string) (*models.IdempotencyRecord, error) {
+			return &models.IdempotencyRecord{
+				StatusCode: http.StatusCreated,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       []byte(`{"data":{"id":1}}`),
+			}, nil
+		},
+	}
+	calls := 0
+	handler := setupIdempotentHandler(mockService, &calls, http.StatusCreated)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sequences", bytes.NewReader([]byte(`{"name":"Test"}`)))
+	req.Header.Set("Idempotency-Key", "abc")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, `{"data":{"id":1}}`, rec.Body.String())
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+}
+
+func TestIdempotency_SameBodySameHash(t *testing.T) {
+	mockService := &IdempotencyServiceMock{
+		BeginFunc: func(ctx context.Context, scope, key, requestHash string) (*models.IdempotencyRecord, error) {
+			return nil, nil
+		},
+		CompleteFunc: func(ctx context.Context, record *models.IdempotencyRecord) error {
+			return nil
+		},
+	}
+	calls := 0
+	handler := setupIdempotentHandler(mockService, &calls, http.StatusCreated)
+
+	for _, body := range []string{`{"name":"Test"}`, `{"name":"Test"}`, `{"name":"Other"}`} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sequences", bytes.NewReader([]byte(body)))
+		req.Header.Set("Idempotency-Key", "abc")
+		handler(httptest.NewRecorder(), req)
+	}
+
+	begins := mockService.BeginCalls()
+	assert.Equal(t, begins[0].RequestHash, begins[1].RequestHash)
+	assert.NotEqual(t, begins[0].RequestHash, begins[2].RequestHash)
+}
+
+func TestIdempotency_MismatchedBody(t *testing.T) {
+	mockService := &IdempotencyServiceMock{
+		BeginFunc: func(ctx context.Context, scope, key, requestHash string) (*models.IdempotencyRecord, error) {
+			return nil, core.ErrIdempotencyKeyReused
+		},
+	}
+	calls := 0
+	handler := setupIdempotentHandler(mockService, &calls, http.StatusCreated)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sequences", bytes.NewReader([]byte(`{"name":"Other"}`)))
+	req.Header.Set("Idempotency-Key", "abc")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, 0, calls)
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	mockService := &IdempotencyServiceMock{
+		BeginFunc: func(ctx context.Context, scope, key, requestHash string) (*models.IdempotencyRecord, error) {
+			return nil, &core.ConflictError{Message: "a request with this idempotency key is in progress"}
+		},
+	}
+	calls := 0
+	handler := setupIdempotentHandler(mockService, &calls, http.StatusCreated)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sequences", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Idempotency-Key", "abc")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, 0, calls)
+}
+
+func TestIdempotency_ServerErrorReleasesKey(t *testing.T) {
+	mockService := &IdempotencyServiceMock{
+		BeginFunc: func(ctx context.Context, scope, key, requestHash string) (*models.IdempotencyRecord, error) {
+			return nil, nil
+		},
+		ReleaseFunc: func(ctx context.Context, scope, key string) error {
+			return nil
+		},
+	}
+	calls := 0
+	handler := setupIdempotentHandler(mockService, &calls, http.StatusInternalServerError)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sequences", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Idempotency-Key", "abc")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Len(t, mockService.ReleaseCalls(), 1)
+	assert.Len(t, mockService.CompleteCalls(), 0)
+}
+
+func TestIdempotency_WithoutKey(t *testing.T) {
+	mockService := &IdempotencyServiceMock{
+		BeginFunc: func(ctx context.Context, scope, key, requestHash string) (*models.IdempotencyRecord, error) {
+			return nil, errors.New("unexpected call")
+		},
+	}
+	calls := 0
+	handler := setupIdempotentHandler(mockService, &calls, http.StatusCreated)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sequences", bytes.NewReader([]byte(`{}`)))
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, 1, calls)
+	assert.Len(t, mockService.BeginCalls(), 0)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"sf_test/internal/core"
+
+	"github.com/gorilla/mux"
+)
+
+type JobHandler struct {
+	jobService core.JobService
+}
+
+func NewJobHandler(service core.JobService) *JobHandler {
+	return &JobHandler{jobService: service}
+}
+
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid ID")
+		return
+	}
+
+	job, err := h.jobService.GetJob(r.Context(), id)
+	if err != nil {
+		WriteError(w, r, err, "Failed to fetch job")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(job, "Job fetched successfully"))
+}
+
+func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		WriteBadRequest(w, r, "Invalid query parameter", err.Error())
+		return
+	}
+
+	jobs, next, err := h.jobService.ListJobs(r.Context(), opts)
+	if err != nil {
+		WriteError(w, r, err, "Failed to fetch jobs")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, PageResponse(jobs, opts, next, "Jobs fetched successfully"))
+}
+
+// DownloadJobErrors returns the rejected items of a job as a CSV report.
+func (h *JobHandler) DownloadJobErrors(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid ID")
+		return
+	}
+
+	itemErrors, err := h.jobService.ListJobErrors(r.Context(), id)
+	if err != nil {
+		WriteError(w, r, err, "Failed to fetch job errors")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"job-%d-errors.csv\"", id))
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"row", "reason", "data"})
+	for _, itemError := range itemErrors {
+		writer.Write(append([]string{strconv.Itoa(itemError.Item), itemError.Reason}, itemError.Data...))
+	}
+	writer.Flush()
+}
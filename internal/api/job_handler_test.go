@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sf_test/internal/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupJobRouter(handler *JobHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/jobs", handler.ListJobs).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/jobs/{id}", handler.GetJob).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/jobs/{id}/errors", handler.DownloadJobErrors).Methods(http.MethodGet)
+	return router
+}
+
+func TestGetJob_Success(t *testing.T) {
+	mockService := &JobServiceMock{
+		GetJobFunc: func(ctx context.Context, id int64) (*models.Job, error) {
+			return &models.Job{ID: id, Status: models.JobStatusRunning, TotalItems: 10, ProcessedItems: 4}, nil
+		},
+	}
+	handler := NewJobHandler(mockService)
+	router := setupJobRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/2", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "running", data["status"])
+	assert.Equal(t, float64(4), data["processedItems"])
+}
+
+func TestGetJob_InvalidID(t *testing.T) {
+	mockService := &JobServiceMock{}
+	handler := NewJobHandler(mockService)
+	router := setupJobRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/abc", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestDownloadJobErrors_Success(t *testing.T) {
+	mockService := &JobServiceMock{
+		ListJobErrorsFunc: func(ctx context.Context, id int64) ([]models.JobItemError, error) {
+			return []models.JobItemError{{Item: 3, Reason: "invalid email address", Data: []string{"not-an-email", "Jane"}}}, nil
+		},
+	}
+	handler := NewJobHandler(mockService)
+	router := setupJobRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/2/errors", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+	reader := csv.NewReader(rec.Body)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3", "invalid email address", "not-an-email", "Jane"}, records[1])
+}
+
+func TestDownloadJobErrors_Failure(t *testing.T) {
+	mockService := &JobServiceMock{
+		ListJobErrorsFunc: func(ctx context.Context, id int64) ([]models.JobItemError, error) {
+			return nil, errors.New("database error")
+		},
+	}
+	handler := NewJobHandler(mockService)
+	router := setupJobRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/2/errors", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestListJobs_FilterByStatus(t *testing.T) {
+	mockService := &JobServiceMock{
+		ListJobsFunc: func(ctx context.Context, opts models.ListOptions) ([]*models.Job, string, error) {
+			return []*models.Job{{ID: 1, Status: opts.Filters.Status}}, "cursor", nil
+		},
+	}
+	handler := NewJobHandler(mockService)
+	router := setupJobRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs?status=failed", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	assert.Equal(t, "cursor", response["meta"].(map[string]interface{})["nextCursor"])
+	assert.Equal(t, "failed", mockService.ListJobsCalls()[0].Opts.Filters.Status)
+}
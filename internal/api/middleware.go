@@ -1,13 +1,61 @@
 package api
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"log"
 	"net/http"
+	"regexp"
+	"strings"
+
+	"sf_test/internal/db"
+	"sf_test/internal/requestid"
 )
 
+// requestIDPattern is the form of request IDs accepted from clients; others
+// are replaced so they cannot smuggle anything into logs.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDMiddleware tags every request with an ID, taken from its
+// X-Request-ID header when well formed and generated otherwise. The ID is
+// returned in the response's X-Request-ID header and recorded in the audit
+// log entries of changes the request makes.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(requestid.WithRequestID(r.Context(), id)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// PrimaryReadMiddleware sends the reads of requests that change data to the
+// primary database rather than a replica, so they see their own writes.
+// Clients that need to read their earlier writes, which replicas may not have
+// caught up with yet, can ask for the same with an X-Read-Primary: true header.
+func PrimaryReadMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		safe := r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions
+		if !safe || strings.EqualFold(r.Header.Get("X-Read-Primary"), "true") {
+			r = r.WithContext(db.WithPrimary(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("Request: %s %s", r.Method, r.URL.Path)
+		log.Printf("Request: %s %s [%s]", r.Method, r.URL.Path, requestid.RequestIDFrom(r.Context()))
 		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sf_test/internal/db"
+	"sf_test/internal/requestid"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = requestid.RequestIDFrom(r.Context())
+	}))
+
+	// A well-formed ID from the client is kept.
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", "trace-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "trace-123", seen)
+	assert.Equal(t, "trace-123", rec.Header().Get("X-Request-ID"))
+
+	// Missing or malformed IDs are replaced.
+	for _, header := range []string{"", "bad id\nwith newline"} {
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Request-ID", header)
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Len(t, seen, 32)
+		assert.Equal(t, seen, rec.Header().Get("X-Request-ID"))
+	}
+}
+
+func TestPrimaryReadMiddleware(t *testing.T) {
+	var primary bool
+	handler := PrimaryReadMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primary = db.ReadsPrimary(r.Context())
+	}))
+
+	for _, tc := range []struct {
+		method, header string
+		primary        bool
+	}{
+		{http.MethodGet, "", false},
+		{http.MethodGet, "true", true},
+		{http.MethodHead, "false", false},
+		{http.MethodPost, "", true},
+		{http.MethodPatch, "", true},
+		{http.MethodDelete, "", true},
+	} {
+		req := httptest.NewRequest(tc.method, "/", nil)
+		if tc.header != "" {
+			req.Header.Set("X-Read-Primary", tc.header)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, tc.primary, primary, "%s with X-Read-Primary %q", tc.method, tc.header)
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sf_test/internal/models"
+)
+
+// PageMeta is returned in APIResponse metadata for paginated lists.
+type PageMeta struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// parseListOptions reads the shared list query parameters: limit, cursor,
+// sort, name, status, type, createdAfter and createdBefore.
+func parseListOptions(r *http.Request) (models.ListOptions, error) {
+	query := r.URL.Query()
+	opts := models.ListOptions{
+		Cursor: query.Get("cursor"),
+		Sort:   query.Get("sort"),
+		Filters: models.ListFilters{
+			NameContains: query.Get("name"),
+			Status:       query.Get("status"),
+			Type:         query.Get("type"),
+		},
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > models.MaxPageLimit {
+			return opts, fmt.Errorf("limit must be between 1 and %d", models.MaxPageLimit)
+		}
+		opts.Limit = limit
+	}
+
+	for name, target := range map[string]**time.Time{
+		"createdAfter":  &opts.Filters.CreatedAfter,
+		"createdBefore": &opts.Filters.CreatedBefore,
+	} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return opts, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+		}
+		*target = &t
+	}
+
+	return opts, nil
+}
+
+// PageResponse generates a successful response for one page of a list.
+func PageResponse(data interface{}, opts models.ListOptions, nextCursor string, message string) APIResponse {
+	response := SuccessResponse(data, message)
+	response.Meta = PageMeta{Limit: opts.PageLimit(), NextCursor: nextCursor}
+	return response
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"io"
+	"mime"
+	"net/http"
+)
+
+// MergePatchContentType is the media type of RFC 7396 JSON merge patches.
+const MergePatchContentType = "application/merge-patch+json"
+
+// maxPatchSize bounds the size of a merge patch body.
+const maxPatchSize = 1 << 20
+
+// readMergePatch returns the body of a merge patch request. It writes a
+// problem response and returns false when the request is not a merge patch.
+func readMergePatch(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != MergePatchContentType && mediaType != "application/json") {
+		WriteProblem(w, r, ProblemDetails{
+			Title:  "Unsupported media type",
+			Status: http.StatusUnsupportedMediaType,
+			Detail: "PATCH requests must be sent as " + MergePatchContentType,
+		})
+		return nil, false
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchSize))
+	if err != nil {
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
+		return nil, false
+	}
+	return patch, true
+}
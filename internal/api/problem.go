@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"sf_test/internal/core"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 problem details object. Errors carries
+// per-field validation failures and Operation the index of the failed
+// operation of a batch.
+type ProblemDetails struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Instance  string            `json:"instance,omitempty"`
+	Errors    []core.FieldError `json:"errors,omitempty"`
+	Operation *int              `json:"operation,omitempty"`
+}
+
+// WriteProblem sends problem details with their status code.
+func WriteProblem(w http.ResponseWriter, r *http.Request, problem ProblemDetails) {
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Instance == "" {
+		problem.Instance = r.URL.Path
+	}
+	if problem.Status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	}
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// WriteBadRequest sends a 400 problem for input rejected by the handler itself.
+func WriteBadRequest(w http.ResponseWriter, r *http.Request, title, detail string) {
+	WriteProblem(w, r, ProblemDetails{Title: title, Status: http.StatusBadRequest, Detail: detail})
+}
+
+// WriteError maps a service error to its status code and sends it as problem
+// details. title describes the failed operation and is used for errors that
+// are not domain errors, whose details are logged rather than returned.
+func WriteError(w http.ResponseWriter, r *http.Request, err error, title string) {
+	problem := problemFor(err, title)
+	var batchErr *core.BatchError
+	if errors.As(err, &batchErr) {
+		problem.Operation = &batchErr.Index
+	}
+	WriteProblem(w, r, problem)
+}
+
+// problemFor is the single mapping from domain errors to HTTP problems.
+func problemFor(err error, title string) ProblemDetails {
+	var (
+		notFound     *core.NotFoundError
+		validation   *core.ValidationError
+		conflict     *core.ConflictError
+		precondition *core.PreconditionFailedError
+		unauth       *core.UnauthenticatedError
+		forbidden    *core.ForbiddenError
+	)
+	switch {
+	case errors.As(err, &notFound):
+		return ProblemDetails{Type: "/problems/not-found", Title: "Resource not found", Status: http.StatusNotFound, Detail: notFound.Error()}
+	case errors.As(err, &validation):
+		return ProblemDetails{Type: "/problems/validation-error", Title: "Validation failed", Status: http.StatusBadRequest, Detail: validation.Error(), Errors: validation.Fields}
+	case errors.As(err, &conflict):
+		return ProblemDetails{Type: "/problems/conflict", Title: "Conflict", Status: http.StatusConflict, Detail: conflict.Error()}
+	case errors.As(err, &precondition):
+		return ProblemDetails{Type: "/problems/precondition-failed", Title: "Precondition failed", Status: http.StatusPreconditionFailed, Detail: precondition.Error()}
+	case errors.As(err, &unauth):
+		return ProblemDetails{Type: "/problems/unauthenticated", Title: "Unauthenticated", Status: http.StatusUnauthorized, Detail: unauth.Error()}
+	case errors.As(err, &forbidden):
+		return ProblemDetails{Type: "/problems/forbidden", Title: "Forbidden", Status: http.StatusForbidden, Detail: forbidden.Error()}
+	}
+
+	log.Printf("%s: %v", title, err)
+	return ProblemDetails{Title: title, Status: http.StatusInternalServerError, Detail: "An unexpected error occurred"}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sf_test/internal/core"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteError_MapsDomainErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		title  string
+	}{
+		{"not found", &core.NotFoundError{Resource: "step", ID: 7}, http.StatusNotFound, "Resource not found"},
+		{"validation", &core.ValidationError{Message: "invalid step"}, http.StatusBadRequest, "Validation failed"},
+		{"conflict", &core.ConflictError{Message: "external key already exists"}, http.StatusConflict, "Conflict"},
+		{"precondition", &core.PreconditionFailedError{Message: "stale version"}, http.StatusPreconditionFailed, "Precondition failed"},
+		{"wrapped", fmt.Errorf("update: %w", &core.NotFoundError{Resource: "step"}), http.StatusNotFound, "Resource not found"},
+		{"unexpected", errors.New("connection refused"), http.StatusInternalServerError, "Failed to update step"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/steps/7", nil)
+			rec := httptest.NewRecorder()
+
+			WriteError(rec, req, tt.err, "Failed to update step")
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+			var problem ProblemDetails
+			_ = json.NewDecoder(rec.Body).Decode(&problem)
+			assert.Equal(t, tt.status, problem.Status)
+			assert.Equal(t, tt.title, problem.Title)
+			assert.Equal(t, "/api/v1/steps/7", problem.Instance)
+			assert.NotContains(t, problem.Detail, "connection refused")
+		})
+	}
+}
+
+func TestWriteError_ValidationFields(t *testing.T) {
+	err := &core.ValidationError{
+		Message: "step is invalid",
+		Fields:  []core.FieldError{{Field: "subject", Rule: "required", Message: "subject is required"}},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/steps", nil)
+	rec := httptest.NewRecorder()
+
+	WriteError(rec, req, err, "Failed to create step")
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var problem ProblemDetails
+	_ = json.NewDecoder(rec.Body).Decode(&problem)
+	assert.Equal(t, "/problems/validation-error", problem.Type)
+	assert.Equal(t, "step is invalid", problem.Detail)
+	assert.Equal(t, err.Fields, problem.Errors)
+}
@@ -9,6 +9,7 @@ type APIResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 	Errors  interface{} `json:"errors,omitempty"`
 	Message string      `json:"message,omitempty"`
+	Meta    interface{} `json:"meta,omitempty"`
 }
 
 // WriteResponse sends a JSON response with the given status code.
@@ -6,14 +6,33 @@ import (
 	"os"
 	"path/filepath"
 
+	"sf_test/internal/auth"
+
 	"github.com/gorilla/mux"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 type Routes struct {
-	SequenceHandler *SequenceHandler
-	StepHandler     *StepHandler
-	GeneralHandler  *GeneralHandler
+	SequenceHandler   *SequenceHandler
+	StepHandler       *StepHandler
+	GeneralHandler    *GeneralHandler
+	ContactHandler    *ContactHandler
+	JobHandler        *JobHandler
+	SearchHandler     *SearchHandler
+	BatchHandler      *BatchHandler
+	APIKeyHandler     *APIKeyHandler
+	TeamHandler       *TeamHandler
+	AuditHandler      *AuditHandler
+	EnrollmentHandler *EnrollmentHandler
+	HolidayHandler    *HolidayHandler
+	// Auth resolves credentials to principals. Without it every route that
+	// requires a scope rejects its requests.
+	Auth *AuthMiddleware
+	// Tenant binds authenticated requests to their workspace. Without it
+	// requests reaching the repositories fail for lack of a workspace.
+	Tenant *TenantMiddleware
+	// Idempotency is optional; without it Idempotency-Key headers are ignored.
+	Idempotency *IdempotencyMiddleware
 }
 
 // NewRouter creates a new router and sets up all routes.
@@ -43,22 +62,100 @@ func NewRouter(routes *Routes) *mux.Router {
 	// API routes
 	api := router.PathPrefix("/api/v1").Subrouter()
 
-	// General routes
+	// Create endpoints accept an Idempotency-Key so clients can retry them safely
+	idempotent := func(handler http.HandlerFunc) http.HandlerFunc {
+		if routes.Idempotency == nil {
+			return handler
+		}
+		return routes.Idempotency.Wrap(handler)
+	}
+
+	// General routes are public
 	api.HandleFunc("/health", routes.GeneralHandler.HealthCheck).Methods(http.MethodGet)
 	api.HandleFunc("/info", routes.GeneralHandler.GetAPIInfo).Methods(http.MethodGet)
 
+	read := func(handler http.HandlerFunc) http.HandlerFunc {
+		return RequireScope(auth.ScopeSequencesRead, handler)
+	}
+	write := func(handler http.HandlerFunc) http.HandlerFunc {
+		return RequireScope(auth.ScopeSequencesWrite, handler)
+	}
+
 	// Sequence routes
-	api.HandleFunc("/sequences", routes.SequenceHandler.CreateSequence).Methods(http.MethodPost)
-	api.HandleFunc("/sequences/{id}", routes.SequenceHandler.UpdateTracking).Methods(http.MethodPut)
-	api.HandleFunc("/sequences/{id}", routes.SequenceHandler.GetSequence).Methods(http.MethodGet)
+	api.HandleFunc("/sequences", write(idempotent(routes.SequenceHandler.CreateSequence))).Methods(http.MethodPost)
+	api.HandleFunc("/sequences", read(routes.SequenceHandler.ListSequences)).Methods(http.MethodGet)
+	api.HandleFunc("/sequences/{id}", write(routes.SequenceHandler.UpdateTracking)).Methods(http.MethodPut)
+	api.HandleFunc("/sequences/{id}", write(routes.SequenceHandler.PatchSequence)).Methods(http.MethodPatch)
+	api.HandleFunc("/sequences/{id}", read(routes.SequenceHandler.GetSequence)).Methods(http.MethodGet)
+	api.HandleFunc("/sequences/{id}", write(routes.SequenceHandler.DeleteSequence)).Methods(http.MethodDelete)
+	api.HandleFunc("/sequences/{id}/activate", write(routes.SequenceHandler.ActivateSequence)).Methods(http.MethodPost)
+	api.HandleFunc("/sequences/{id}/deactivate", write(routes.SequenceHandler.DeactivateSequence)).Methods(http.MethodPost)
+	api.HandleFunc("/sequences/import", write(idempotent(routes.SequenceHandler.ImportSequence))).Methods(http.MethodPost)
+	api.HandleFunc("/sequences/{id}/export", read(routes.SequenceHandler.ExportSequence)).Methods(http.MethodGet)
+	api.HandleFunc("/sequences/{id}/forecast", read(routes.SequenceHandler.ForecastSequence)).Methods(http.MethodPost)
+
+	// Enrollment routes
+	api.HandleFunc("/enrollments/{id}/timeline", read(routes.EnrollmentHandler.GetTimeline)).Methods(http.MethodGet)
+	api.HandleFunc("/sequences/{id}/timeline", read(routes.EnrollmentHandler.PreviewTimeline)).Methods(http.MethodGet)
+
+	// Holiday routes
+	api.HandleFunc("/holidays/import", RequireScope(auth.ScopeSendsAdmin, routes.HolidayHandler.ImportHolidays)).Methods(http.MethodPost)
+	api.HandleFunc("/holidays", read(routes.HolidayHandler.ListHolidays)).Methods(http.MethodGet)
 
 	// Step routes
-	api.HandleFunc("/steps", routes.StepHandler.CreateStep).Methods(http.MethodPost)
-	api.HandleFunc("/steps/{id}", routes.StepHandler.UpdateStep).Methods(http.MethodPut)
-	api.HandleFunc("/steps/{id}", routes.StepHandler.DeleteStep).Methods(http.MethodDelete)
-	api.HandleFunc("/steps", routes.StepHandler.ListSteps).Methods(http.MethodGet)
+	api.HandleFunc("/steps", write(idempotent(routes.StepHandler.CreateStep))).Methods(http.MethodPost)
+	api.HandleFunc("/steps/{id}", write(routes.StepHandler.UpdateStep)).Methods(http.MethodPut)
+	api.HandleFunc("/steps/{id}", write(routes.StepHandler.PatchStep)).Methods(http.MethodPatch)
+	api.HandleFunc("/steps/{id}", write(routes.StepHandler.DeleteStep)).Methods(http.MethodDelete)
+	api.HandleFunc("/steps", read(routes.StepHandler.ListSteps)).Methods(http.MethodGet)
+
+	// Contact routes
+	api.HandleFunc("/contacts/import", RequireScope(auth.ScopeContactsWrite, routes.ContactHandler.ImportContacts)).Methods(http.MethodPost)
+
+	// Job routes
+	api.HandleFunc("/jobs", RequireScope(auth.ScopeJobsRead, routes.JobHandler.ListJobs)).Methods(http.MethodGet)
+	api.HandleFunc("/jobs/{id}", RequireScope(auth.ScopeJobsRead, routes.JobHandler.GetJob)).Methods(http.MethodGet)
+	api.HandleFunc("/jobs/{id}/errors", RequireScope(auth.ScopeJobsRead, routes.JobHandler.DownloadJobErrors)).Methods(http.MethodGet)
+
+	// Batch routes
+	api.HandleFunc("/batch", write(idempotent(routes.BatchHandler.ExecuteBatch))).Methods(http.MethodPost)
+
+	// Search routes
+	api.HandleFunc("/search", read(routes.SearchHandler.Search)).Methods(http.MethodGet)
+
+	// API key routes
+	keysAdmin := func(handler http.HandlerFunc) http.HandlerFunc {
+		return RequireScope(auth.ScopeKeysAdmin, handler)
+	}
+	api.HandleFunc("/api-keys", keysAdmin(routes.APIKeyHandler.CreateAPIKey)).Methods(http.MethodPost)
+	api.HandleFunc("/api-keys", keysAdmin(routes.APIKeyHandler.ListAPIKeys)).Methods(http.MethodGet)
+	api.HandleFunc("/api-keys/{id}", keysAdmin(routes.APIKeyHandler.RevokeAPIKey)).Methods(http.MethodDelete)
+
+	// User and team routes
+	teamsAdmin := func(handler http.HandlerFunc) http.HandlerFunc {
+		return RequireScope(auth.ScopeTeamsAdmin, handler)
+	}
+	api.HandleFunc("/users", teamsAdmin(routes.TeamHandler.CreateUser)).Methods(http.MethodPost)
+	api.HandleFunc("/users", teamsAdmin(routes.TeamHandler.ListUsers)).Methods(http.MethodGet)
+	api.HandleFunc("/teams", teamsAdmin(routes.TeamHandler.CreateTeam)).Methods(http.MethodPost)
+	api.HandleFunc("/teams", teamsAdmin(routes.TeamHandler.ListTeams)).Methods(http.MethodGet)
+	api.HandleFunc("/teams/{id}/members", teamsAdmin(routes.TeamHandler.ListMembers)).Methods(http.MethodGet)
+	api.HandleFunc("/teams/{id}/members/{userId}", teamsAdmin(routes.TeamHandler.SetMember)).Methods(http.MethodPut)
+	api.HandleFunc("/teams/{id}/members/{userId}", teamsAdmin(routes.TeamHandler.RemoveMember)).Methods(http.MethodDelete)
+
+	// Audit routes
+	api.HandleFunc("/audit", RequireScope(auth.ScopeAuditRead, routes.AuditHandler.ListAudit)).Methods(http.MethodGet)
+
+	if routes.Auth != nil {
+		api.Use(routes.Auth.Authenticate)
+	}
+	if routes.Tenant != nil {
+		api.Use(routes.Tenant.Resolve)
+	}
 
 	// Middleware (optional, e.g., logging)
+	router.Use(RequestIDMiddleware)
+	router.Use(PrimaryReadMiddleware)
 	router.Use(LoggingMiddleware)
 
 	return router
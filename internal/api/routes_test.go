@@ -6,14 +6,25 @@ import (
 	"os"
 	"testing"
 
+	"sf_test/internal/clock"
+
 	"github.com/stretchr/testify/assert"
 )
 
 func setupRoutes() *Routes {
 	return &Routes{
-		SequenceHandler: &SequenceHandler{},
-		StepHandler:     &StepHandler{},
-		GeneralHandler:  NewGeneralHandler("1.0.0"),
+		SequenceHandler:   &SequenceHandler{},
+		StepHandler:       &StepHandler{},
+		GeneralHandler:    NewGeneralHandler("1.0.0", clock.System),
+		ContactHandler:    &ContactHandler{},
+		JobHandler:        &JobHandler{},
+		SearchHandler:     &SearchHandler{},
+		BatchHandler:      &BatchHandler{},
+		APIKeyHandler:     &APIKeyHandler{},
+		TeamHandler:       &TeamHandler{},
+		AuditHandler:      &AuditHandler{},
+		EnrollmentHandler: &EnrollmentHandler{},
+		HolidayHandler:    &HolidayHandler{},
 	}
 }
 
@@ -99,3 +110,29 @@ func TestNewRouter_GeneralRoutes(t *testing.T) {
 		assert.Equal(t, test.statusCode, rec.Code, "Expected status code %d for %s", test.statusCode, test.path)
 	}
 }
+
+func TestNewRouter_ProtectedRoutesRequireAuthentication(t *testing.T) {
+	routes := setupRoutes()
+	router := NewRouter(routes)
+
+	tests := []struct {
+		path   string
+		method string
+	}{
+		{"/api/v1/sequences", http.MethodGet},
+		{"/api/v1/steps", http.MethodPost},
+		{"/api/v1/jobs", http.MethodGet},
+		{"/api/v1/api-keys", http.MethodGet},
+		{"/api/v1/sequences/1/activate", http.MethodPost},
+		{"/api/v1/teams", http.MethodGet},
+		{"/api/v1/audit", http.MethodGet},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, "Expected status code 401 for %s %s", test.method, test.path)
+	}
+}
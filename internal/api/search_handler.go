@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"sf_test/internal/core"
+)
+
+type SearchHandler struct {
+	searchService core.SearchService
+}
+
+func NewSearchHandler(service core.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: service}
+}
+
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		WriteBadRequest(w, r, "Invalid query parameter", "q is required")
+		return
+	}
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > 100 {
+			WriteBadRequest(w, r, "Invalid query parameter", "limit must be between 1 and 100")
+			return
+		}
+	}
+
+	hits, err := h.searchService.Search(r.Context(), query, limit)
+	if err != nil {
+		WriteError(w, r, err, "Failed to search")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(hits, "Search completed successfully"))
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sf_test/internal/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSearchRouter(handler *SearchHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/search", handler.Search).Methods(http.MethodGet)
+	return router
+}
+
+func TestSearch_Success(t *testing.T) {
+	mockService := &SearchServiceMock{
+		SearchFunc: func(ctx context.Context, query string, limit int) ([]*models.SearchHit, error) {
+			return []*models.SearchHit{{
+				Type:     models.SearchHitStep,
+				Rank:     0.6,
+				Snippet:  "Join our <mark>webinar</mark> next week",
+				Sequence: models.SearchSequence{ID: 1, Name: "Event follow-up"},
+				Step:     &models.SearchStep{ID: 4, Subject: "Reminder"},
+			}}, nil
+		},
+	}
+	handler := NewSearchHandler(mockService)
+	router := setupSearchRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q=webinar&limit=5", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	hits := response["data"].([]interface{})
+	assert.Len(t, hits, 1)
+	hit := hits[0].(map[string]interface{})
+	assert.Equal(t, "step", hit["type"])
+	assert.Equal(t, "Event follow-up", hit["sequence"].(map[string]interface{})["name"])
+	assert.Equal(t, "webinar", mockService.SearchCalls()[0].Query)
+	assert.Equal(t, 5, mockService.SearchCalls()[0].Limit)
+}
+
+func TestSearch_MissingQuery(t *testing.T) {
+	mockService := &SearchServiceMock{}
+	handler := NewSearchHandler(mockService)
+	router := setupSearchRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q=%20", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Len(t, mockService.SearchCalls(), 0)
+}
+
+func TestSearch_InvalidLimit(t *testing.T) {
+	mockService := &SearchServiceMock{}
+	handler := NewSearchHandler(mockService)
+	router := setupSearchRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q=webinar&limit=0", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -22,13 +23,13 @@ func NewSequenceHandler(service core.SequenceService) *SequenceHandler {
 func (h *SequenceHandler) CreateSequence(w http.ResponseWriter, r *http.Request) {
 	var sequence models.Sequence
 	if err := json.NewDecoder(r.Body).Decode(&sequence); err != nil {
-		WriteResponse(w, http.StatusBadRequest, ErrorResponse(err.Error(), "Invalid request body"))
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
 		return
 	}
 
 	id, err := h.sequenceService.CreateSequence(r.Context(), &sequence)
 	if err != nil {
-		WriteResponse(w, http.StatusInternalServerError, ErrorResponse(err.Error(), "Failed to create sequence"))
+		WriteError(w, r, err, "Failed to create sequence")
 		return
 	}
 
@@ -40,7 +41,7 @@ func (h *SequenceHandler) UpdateTracking(w http.ResponseWriter, r *http.Request)
 	idStr := vars["id"]
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil || id <= 0 {
-		WriteResponse(w, http.StatusBadRequest, ErrorResponse("Invalid ID", "Invalid query parameter"))
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid ID")
 		return
 	}
 
@@ -49,32 +50,219 @@ func (h *SequenceHandler) UpdateTracking(w http.ResponseWriter, r *http.Request)
 		ClickTracking bool `json:"clickTracking"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		WriteResponse(w, http.StatusBadRequest, ErrorResponse(err.Error(), "Invalid request body"))
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
 		return
 	}
-	err = h.sequenceService.UpdateTracking(r.Context(), id, payload.OpenTracking, payload.ClickTracking)
+	version, err := ifMatchVersion(r)
 	if err != nil {
-		WriteResponse(w, http.StatusInternalServerError, ErrorResponse(err.Error(), "Failed to update tracking"))
+		WriteError(w, r, err, "Failed to update tracking")
+		return
+	}
+	version, err = h.sequenceService.UpdateTracking(r.Context(), id, payload.OpenTracking, payload.ClickTracking, version)
+	if err != nil {
+		WriteError(w, r, err, "Failed to update tracking")
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(version))
 	WriteResponse(w, http.StatusOK, SuccessResponse(nil, "Tracking updated successfully"))
 }
 
+func (h *SequenceHandler) ActivateSequence(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, true)
+}
+
+func (h *SequenceHandler) DeactivateSequence(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, false)
+}
+
+func (h *SequenceHandler) setActive(w http.ResponseWriter, r *http.Request, active bool) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid ID")
+		return
+	}
+
+	failure, success := "Failed to activate sequence", "Sequence activated successfully"
+	if !active {
+		failure, success = "Failed to deactivate sequence", "Sequence deactivated successfully"
+	}
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		WriteError(w, r, err, failure)
+		return
+	}
+	version, err = h.sequenceService.SetSequenceActive(r.Context(), id, active, version)
+	if err != nil {
+		WriteError(w, r, err, failure)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(version))
+	WriteResponse(w, http.StatusOK, SuccessResponse(nil, success))
+}
+
+func (h *SequenceHandler) DeleteSequence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid ID")
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		WriteError(w, r, err, "Failed to delete sequence")
+		return
+	}
+	if err := h.sequenceService.DeleteSequence(r.Context(), id, version); err != nil {
+		WriteError(w, r, err, "Failed to delete sequence")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(nil, "Sequence deleted successfully"))
+}
+
+func (h *SequenceHandler) PatchSequence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid ID")
+		return
+	}
+	patch, ok := readMergePatch(w, r)
+	if !ok {
+		return
+	}
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		WriteError(w, r, err, "Failed to update sequence")
+		return
+	}
+
+	sequence, err := h.sequenceService.PatchSequence(r.Context(), id, patch, version)
+	if err != nil {
+		WriteError(w, r, err, "Failed to update sequence")
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(sequence.Version))
+	WriteResponse(w, http.StatusOK, SuccessResponse(sequence, "Sequence updated successfully"))
+}
+
 func (h *SequenceHandler) GetSequence(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil || id <= 0 {
-		WriteResponse(w, http.StatusBadRequest, ErrorResponse("Invalid ID", "Invalid query parameter"))
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid ID")
 		return
 	}
 
 	sequence, err := h.sequenceService.GetSequence(r.Context(), id)
 	if err != nil {
-		WriteResponse(w, http.StatusInternalServerError, ErrorResponse(err.Error(), "Failed to fetch sequence"))
+		WriteError(w, r, err, "Failed to fetch sequence")
+		return
+	}
+	if notModified(w, r, versionETag(sequence.Version)) {
 		return
 	}
 
 	WriteResponse(w, http.StatusOK, SuccessResponse(sequence, "Sequence fetched successfully"))
 }
+
+func (h *SequenceHandler) ListSequences(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		WriteBadRequest(w, r, "Invalid query parameter", err.Error())
+		return
+	}
+
+	sequences, next, err := h.sequenceService.ListSequences(r.Context(), opts)
+	if err != nil {
+		WriteError(w, r, err, "Failed to fetch sequences")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, PageResponse(sequences, opts, next, "Sequences fetched successfully"))
+}
+
+func (h *SequenceHandler) ExportSequence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid ID")
+		return
+	}
+
+	bundle, err := h.sequenceService.ExportSequence(r.Context(), id)
+	if err != nil {
+		WriteError(w, r, err, "Failed to export sequence")
+		return
+	}
+
+	// The bundle is written as-is so it can be posted straight back to the import endpoint.
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"sequence-%d.json\"", id))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(bundle)
+}
+
+func (h *SequenceHandler) ForecastSequence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid ID")
+		return
+	}
+
+	var req models.ForecastRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
+		return
+	}
+
+	forecast, err := h.sequenceService.ForecastSequence(r.Context(), id, &req)
+	if err != nil {
+		WriteError(w, r, err, "Failed to forecast sequence")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(forecast, "Sequence forecast successfully"))
+}
+
+func (h *SequenceHandler) ImportSequence(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if dryRunStr := r.URL.Query().Get("dryRun"); dryRunStr != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			WriteBadRequest(w, r, "Invalid query parameter", "Invalid dryRun")
+			return
+		}
+	}
+
+	var bundle models.SequenceBundle
+	if err := json.NewDecoder(r.Body).Decode(&bundle); err != nil {
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := h.sequenceService.ImportSequence(r.Context(), &bundle, dryRun)
+	if err != nil {
+		WriteError(w, r, err, "Failed to import sequence")
+		return
+	}
+
+	if dryRun {
+		WriteResponse(w, http.StatusOK, SuccessResponse(result, "Sequence import validated successfully"))
+		return
+	}
+	WriteResponse(w, http.StatusCreated, SuccessResponse(result, "Sequence imported successfully"))
+}
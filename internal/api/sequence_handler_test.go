@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"sf_test/internal/core"
 	"sf_test/internal/models"
 
 	"github.com/gorilla/mux"
@@ -18,8 +20,16 @@ import (
 func setupRouter(handler *SequenceHandler) *mux.Router {
 	router := mux.NewRouter()
 	router.HandleFunc("/api/v1/sequences", handler.CreateSequence).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/sequences", handler.ListSequences).Methods(http.MethodGet)
 	router.HandleFunc("/api/v1/sequences/{id}", handler.UpdateTracking).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/sequences/{id}", handler.PatchSequence).Methods(http.MethodPatch)
 	router.HandleFunc("/api/v1/sequences/{id}", handler.GetSequence).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/sequences/{id}", handler.DeleteSequence).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/sequences/{id}/activate", handler.ActivateSequence).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/sequences/{id}/deactivate", handler.DeactivateSequence).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/sequences/import", handler.ImportSequence).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/sequences/{id}/export", handler.ExportSequence).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/sequences/{id}/forecast", handler.ForecastSequence).Methods(http.MethodPost)
 	return router
 }
 
@@ -60,13 +70,13 @@ func TestCreateSequence_InvalidBody(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 	var response map[string]interface{}
 	_ = json.NewDecoder(rec.Body).Decode(&response)
-	assert.Equal(t, "Invalid request body", response["message"])
+	assert.Equal(t, "Invalid request body", response["title"])
 }
 
 func TestUpdateTracking_Success(t *testing.T) {
 	mockService := &SequenceServiceMock{
-		UpdateTrackingFunc: func(ctx context.Context, id int64, openTracking, clickTracking bool) error {
-			return nil
+		UpdateTrackingFunc: func(ctx context.Context, id int64, openTracking, clickTracking bool, version int64) (int64, error) {
+			return 2, nil
 		},
 	}
 	handler := NewSequenceHandler(mockService)
@@ -88,6 +98,49 @@ func TestUpdateTracking_Success(t *testing.T) {
 	assert.Equal(t, "Tracking updated successfully", response["message"])
 }
 
+func TestUpdateTracking_IfMatch(t *testing.T) {
+	mockService := &SequenceServiceMock{
+		UpdateTrackingFunc: func(ctx context.Context, id int64, openTracking, clickTracking bool, version int64) (int64, error) {
+			return version + 1, nil
+		},
+	}
+	handler := NewSequenceHandler(mockService)
+	router := setupRouter(handler)
+
+	body, _ := json.Marshal(map[string]bool{"openTracking": true})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/sequences/1", bytes.NewReader(body))
+	req.Header.Set("If-Match", `"3"`)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+	assert.Equal(t, int64(3), mockService.UpdateTrackingCalls()[0].Version)
+}
+
+func TestUpdateTracking_StaleVersion(t *testing.T) {
+	mockService := &SequenceServiceMock{
+		UpdateTrackingFunc: func(ctx context.Context, id int64, openTracking, clickTracking bool, version int64) (int64, error) {
+			return 0, &core.PreconditionFailedError{Message: "sequence 1 has been modified since version was read"}
+		},
+	}
+	handler := NewSequenceHandler(mockService)
+	router := setupRouter(handler)
+
+	body, _ := json.Marshal(map[string]bool{"openTracking": true})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/sequences/1", bytes.NewReader(body))
+	req.Header.Set("If-Match", `"3"`)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	assert.Equal(t, "Precondition failed", response["title"])
+}
+
 func TestUpdateTracking_InvalidID(t *testing.T) {
 	mockService := &SequenceServiceMock{}
 	handler := NewSequenceHandler(mockService)
@@ -101,7 +154,101 @@ func TestUpdateTracking_InvalidID(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 	var response map[string]interface{}
 	_ = json.NewDecoder(rec.Body).Decode(&response)
-	assert.Equal(t, "Invalid query parameter", response["message"])
+	assert.Equal(t, "Invalid query parameter", response["title"])
+}
+
+func TestActivateSequence_Success(t *testing.T) {
+	mockService := &SequenceServiceMock{
+		SetSequenceActiveFunc: func(ctx context.Context, id int64, active bool, version int64) (int64, error) {
+			return version + 1, nil
+		},
+	}
+	router := setupRouter(NewSequenceHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sequences/1/activate", nil)
+	req.Header.Set("If-Match", `"3"`)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+	assert.True(t, mockService.SetSequenceActiveCalls()[0].Active)
+}
+
+func TestDeactivateSequence_Forbidden(t *testing.T) {
+	mockService := &SequenceServiceMock{
+		SetSequenceActiveFunc: func(ctx context.Context, id int64, active bool, version int64) (int64, error) {
+			return 0, &core.ForbiddenError{Message: "manage requires the owner role in team 1"}
+		},
+	}
+	router := setupRouter(NewSequenceHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sequences/1/deactivate", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.False(t, mockService.SetSequenceActiveCalls()[0].Active)
+}
+
+func TestDeleteSequence_Success(t *testing.T) {
+	mockService := &SequenceServiceMock{
+		DeleteSequenceFunc: func(ctx context.Context, id int64, version int64) error {
+			return nil
+		},
+	}
+	router := setupRouter(NewSequenceHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/sequences/5", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int64(5), mockService.DeleteSequenceCalls()[0].ID)
+}
+
+func TestPatchSequence_Success(t *testing.T) {
+	mockService := &SequenceServiceMock{
+		PatchSequenceFunc: func(ctx context.Context, id int64, patch []byte, version int64) (*models.Sequence, error) {
+			return &models.Sequence{ID: id, Name: "Test Sequence", ClickTrackingEnabled: true, Version: 2}, nil
+		},
+	}
+	handler := NewSequenceHandler(mockService)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/sequences/1", bytes.NewReader([]byte(`{"clickTrackingEnabled":true}`)))
+	req.Header.Set("Content-Type", MergePatchContentType)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+	assert.Equal(t, int64(0), mockService.PatchSequenceCalls()[0].Version)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	assert.Equal(t, "Sequence updated successfully", response["message"])
+}
+
+func TestPatchSequence_NotFound(t *testing.T) {
+	mockService := &SequenceServiceMock{
+		PatchSequenceFunc: func(ctx context.Context, id int64, patch []byte, version int64) (*models.Sequence, error) {
+			return nil, &core.NotFoundError{Resource: "sequence", ID: id}
+		},
+	}
+	handler := NewSequenceHandler(mockService)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/sequences/9", bytes.NewReader([]byte(`{"name":"New name"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
 }
 
 func TestGetSequence_Success(t *testing.T) {
@@ -124,6 +271,31 @@ func TestGetSequence_Success(t *testing.T) {
 	assert.Equal(t, "Sequence fetched successfully", response["message"])
 }
 
+func TestGetSequence_ETag(t *testing.T) {
+	mockService := &SequenceServiceMock{
+		GetSequenceFunc: func(ctx context.Context, id int64) (*models.Sequence, error) {
+			return &models.Sequence{ID: id, Name: "Test Sequence", Version: 5}, nil
+		},
+	}
+	handler := NewSequenceHandler(mockService)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sequences/1", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"5"`, rec.Header().Get("ETag"))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/sequences/1", nil)
+	req.Header.Set("If-None-Match", `"5"`)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
+
 func TestGetSequence_InvalidID(t *testing.T) {
 	mockService := &SequenceServiceMock{}
 	handler := NewSequenceHandler(mockService)
@@ -137,13 +309,13 @@ func TestGetSequence_InvalidID(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 	var response map[string]interface{}
 	_ = json.NewDecoder(rec.Body).Decode(&response)
-	assert.Equal(t, "Invalid query parameter", response["message"])
+	assert.Equal(t, "Invalid query parameter", response["title"])
 }
 
 func TestGetSequence_NotFound(t *testing.T) {
 	mockService := &SequenceServiceMock{
 		GetSequenceFunc: func(ctx context.Context, id int64) (*models.Sequence, error) {
-			return nil, errors.New("not found")
+			return nil, &core.NotFoundError{Resource: "sequence", ID: id}
 		},
 	}
 	handler := NewSequenceHandler(mockService)
@@ -154,8 +326,178 @@ func TestGetSequence_NotFound(t *testing.T) {
 
 	router.ServeHTTP(rec, req)
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	assert.Equal(t, "Resource not found", response["title"])
+	assert.Equal(t, "sequence 99 not found", response["detail"])
+	assert.Equal(t, "/api/v1/sequences/99", response["instance"])
+}
+
+func TestExportSequence_Success(t *testing.T) {
+	mockService := &SequenceServiceMock{
+		ExportSequenceFunc: func(ctx context.Context, id int64) (*models.SequenceBundle, error) {
+			return models.NewSequenceBundle(&models.Sequence{ID: id, Name: "Test Sequence"}, time.Now()), nil
+		},
+	}
+	handler := NewSequenceHandler(mockService)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sequences/3/export", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "sequence-3.json")
+	var bundle models.SequenceBundle
+	_ = json.NewDecoder(rec.Body).Decode(&bundle)
+	assert.Equal(t, models.SequenceBundleSchemaVersion, bundle.SchemaVersion)
+	assert.Equal(t, int64(3), bundle.Sequence.SourceID)
+	assert.Equal(t, "Test Sequence", bundle.Sequence.Name)
+}
+
+func TestImportSequence_DryRun(t *testing.T) {
+	mockService := &SequenceServiceMock{
+		ImportSequenceFunc: func(ctx context.Context, bundle *models.SequenceBundle, dryRun bool) (*models.ImportResult, error) {
+			return &models.ImportResult{DryRun: dryRun}, nil
+		},
+	}
+	handler := NewSequenceHandler(mockService)
+	router := setupRouter(handler)
+
+	body, _ := json.Marshal(models.SequenceBundle{SchemaVersion: models.SequenceBundleSchemaVersion})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sequences/import?dryRun=true", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, mockService.ImportSequenceCalls(), 1)
+	assert.True(t, mockService.ImportSequenceCalls()[0].DryRun)
+}
+
+func TestImportSequence_Created(t *testing.T) {
+	mockService := &SequenceServiceMock{
+		ImportSequenceFunc: func(ctx context.Context, bundle *models.SequenceBundle, dryRun bool) (*models.ImportResult, error) {
+			return &models.ImportResult{Sequence: models.ImportedResource{SourceID: 3, ID: 10}}, nil
+		},
+	}
+	handler := NewSequenceHandler(mockService)
+	router := setupRouter(handler)
+
+	body, _ := json.Marshal(models.SequenceBundle{SchemaVersion: models.SequenceBundleSchemaVersion})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sequences/import", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
 	var response map[string]interface{}
 	_ = json.NewDecoder(rec.Body).Decode(&response)
-	assert.Equal(t, "Failed to fetch sequence", response["message"])
+	assert.Equal(t, "Sequence imported successfully", response["message"])
+	assert.False(t, mockService.ImportSequenceCalls()[0].DryRun)
+}
+
+func TestImportSequence_InvalidDryRun(t *testing.T) {
+	mockService := &SequenceServiceMock{}
+	handler := NewSequenceHandler(mockService)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sequences/import?dryRun=maybe", bytes.NewReader([]byte("{}")))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestForecastSequence_Success(t *testing.T) {
+	mockService := &SequenceServiceMock{
+		ForecastSequenceFunc: func(ctx context.Context, id int64, req *models.ForecastRequest) (*models.Forecast, error) {
+			return &models.Forecast{SequenceID: id, StartDate: req.StartDate, FinishDate: "2024-03-06"}, nil
+		},
+	}
+	handler := NewSequenceHandler(mockService)
+	router := setupRouter(handler)
+
+	body := `{"contactCount": 100, "startDate": "2024-03-04", "mailboxes": [{"email": "sales@example.com", "dailyLimit": 50}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sequences/7/forecast", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	calls := mockService.ForecastSequenceCalls()
+	assert.Len(t, calls, 1)
+	assert.Equal(t, int64(7), calls[0].ID)
+	assert.Equal(t, 100, calls[0].Req.ContactCount)
+	assert.Equal(t, []models.ForecastMailbox{{Email: "sales@example.com", DailyLimit: 50}}, calls[0].Req.Mailboxes)
+}
+
+func TestForecastSequence_InvalidBody(t *testing.T) {
+	mockService := &SequenceServiceMock{}
+	handler := NewSequenceHandler(mockService)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sequences/7/forecast", strings.NewReader("{"))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, mockService.ForecastSequenceCalls())
+}
+
+func TestListSequences_Success(t *testing.T) {
+	mockService := &SequenceServiceMock{
+		ListSequencesFunc: func(ctx context.Context, opts models.ListOptions) ([]*models.Sequence, string, error) {
+			return []*models.Sequence{{ID: 1, Name: "Webinar follow-up"}}, "", nil
+		},
+	}
+	handler := NewSequenceHandler(mockService)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sequences?name=webinar&sort=name", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	assert.Len(t, response["data"].([]interface{}), 1)
+	assert.NotContains(t, response["meta"].(map[string]interface{}), "nextCursor")
+	assert.Equal(t, "webinar", mockService.ListSequencesCalls()[0].Opts.Filters.NameContains)
+}
+
+func TestListSequences_InvalidSort(t *testing.T) {
+	mockService := &SequenceServiceMock{
+		ListSequencesFunc: func(ctx context.Context, opts models.ListOptions) ([]*models.Sequence, string, error) {
+			return nil, "", &core.ValidationError{Message: "invalid sort field", Err: models.ErrInvalidSort}
+		},
+	}
+	handler := NewSequenceHandler(mockService)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sequences?sort=color", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestListSequences_InvalidCreatedBefore(t *testing.T) {
+	mockService := &SequenceServiceMock{}
+	handler := NewSequenceHandler(mockService)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sequences?createdBefore=yesterday", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
 }
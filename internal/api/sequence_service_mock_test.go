@@ -5,8 +5,8 @@ package api
 
 import (
 	"context"
-	"sf_test/internal/models"
 	"sf_test/internal/core"
+	"sf_test/internal/models"
 	"sync"
 )
 
@@ -23,10 +23,31 @@ var _ core.SequenceService = &SequenceServiceMock{}
 //			CreateSequenceFunc: func(ctx context.Context, sequence *models.Sequence) (int64, error) {
 //				panic("mock out the CreateSequence method")
 //			},
+//			DeleteSequenceFunc: func(ctx context.Context, id int64, version int64) error {
+//				panic("mock out the DeleteSequence method")
+//			},
+//			ExportSequenceFunc: func(ctx context.Context, id int64) (*models.SequenceBundle, error) {
+//				panic("mock out the ExportSequence method")
+//			},
+//			ForecastSequenceFunc: func(ctx context.Context, id int64, req *models.ForecastRequest) (*models.Forecast, error) {
+//				panic("mock out the ForecastSequence method")
+//			},
 //			GetSequenceFunc: func(ctx context.Context, id int64) (*models.Sequence, error) {
 //				panic("mock out the GetSequence method")
 //			},
-//			UpdateTrackingFunc: func(ctx context.Context, id int64, openTracking bool, clickTracking bool) error {
+//			ImportSequenceFunc: func(ctx context.Context, bundle *models.SequenceBundle, dryRun bool) (*models.ImportResult, error) {
+//				panic("mock out the ImportSequence method")
+//			},
+//			ListSequencesFunc: func(ctx context.Context, opts models.ListOptions) ([]*models.Sequence, string, error) {
+//				panic("mock out the ListSequences method")
+//			},
+//			PatchSequenceFunc: func(ctx context.Context, id int64, patch []byte, version int64) (*models.Sequence, error) {
+//				panic("mock out the PatchSequence method")
+//			},
+//			SetSequenceActiveFunc: func(ctx context.Context, id int64, active bool, version int64) (int64, error) {
+//				panic("mock out the SetSequenceActive method")
+//			},
+//			UpdateTrackingFunc: func(ctx context.Context, id int64, openTracking bool, clickTracking bool, version int64) (int64, error) {
 //				panic("mock out the UpdateTracking method")
 //			},
 //		}
@@ -39,11 +60,32 @@ type SequenceServiceMock struct {
 	// CreateSequenceFunc mocks the CreateSequence method.
 	CreateSequenceFunc func(ctx context.Context, sequence *models.Sequence) (int64, error)
 
+	// DeleteSequenceFunc mocks the DeleteSequence method.
+	DeleteSequenceFunc func(ctx context.Context, id int64, version int64) error
+
+	// ExportSequenceFunc mocks the ExportSequence method.
+	ExportSequenceFunc func(ctx context.Context, id int64) (*models.SequenceBundle, error)
+
+	// ForecastSequenceFunc mocks the ForecastSequence method.
+	ForecastSequenceFunc func(ctx context.Context, id int64, req *models.ForecastRequest) (*models.Forecast, error)
+
 	// GetSequenceFunc mocks the GetSequence method.
 	GetSequenceFunc func(ctx context.Context, id int64) (*models.Sequence, error)
 
+	// ImportSequenceFunc mocks the ImportSequence method.
+	ImportSequenceFunc func(ctx context.Context, bundle *models.SequenceBundle, dryRun bool) (*models.ImportResult, error)
+
+	// ListSequencesFunc mocks the ListSequences method.
+	ListSequencesFunc func(ctx context.Context, opts models.ListOptions) ([]*models.Sequence, string, error)
+
+	// PatchSequenceFunc mocks the PatchSequence method.
+	PatchSequenceFunc func(ctx context.Context, id int64, patch []byte, version int64) (*models.Sequence, error)
+
+	// SetSequenceActiveFunc mocks the SetSequenceActive method.
+	SetSequenceActiveFunc func(ctx context.Context, id int64, active bool, version int64) (int64, error)
+
 	// UpdateTrackingFunc mocks the UpdateTracking method.
-	UpdateTrackingFunc func(ctx context.Context, id int64, openTracking bool, clickTracking bool) error
+	UpdateTrackingFunc func(ctx context.Context, id int64, openTracking bool, clickTracking bool, version int64) (int64, error)
 
 	// calls tracks calls to the methods.
 	calls struct {
@@ -54,6 +96,31 @@ type SequenceServiceMock struct {
 			// Sequence is the sequence argument value.
 			Sequence *models.Sequence
 		}
+		// DeleteSequence holds details about calls to the DeleteSequence method.
+		DeleteSequence []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+			// Version is the version argument value.
+			Version int64
+		}
+		// ExportSequence holds details about calls to the ExportSequence method.
+		ExportSequence []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+		}
+		// ForecastSequence holds details about calls to the ForecastSequence method.
+		ForecastSequence []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+			// Req is the req argument value.
+			Req *models.ForecastRequest
+		}
 		// GetSequence holds details about calls to the GetSequence method.
 		GetSequence []struct {
 			// Ctx is the ctx argument value.
@@ -61,6 +128,44 @@ type SequenceServiceMock struct {
 			// ID is the id argument value.
 			ID int64
 		}
+		// ImportSequence holds details about calls to the ImportSequence method.
+		ImportSequence []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Bundle is the bundle argument value.
+			Bundle *models.SequenceBundle
+			// DryRun is the dryRun argument value.
+			DryRun bool
+		}
+		// ListSequences holds details about calls to the ListSequences method.
+		ListSequences []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Opts is the opts argument value.
+			Opts models.ListOptions
+		}
+		// PatchSequence holds details about calls to the PatchSequence method.
+		PatchSequence []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+			// Patch is the patch argument value.
+			Patch []byte
+			// Version is the version argument value.
+			Version int64
+		}
+		// SetSequenceActive holds details about calls to the SetSequenceActive method.
+		SetSequenceActive []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+			// Active is the active argument value.
+			Active bool
+			// Version is the version argument value.
+			Version int64
+		}
 		// UpdateTracking holds details about calls to the UpdateTracking method.
 		UpdateTracking []struct {
 			// Ctx is the ctx argument value.
@@ -71,11 +176,20 @@ type SequenceServiceMock struct {
 			OpenTracking bool
 			// ClickTracking is the clickTracking argument value.
 			ClickTracking bool
+			// Version is the version argument value.
+			Version int64
 		}
 	}
-	lockCreateSequence sync.RWMutex
-	lockGetSequence    sync.RWMutex
-	lockUpdateTracking sync.RWMutex
+	lockCreateSequence    sync.RWMutex
+	lockDeleteSequence    sync.RWMutex
+	lockExportSequence    sync.RWMutex
+	lockForecastSequence  sync.RWMutex
+	lockGetSequence       sync.RWMutex
+	lockImportSequence    sync.RWMutex
+	lockListSequences     sync.RWMutex
+	lockPatchSequence     sync.RWMutex
+	lockSetSequenceActive sync.RWMutex
+	lockUpdateTracking    sync.RWMutex
 }
 
 // CreateSequence calls CreateSequenceFunc.
@@ -114,6 +228,122 @@ func (mock *SequenceServiceMock) CreateSequenceCalls() []struct {
 	return calls
 }
 
+// DeleteSequence calls DeleteSequenceFunc.
+func (mock *SequenceServiceMock) DeleteSequence(ctx context.Context, id int64, version int64) error {
+	if mock.DeleteSequenceFunc == nil {
+		panic("SequenceServiceMock.DeleteSequenceFunc: method is nil but SequenceService.DeleteSequence was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		ID      int64
+		Version int64
+	}{
+		Ctx:     ctx,
+		ID:      id,
+		Version: version,
+	}
+	mock.lockDeleteSequence.Lock()
+	mock.calls.DeleteSequence = append(mock.calls.DeleteSequence, callInfo)
+	mock.lockDeleteSequence.Unlock()
+	return mock.DeleteSequenceFunc(ctx, id, version)
+}
+
+// DeleteSequenceCalls gets all the calls that were made to DeleteSequence.
+// Check the length with:
+//
+//	len(mockedSequenceService.DeleteSequenceCalls())
+func (mock *SequenceServiceMock) DeleteSequenceCalls() []struct {
+	Ctx     context.Context
+	ID      int64
+	Version int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		ID      int64
+		Version int64
+	}
+	mock.lockDeleteSequence.RLock()
+	calls = mock.calls.DeleteSequence
+	mock.lockDeleteSequence.RUnlock()
+	return calls
+}
+
+// ExportSequence calls ExportSequenceFunc.
+func (mock *SequenceServiceMock) ExportSequence(ctx context.Context, id int64) (*models.SequenceBundle, error) {
+	if mock.ExportSequenceFunc == nil {
+		panic("SequenceServiceMock.ExportSequenceFunc: method is nil but SequenceService.ExportSequence was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  int64
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockExportSequence.Lock()
+	mock.calls.ExportSequence = append(mock.calls.ExportSequence, callInfo)
+	mock.lockExportSequence.Unlock()
+	return mock.ExportSequenceFunc(ctx, id)
+}
+
+// ExportSequenceCalls gets all the calls that were made to ExportSequence.
+// Check the length with:
+//
+//	len(mockedSequenceService.ExportSequenceCalls())
+func (mock *SequenceServiceMock) ExportSequenceCalls() []struct {
+	Ctx context.Context
+	ID  int64
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  int64
+	}
+	mock.lockExportSequence.RLock()
+	calls = mock.calls.ExportSequence
+	mock.lockExportSequence.RUnlock()
+	return calls
+}
+
+// ForecastSequence calls ForecastSequenceFunc.
+func (mock *SequenceServiceMock) ForecastSequence(ctx context.Context, id int64, req *models.ForecastRequest) (*models.Forecast, error) {
+	if mock.ForecastSequenceFunc == nil {
+		panic("SequenceServiceMock.ForecastSequenceFunc: method is nil but SequenceService.ForecastSequence was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  int64
+		Req *models.ForecastRequest
+	}{
+		Ctx: ctx,
+		ID:  id,
+		Req: req,
+	}
+	mock.lockForecastSequence.Lock()
+	mock.calls.ForecastSequence = append(mock.calls.ForecastSequence, callInfo)
+	mock.lockForecastSequence.Unlock()
+	return mock.ForecastSequenceFunc(ctx, id, req)
+}
+
+// ForecastSequenceCalls gets all the calls that were made to ForecastSequence.
+// Check the length with:
+//
+//	len(mockedSequenceService.ForecastSequenceCalls())
+func (mock *SequenceServiceMock) ForecastSequenceCalls() []struct {
+	Ctx context.Context
+	ID  int64
+	Req *models.ForecastRequest
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  int64
+		Req *models.ForecastRequest
+	}
+	mock.lockForecastSequence.RLock()
+	calls = mock.calls.ForecastSequence
+	mock.lockForecastSequence.RUnlock()
+	return calls
+}
+
 // GetSequence calls GetSequenceFunc.
 func (mock *SequenceServiceMock) GetSequence(ctx context.Context, id int64) (*models.Sequence, error) {
 	if mock.GetSequenceFunc == nil {
@@ -150,8 +380,172 @@ func (mock *SequenceServiceMock) GetSequenceCalls() []struct {
 	return calls
 }
 
+// ImportSequence calls ImportSequenceFunc.
+func (mock *SequenceServiceMock) ImportSequence(ctx context.Context, bundle *models.SequenceBundle, dryRun bool) (*models.ImportResult, error) {
+	if mock.ImportSequenceFunc == nil {
+		panic("SequenceServiceMock.ImportSequenceFunc: method is nil but SequenceService.ImportSequence was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Bundle *models.SequenceBundle
+		DryRun bool
+	}{
+		Ctx:    ctx,
+		Bundle: bundle,
+		DryRun: dryRun,
+	}
+	mock.lockImportSequence.Lock()
+	mock.calls.ImportSequence = append(mock.calls.ImportSequence, callInfo)
+	mock.lockImportSequence.Unlock()
+	return mock.ImportSequenceFunc(ctx, bundle, dryRun)
+}
+
+// ImportSequenceCalls gets all the calls that were made to ImportSequence.
+// Check the length with:
+//
+//	len(mockedSequenceService.ImportSequenceCalls())
+func (mock *SequenceServiceMock) ImportSequenceCalls() []struct {
+	Ctx    context.Context
+	Bundle *models.SequenceBundle
+	DryRun bool
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Bundle *models.SequenceBundle
+		DryRun bool
+	}
+	mock.lockImportSequence.RLock()
+	calls = mock.calls.ImportSequence
+	mock.lockImportSequence.RUnlock()
+	return calls
+}
+
+// ListSequences calls ListSequencesFunc.
+func (mock *SequenceServiceMock) ListSequences(ctx context.Context, opts models.ListOptions) ([]*models.Sequence, string, error) {
+	if mock.ListSequencesFunc == nil {
+		panic("SequenceServiceMock.ListSequencesFunc: method is nil but SequenceService.ListSequences was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Opts models.ListOptions
+	}{
+		Ctx:  ctx,
+		Opts: opts,
+	}
+	mock.lockListSequences.Lock()
+	mock.calls.ListSequences = append(mock.calls.ListSequences, callInfo)
+	mock.lockListSequences.Unlock()
+	return mock.ListSequencesFunc(ctx, opts)
+}
+
+// ListSequencesCalls gets all the calls that were made to ListSequences.
+// Check the length with:
+//
+//	len(mockedSequenceService.ListSequencesCalls())
+func (mock *SequenceServiceMock) ListSequencesCalls() []struct {
+	Ctx  context.Context
+	Opts models.ListOptions
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Opts models.ListOptions
+	}
+	mock.lockListSequences.RLock()
+	calls = mock.calls.ListSequences
+	mock.lockListSequences.RUnlock()
+	return calls
+}
+
+// PatchSequence calls PatchSequenceFunc.
+func (mock *SequenceServiceMock) PatchSequence(ctx context.Context, id int64, patch []byte, version int64) (*models.Sequence, error) {
+	if mock.PatchSequenceFunc == nil {
+		panic("SequenceServiceMock.PatchSequenceFunc: method is nil but SequenceService.PatchSequence was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		ID      int64
+		Patch   []byte
+		Version int64
+	}{
+		Ctx:     ctx,
+		ID:      id,
+		Patch:   patch,
+		Version: version,
+	}
+	mock.lockPatchSequence.Lock()
+	mock.calls.PatchSequence = append(mock.calls.PatchSequence, callInfo)
+	mock.lockPatchSequence.Unlock()
+	return mock.PatchSequenceFunc(ctx, id, patch, version)
+}
+
+// PatchSequenceCalls gets all the calls that were made to PatchSequence.
+// Check the length with:
+//
+//	len(mockedSequenceService.PatchSequenceCalls())
+func (mock *SequenceServiceMock) PatchSequenceCalls() []struct {
+	Ctx     context.Context
+	ID      int64
+	Patch   []byte
+	Version int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		ID      int64
+		Patch   []byte
+		Version int64
+	}
+	mock.lockPatchSequence.RLock()
+	calls = mock.calls.PatchSequence
+	mock.lockPatchSequence.RUnlock()
+	return calls
+}
+
+// SetSequenceActive calls SetSequenceActiveFunc.
+func (mock *SequenceServiceMock) SetSequenceActive(ctx context.Context, id int64, active bool, version int64) (int64, error) {
+	if mock.SetSequenceActiveFunc == nil {
+		panic("SequenceServiceMock.SetSequenceActiveFunc: method is nil but SequenceService.SetSequenceActive was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		ID      int64
+		Active  bool
+		Version int64
+	}{
+		Ctx:     ctx,
+		ID:      id,
+		Active:  active,
+		Version: version,
+	}
+	mock.lockSetSequenceActive.Lock()
+	mock.calls.SetSequenceActive = append(mock.calls.SetSequenceActive, callInfo)
+	mock.lockSetSequenceActive.Unlock()
+	return mock.SetSequenceActiveFunc(ctx, id, active, version)
+}
+
+// SetSequenceActiveCalls gets all the calls that were made to SetSequenceActive.
+// Check the length with:
+//
+//	len(mockedSequenceService.SetSequenceActiveCalls())
+func (mock *SequenceServiceMock) SetSequenceActiveCalls() []struct {
+	Ctx     context.Context
+	ID      int64
+	Active  bool
+	Version int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		ID      int64
+		Active  bool
+		Version int64
+	}
+	mock.lockSetSequenceActive.RLock()
+	calls = mock.calls.SetSequenceActive
+	mock.lockSetSequenceActive.RUnlock()
+	return calls
+}
+
 // UpdateTracking calls UpdateTrackingFunc.
-func (mock *SequenceServiceMock) UpdateTracking(ctx context.Context, id int64, openTracking bool, clickTracking bool) error {
+func (mock *SequenceServiceMock) UpdateTracking(ctx context.Context, id int64, openTracking bool, clickTracking bool, version int64) (int64, error) {
 	if mock.UpdateTrackingFunc == nil {
 		panic("SequenceServiceMock.UpdateTrackingFunc: method is nil but SequenceService.UpdateTracking was just called")
 	}
@@ -160,16 +554,18 @@ func (mock *SequenceServiceMock) UpdateTracking(ctx context.Context, id int64, o
 		ID            int64
 		OpenTracking  bool
 		ClickTracking bool
+		Version       int64
 	}{
 		Ctx:           ctx,
 		ID:            id,
 		OpenTracking:  openTracking,
 		ClickTracking: clickTracking,
+		Version:       version,
 	}
 	mock.lockUpdateTracking.Lock()
 	mock.calls.UpdateTracking = append(mock.calls.UpdateTracking, callInfo)
 	mock.lockUpdateTracking.Unlock()
-	return mock.UpdateTrackingFunc(ctx, id, openTracking, clickTracking)
+	return mock.UpdateTrackingFunc(ctx, id, openTracking, clickTracking, version)
 }
 
 // UpdateTrackingCalls gets all the calls that were made to UpdateTracking.
@@ -181,12 +577,14 @@ func (mock *SequenceServiceMock) UpdateTrackingCalls() []struct {
 	ID            int64
 	OpenTracking  bool
 	ClickTracking bool
+	Version       int64
 } {
 	var calls []struct {
 		Ctx           context.Context
 		ID            int64
 		OpenTracking  bool
 		ClickTracking bool
+		Version       int64
 	}
 	mock.lockUpdateTracking.RLock()
 	calls = mock.calls.UpdateTracking
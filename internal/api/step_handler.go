@@ -22,13 +22,13 @@ func NewStepHandler(service core.StepService) *StepHandler {
 func (h *StepHandler) CreateStep(w http.ResponseWriter, r *http.Request) {
 	var step models.Step
 	if err := json.NewDecoder(r.Body).Decode(&step); err != nil {
-		WriteResponse(w, http.StatusBadRequest, ErrorResponse(err.Error(), "Invalid request body"))
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
 		return
 	}
 
 	id, err := h.stepService.CreateStep(r.Context(), &step)
 	if err != nil {
-		WriteResponse(w, http.StatusInternalServerError, ErrorResponse(err.Error(), "Failed to create step"))
+		WriteError(w, r, err, "Failed to create step")
 		return
 	}
 
@@ -40,37 +40,77 @@ func (h *StepHandler) UpdateStep(w http.ResponseWriter, r *http.Request) {
 	idStr := vars["id"]
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil || id <= 0 {
-		WriteResponse(w, http.StatusBadRequest, ErrorResponse("Invalid ID", "Invalid query parameter"))
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid ID")
 		return
 	}
 	var step models.Step
 	if err := json.NewDecoder(r.Body).Decode(&step); err != nil {
-		WriteResponse(w, http.StatusBadRequest, ErrorResponse(err.Error(), "Invalid request body"))
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
 		return
 	}
 	step.ID = id
+	// The expected version comes only from If-Match, never from the body.
+	step.Version, err = ifMatchVersion(r)
+	if err != nil {
+		WriteError(w, r, err, "Failed to update step")
+		return
+	}
 
 	err = h.stepService.UpdateStep(r.Context(), &step)
 	if err != nil {
-		WriteResponse(w, http.StatusInternalServerError, ErrorResponse(err.Error(), "Failed to update step"))
+		WriteError(w, r, err, "Failed to update step")
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(step.Version))
 	WriteResponse(w, http.StatusOK, SuccessResponse(nil, "Step updated successfully"))
 }
 
+func (h *StepHandler) PatchStep(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid ID")
+		return
+	}
+	patch, ok := readMergePatch(w, r)
+	if !ok {
+		return
+	}
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		WriteError(w, r, err, "Failed to update step")
+		return
+	}
+
+	step, err := h.stepService.PatchStep(r.Context(), id, patch, version)
+	if err != nil {
+		WriteError(w, r, err, "Failed to update step")
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(step.Version))
+	WriteResponse(w, http.StatusOK, SuccessResponse(step, "Step updated successfully"))
+}
+
 func (h *StepHandler) DeleteStep(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil || id <= 0 {
-		WriteResponse(w, http.StatusBadRequest, ErrorResponse("Invalid ID", "Invalid query parameter"))
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid ID")
 		return
 	}
 
-	err = h.stepService.DeleteStep(r.Context(), id)
+	version, err := ifMatchVersion(r)
 	if err != nil {
-		WriteResponse(w, http.StatusInternalServerError, ErrorResponse(err.Error(), "Failed to delete step"))
+		WriteError(w, r, err, "Failed to delete step")
+		return
+	}
+	err = h.stepService.DeleteStep(r.Context(), id, version)
+	if err != nil {
+		WriteError(w, r, err, "Failed to delete step")
 		return
 	}
 
@@ -81,15 +121,24 @@ func (h *StepHandler) ListSteps(w http.ResponseWriter, r *http.Request) {
 	sequenceIDStr := r.URL.Query().Get("sequenceId")
 	sequenceID, err := strconv.ParseInt(sequenceIDStr, 10, 64)
 	if err != nil || sequenceID <= 0 {
-		WriteResponse(w, http.StatusBadRequest, ErrorResponse("Invalid sequenceId", "Invalid query parameter"))
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid sequenceId")
 		return
 	}
 
-	steps, err := h.stepService.ListSteps(r.Context(), sequenceID)
+	opts, err := parseListOptions(r)
 	if err != nil {
-		WriteResponse(w, http.StatusInternalServerError, ErrorResponse(err.Error(), "Failed to fetch steps"))
+		WriteBadRequest(w, r, "Invalid query parameter", err.Error())
+		return
+	}
+
+	steps, next, err := h.stepService.ListSteps(r.Context(), sequenceID, opts)
+	if err != nil {
+		WriteError(w, r, err, "Failed to fetch steps")
+		return
+	}
+	if notModified(w, r, stepsETag(steps, next)) {
 		return
 	}
 
-	WriteResponse(w, http.StatusOK, SuccessResponse(steps, "Steps fetched successfully"))
+	WriteResponse(w, http.StatusOK, PageResponse(steps, opts, next, "Steps fetched successfully"))
 }
@@ -5,9 +5,8 @@ package api
 
 import (
 	"context"
-	"sf_test/internal/models"
 	"sf_test/internal/core"
-
+	"sf_test/internal/models"
 	"sync"
 )
 
@@ -24,12 +23,15 @@ var _ core.StepService = &StepServiceMock{}
 //			CreateStepFunc: func(ctx context.Context, step *models.Step) (int64, error) {
 //				panic("mock out the CreateStep method")
 //			},
-//			DeleteStepFunc: func(ctx context.Context, id int64) error {
+//			DeleteStepFunc: func(ctx context.Context, id int64, version int64) error {
 //				panic("mock out the DeleteStep method")
 //			},
-//			ListStepsFunc: func(ctx context.Context, sequenceID int64) ([]*models.Step, error) {
+//			ListStepsFunc: func(ctx context.Context, sequenceID int64, opts models.ListOptions) ([]*models.Step, string, error) {
 //				panic("mock out the ListSteps method")
 //			},
+//			PatchStepFunc: func(ctx context.Context, id int64, patch []byte, version int64) (*models.Step, error) {
+//				panic("mock out the PatchStep method")
+//			},
 //			UpdateStepFunc: func(ctx context.Context, step *models.Step) error {
 //				panic("mock out the UpdateStep method")
 //			},
@@ -44,10 +46,13 @@ type StepServiceMock struct {
 	CreateStepFunc func(ctx context.Context, step *models.Step) (int64, error)
 
 	// DeleteStepFunc mocks the DeleteStep method.
-	DeleteStepFunc func(ctx context.Context, id int64) error
+	DeleteStepFunc func(ctx context.Context, id int64, version int64) error
 
 	// ListStepsFunc mocks the ListSteps method.
-	ListStepsFunc func(ctx context.Context, sequenceID int64) ([]*models.Step, error)
+	ListStepsFunc func(ctx context.Context, sequenceID int64, opts models.ListOptions) ([]*models.Step, string, error)
+
+	// PatchStepFunc mocks the PatchStep method.
+	PatchStepFunc func(ctx context.Context, id int64, patch []byte, version int64) (*models.Step, error)
 
 	// UpdateStepFunc mocks the UpdateStep method.
 	UpdateStepFunc func(ctx context.Context, step *models.Step) error
@@ -67,6 +72,8 @@ type StepServiceMock struct {
 			Ctx context.Context
 			// ID is the id argument value.
 			ID int64
+			// Version is the version argument value.
+			Version int64
 		}
 		// ListSteps holds details about calls to the ListSteps method.
 		ListSteps []struct {
@@ -74,6 +81,19 @@ type StepServiceMock struct {
 			Ctx context.Context
 			// SequenceID is the sequenceID argument value.
 			SequenceID int64
+			// Opts is the opts argument value.
+			Opts models.ListOptions
+		}
+		// PatchStep holds details about calls to the PatchStep method.
+		PatchStep []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+			// Patch is the patch argument value.
+			Patch []byte
+			// Version is the version argument value.
+			Version int64
 		}
 		// UpdateStep holds details about calls to the UpdateStep method.
 		UpdateStep []struct {
@@ -86,6 +106,7 @@ type StepServiceMock struct {
 	lockCreateStep sync.RWMutex
 	lockDeleteStep sync.RWMutex
 	lockListSteps  sync.RWMutex
+	lockPatchStep  sync.RWMutex
 	lockUpdateStep sync.RWMutex
 }
 
@@ -126,21 +147,23 @@ func (mock *StepServiceMock) CreateStepCalls() []struct {
 }
 
 // DeleteStep calls DeleteStepFunc.
-func (mock *StepServiceMock) DeleteStep(ctx context.Context, id int64) error {
+func (mock *StepServiceMock) DeleteStep(ctx context.Context, id int64, version int64) error {
 	if mock.DeleteStepFunc == nil {
 		panic("StepServiceMock.DeleteStepFunc: method is nil but StepService.DeleteStep was just called")
 	}
 	callInfo := struct {
-		Ctx context.Context
-		ID  int64
+		Ctx     context.Context
+		ID      int64
+		Version int64
 	}{
-		Ctx: ctx,
-		ID:  id,
+		Ctx:     ctx,
+		ID:      id,
+		Version: version,
 	}
 	mock.lockDeleteStep.Lock()
 	mock.calls.DeleteStep = append(mock.calls.DeleteStep, callInfo)
 	mock.lockDeleteStep.Unlock()
-	return mock.DeleteStepFunc(ctx, id)
+	return mock.DeleteStepFunc(ctx, id, version)
 }
 
 // DeleteStepCalls gets all the calls that were made to DeleteStep.
@@ -148,12 +171,14 @@ func (mock *StepServiceMock) DeleteStep(ctx context.Context, id int64) error {
 //
 //	len(mockedStepService.DeleteStepCalls())
 func (mock *StepServiceMock) DeleteStepCalls() []struct {
-	Ctx context.Context
-	ID  int64
+	Ctx     context.Context
+	ID      int64
+	Version int64
 } {
 	var calls []struct {
-		Ctx context.Context
-		ID  int64
+		Ctx     context.Context
+		ID      int64
+		Version int64
 	}
 	mock.lockDeleteStep.RLock()
 	calls = mock.calls.DeleteStep
@@ -162,21 +187,23 @@ func (mock *StepServiceMock) DeleteStepCalls() []struct {
 }
 
 // ListSteps calls ListStepsFunc.
-func (mock *StepServiceMock) ListSteps(ctx context.Context, sequenceID int64) ([]*models.Step, error) {
+func (mock *StepServiceMock) ListSteps(ctx context.Context, sequenceID int64, opts models.ListOptions) ([]*models.Step, string, error) {
 	if mock.ListStepsFunc == nil {
 		panic("StepServiceMock.ListStepsFunc: method is nil but StepService.ListSteps was just called")
 	}
 	callInfo := struct {
 		Ctx        context.Context
 		SequenceID int64
+		Opts       models.ListOptions
 	}{
 		Ctx:        ctx,
 		SequenceID: sequenceID,
+		Opts:       opts,
 	}
 	mock.lockListSteps.Lock()
 	mock.calls.ListSteps = append(mock.calls.ListSteps, callInfo)
 	mock.lockListSteps.Unlock()
-	return mock.ListStepsFunc(ctx, sequenceID)
+	return mock.ListStepsFunc(ctx, sequenceID, opts)
 }
 
 // ListStepsCalls gets all the calls that were made to ListSteps.
@@ -186,10 +213,12 @@ func (mock *StepServiceMock) ListSteps(ctx context.Context, sequenceID int64) ([
 func (mock *StepServiceMock) ListStepsCalls() []struct {
 	Ctx        context.Context
 	SequenceID int64
+	Opts       models.ListOptions
 } {
 	var calls []struct {
 		Ctx        context.Context
 		SequenceID int64
+		Opts       models.ListOptions
 	}
 	mock.lockListSteps.RLock()
 	calls = mock.calls.ListSteps
@@ -197,6 +226,50 @@ func (mock *StepServiceMock) ListStepsCalls() []struct {
 	return calls
 }
 
+// PatchStep calls PatchStepFunc.
+func (mock *StepServiceMock) PatchStep(ctx context.Context, id int64, patch []byte, version int64) (*models.Step, error) {
+	if mock.PatchStepFunc == nil {
+		panic("StepServiceMock.PatchStepFunc: method is nil but StepService.PatchStep was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		ID      int64
+		Patch   []byte
+		Version int64
+	}{
+		Ctx:     ctx,
+		ID:      id,
+		Patch:   patch,
+		Version: version,
+	}
+	mock.lockPatchStep.Lock()
+	mock.calls.PatchStep = append(mock.calls.PatchStep, callInfo)
+	mock.lockPatchStep.Unlock()
+	return mock.PatchStepFunc(ctx, id, patch, version)
+}
+
+// PatchStepCalls gets all the calls that were made to PatchStep.
+// Check the length with:
+//
+//	len(mockedStepService.PatchStepCalls())
+func (mock *StepServiceMock) PatchStepCalls() []struct {
+	Ctx     context.Context
+	ID      int64
+	Patch   []byte
+	Version int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		ID      int64
+		Patch   []byte
+		Version int64
+	}
+	mock.lockPatchStep.RLock()
+	calls = mock.calls.PatchStep
+	mock.lockPatchStep.RUnlock()
+	return calls
+}
+
 // UpdateStep calls UpdateStepFunc.
 func (mock *StepServiceMock) UpdateStep(ctx context.Context, step *models.Step) error {
 	if mock.UpdateStepFunc == nil {
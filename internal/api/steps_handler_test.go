@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"sf_test/internal/core"
 	"sf_test/internal/models"
 
 	"github.com/gorilla/mux"
@@ -19,6 +20,7 @@ func setupStepRouter(handler *StepHandler) *mux.Router {
 	router := mux.NewRouter()
 	router.HandleFunc("/api/v1/steps", handler.CreateStep).Methods(http.MethodPost)
 	router.HandleFunc("/api/v1/steps/{id}", handler.UpdateStep).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/steps/{id}", handler.PatchStep).Methods(http.MethodPatch)
 	router.HandleFunc("/api/v1/steps/{id}", handler.DeleteStep).Methods(http.MethodDelete)
 	router.HandleFunc("/api/v1/steps", handler.ListSteps).Methods(http.MethodGet)
 	return router
@@ -61,7 +63,7 @@ func TestCreateStep_InvalidBody(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 	var response map[string]interface{}
 	_ = json.NewDecoder(rec.Body).Decode(&response)
-	assert.Equal(t, "Invalid request body", response["message"])
+	assert.Equal(t, "Invalid request body", response["title"])
 }
 
 func TestUpdateStep_Success(t *testing.T) {
@@ -106,8 +108,69 @@ func TestCreateStep_ServiceError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	var response map[string]interface{}
 	_ = json.NewDecoder(rec.Body).Decode(&response)
-	assert.Equal(t, "Failed to create step", response["message"])
-	assert.Equal(t, "service error", response["errors"])
+	assert.Equal(t, "Failed to create step", response["title"])
+	assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+}
+
+func TestUpdateStep_IfMatch(t *testing.T) {
+	var expected int64
+	mockService := &StepServiceMock{
+		UpdateStepFunc: func(ctx context.Context, step *models.Step) error {
+			expected = step.Version
+			step.Version++
+			return nil
+		},
+	}
+	handler := NewStepHandler(mockService)
+	router := setupStepRouter(handler)
+
+	// A version in the body is ignored in favour of If-Match.
+	body, _ := json.Marshal(models.Step{Subject: "Updated Step", Version: 9})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/steps/1", bytes.NewReader(body))
+	req.Header.Set("If-Match", `"2"`)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int64(2), expected)
+	assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+}
+
+func TestUpdateStep_StaleVersion(t *testing.T) {
+	mockService := &StepServiceMock{
+		UpdateStepFunc: func(ctx context.Context, step *models.Step) error {
+			return &core.PreconditionFailedError{Message: "step 1 has been modified since version was read"}
+		},
+	}
+	handler := NewStepHandler(mockService)
+	router := setupStepRouter(handler)
+
+	body, _ := json.Marshal(models.Step{Subject: "Updated Step"})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/steps/1", bytes.NewReader(body))
+	req.Header.Set("If-Match", `"2"`)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+}
+
+func TestUpdateStep_WeakIfMatch(t *testing.T) {
+	mockService := &StepServiceMock{}
+	handler := NewStepHandler(mockService)
+	router := setupStepRouter(handler)
+
+	body, _ := json.Marshal(models.Step{Subject: "Updated Step"})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/steps/1", bytes.NewReader(body))
+	req.Header.Set("If-Match", `W/"2"`)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	assert.Len(t, mockService.UpdateStepCalls(), 0)
 }
 
 func TestUpdateStep_InvalidID(t *testing.T) {
@@ -123,12 +186,78 @@ func TestUpdateStep_InvalidID(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 	var response map[string]interface{}
 	_ = json.NewDecoder(rec.Body).Decode(&response)
-	assert.Equal(t, "Invalid query parameter", response["message"])
+	assert.Equal(t, "Invalid query parameter", response["title"])
+}
+
+func TestPatchStep_Success(t *testing.T) {
+	mockService := &StepServiceMock{
+		PatchStepFunc: func(ctx context.Context, id int64, patch []byte, version int64) (*models.Step, error) {
+			return &models.Step{ID: id, Subject: "Patched", Version: 4}, nil
+		},
+	}
+	handler := NewStepHandler(mockService)
+	router := setupStepRouter(handler)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/steps/1", bytes.NewReader([]byte(`{"subject":"Patched"}`)))
+	req.Header.Set("Content-Type", MergePatchContentType)
+	req.Header.Set("If-Match", `"3"`)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+	call := mockService.PatchStepCalls()[0]
+	assert.Equal(t, int64(1), call.ID)
+	assert.Equal(t, int64(3), call.Version)
+	assert.JSONEq(t, `{"subject":"Patched"}`, string(call.Patch))
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	assert.Equal(t, "Patched", response["data"].(map[string]interface{})["subject"])
+}
+
+func TestPatchStep_UnsupportedMediaType(t *testing.T) {
+	mockService := &StepServiceMock{}
+	handler := NewStepHandler(mockService)
+	router := setupStepRouter(handler)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/steps/1", bytes.NewReader([]byte(`{"subject":"Patched"}`)))
+	req.Header.Set("Content-Type", "text/plain")
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	assert.Len(t, mockService.PatchStepCalls(), 0)
+}
+
+func TestPatchStep_ValidationError(t *testing.T) {
+	mockService := &StepServiceMock{
+		PatchStepFunc: func(ctx context.Context, id int64, patch []byte, version int64) (*models.Step, error) {
+			return nil, &core.ValidationError{
+				Message: "validation failed",
+				Fields:  []core.FieldError{{Field: "Step.Subject", Rule: "required", Message: "Subject is required"}},
+			}
+		},
+	}
+	handler := NewStepHandler(mockService)
+	router := setupStepRouter(handler)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/steps/1", bytes.NewReader([]byte(`{"subject":null}`)))
+	req.Header.Set("Content-Type", MergePatchContentType)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	assert.Len(t, response["errors"], 1)
 }
 
 func TestDeleteStep_Success(t *testing.T) {
 	mockService := &StepServiceMock{
-		DeleteStepFunc: func(ctx context.Context, id int64) error {
+		DeleteStepFunc: func(ctx context.Context, id int64, version int64) error {
 			return nil
 		},
 	}
@@ -146,6 +275,25 @@ func TestDeleteStep_Success(t *testing.T) {
 	assert.Equal(t, "Step deleted successfully", response["message"])
 }
 
+func TestDeleteStep_IfMatch(t *testing.T) {
+	mockService := &StepServiceMock{
+		DeleteStepFunc: func(ctx context.Context, id int64, version int64) error {
+			return nil
+		},
+	}
+	handler := NewStepHandler(mockService)
+	router := setupStepRouter(handler)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/steps/1", nil)
+	req.Header.Set("If-Match", `"4"`)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int64(4), mockService.DeleteStepCalls()[0].Version)
+}
+
 func TestDeleteStep_InvalidID(t *testing.T) {
 	mockService := &StepServiceMock{}
 	handler := NewStepHandler(mockService)
@@ -159,13 +307,13 @@ func TestDeleteStep_InvalidID(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 	var response map[string]interface{}
 	_ = json.NewDecoder(rec.Body).Decode(&response)
-	assert.Equal(t, "Invalid query parameter", response["message"])
+	assert.Equal(t, "Invalid query parameter", response["title"])
 }
 
 func TestListSteps_Success(t *testing.T) {
 	mockService := &StepServiceMock{
-		ListStepsFunc: func(ctx context.Context, sequenceID int64) ([]*models.Step, error) {
-			return []*models.Step{{Subject: "Step 1"}, {Subject: "Step 2"}}, nil
+		ListStepsFunc: func(ctx context.Context, sequenceID int64, opts models.ListOptions) ([]*models.Step, string, error) {
+			return []*models.Step{{Subject: "Step 1"}, {Subject: "Step 2"}}, "", nil
 		},
 	}
 	handler := NewStepHandler(mockService)
@@ -183,6 +331,91 @@ func TestListSteps_Success(t *testing.T) {
 	assert.Len(t, response["data"].([]interface{}), 2)
 }
 
+func TestListSteps_NotModified(t *testing.T) {
+	mockService := &StepServiceMock{
+		ListStepsFunc: func(ctx context.Context, sequenceID int64, opts models.ListOptions) ([]*models.Step, string, error) {
+			return []*models.Step{{ID: 1, Version: 1}, {ID: 2, Version: 3}}, "", nil
+		},
+	}
+	handler := NewStepHandler(mockService)
+	router := setupStepRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/steps?sequenceId=1", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	etag := rec.Header().Get("ETag")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEmpty(t, etag)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/steps?sequenceId=1", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+}
+
+func TestListSteps_Pagination(t *testing.T) {
+	mockService := &StepServiceMock{
+		ListStepsFunc: func(ctx context.Context, sequenceID int64, opts models.ListOptions) ([]*models.Step, string, error) {
+			return []*models.Step{{Subject: "Step 3"}}, "next-page", nil
+		},
+	}
+	handler := NewStepHandler(mockService)
+	router := setupStepRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/steps?sequenceId=1&limit=1&cursor=abc&sort=-createdAt&name=webinar&createdAfter=2024-01-01T00:00:00Z", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	meta := response["meta"].(map[string]interface{})
+	assert.Equal(t, "next-page", meta["nextCursor"])
+	assert.Equal(t, float64(1), meta["limit"])
+
+	opts := mockService.ListStepsCalls()[0].Opts
+	assert.Equal(t, 1, opts.Limit)
+	assert.Equal(t, "abc", opts.Cursor)
+	assert.Equal(t, "-createdAt", opts.Sort)
+	assert.Equal(t, "webinar", opts.Filters.NameContains)
+	assert.NotNil(t, opts.Filters.CreatedAfter)
+}
+
+func TestListSteps_InvalidLimit(t *testing.T) {
+	mockService := &StepServiceMock{}
+	handler := NewStepHandler(mockService)
+	router := setupStepRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/steps?sequenceId=1&limit=1000", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Len(t, mockService.ListStepsCalls(), 0)
+}
+
+func TestListSteps_InvalidCursor(t *testing.T) {
+	mockService := &StepServiceMock{
+		ListStepsFunc: func(ctx context.Context, sequenceID int64, opts models.ListOptions) ([]*models.Step, string, error) {
+			return nil, "", &core.ValidationError{Message: "invalid cursor", Err: models.ErrInvalidCursor}
+		},
+	}
+	handler := NewStepHandler(mockService)
+	router := setupStepRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/steps?sequenceId=1&cursor=garbage", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
 func TestListSteps_InvalidSequenceID(t *testing.T) {
 	mockService := &StepServiceMock{}
 	handler := NewStepHandler(mockService)
@@ -196,7 +429,7 @@ func TestListSteps_InvalidSequenceID(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 	var response map[string]interface{}
 	_ = json.NewDecoder(rec.Body).Decode(&response)
-	assert.Equal(t, "Invalid query parameter", response["message"])
+	assert.Equal(t, "Invalid query parameter", response["title"])
 }
 
 func TestUpdateStep_InvalidRequestBody(t *testing.T) {
@@ -212,8 +445,8 @@ func TestUpdateStep_InvalidRequestBody(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 	var response map[string]interface{}
 	_ = json.NewDecoder(rec.Body).Decode(&response)
-	assert.Equal(t, "Invalid request body", response["message"])
-	assert.Contains(t, response["errors"], "invalid character")
+	assert.Equal(t, "Invalid request body", response["title"])
+	assert.Contains(t, response["detail"], "invalid character")
 }
 func TestUpdateStep_ServiceError(t *testing.T) {
 	mockService := &StepServiceMock{
@@ -235,13 +468,13 @@ func TestUpdateStep_ServiceError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	var response map[string]interface{}
 	_ = json.NewDecoder(rec.Body).Decode(&response)
-	assert.Equal(t, "Failed to update step", response["message"])
-	assert.Equal(t, "service error", response["errors"])
+	assert.Equal(t, "Failed to update step", response["title"])
+	assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
 }
 
 func TestDeleteStep_ServiceError(t *testing.T) {
 	mockService := &StepServiceMock{
-		DeleteStepFunc: func(ctx context.Context, id int64) error {
+		DeleteStepFunc: func(ctx context.Context, id int64, version int64) error {
 			return errors.New("service error")
 		},
 	}
@@ -256,6 +489,6 @@ func TestDeleteStep_ServiceError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	var response map[string]interface{}
 	_ = json.NewDecoder(rec.Body).Decode(&response)
-	assert.Equal(t, "Failed to delete step", response["message"])
-	assert.Equal(t, "service error", response["errors"])
+	assert.Equal(t, "Failed to delete step", response["title"])
+	assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
 }
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"sf_test/internal/core"
+	"sf_test/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+type TeamHandler struct {
+	teamService core.TeamService
+}
+
+func NewTeamHandler(service core.TeamService) *TeamHandler {
+	return &TeamHandler{teamService: service}
+}
+
+func (h *TeamHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var user models.User
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
+		return
+	}
+
+	if _, err := h.teamService.CreateUser(r.Context(), &user); err != nil {
+		WriteError(w, r, err, "Failed to create user")
+		return
+	}
+
+	WriteResponse(w, http.StatusCreated, SuccessResponse(user, "User created successfully"))
+}
+
+func (h *TeamHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.teamService.ListUsers(r.Context())
+	if err != nil {
+		WriteError(w, r, err, "Failed to fetch users")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(users, "Users fetched successfully"))
+}
+
+func (h *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	var team models.Team
+	if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
+		return
+	}
+
+	if _, err := h.teamService.CreateTeam(r.Context(), &team); err != nil {
+		WriteError(w, r, err, "Failed to create team")
+		return
+	}
+
+	WriteResponse(w, http.StatusCreated, SuccessResponse(team, "Team created successfully"))
+}
+
+func (h *TeamHandler) ListTeams(w http.ResponseWriter, r *http.Request) {
+	teams, err := h.teamService.ListTeams(r.Context())
+	if err != nil {
+		WriteError(w, r, err, "Failed to fetch teams")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(teams, "Teams fetched successfully"))
+}
+
+func (h *TeamHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	teamID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || teamID <= 0 {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid team ID")
+		return
+	}
+
+	members, err := h.teamService.ListMembers(r.Context(), teamID)
+	if err != nil {
+		WriteError(w, r, err, "Failed to fetch team members")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(members, "Team members fetched successfully"))
+}
+
+// SetMember adds the user to the team with the role in the request body, or
+// changes the role of an existing member.
+func (h *TeamHandler) SetMember(w http.ResponseWriter, r *http.Request) {
+	teamID, userID, ok := memberIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var payload struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		WriteBadRequest(w, r, "Invalid request body", err.Error())
+		return
+	}
+
+	member := &models.TeamMember{TeamID: teamID, UserID: userID, Role: payload.Role}
+	if err := h.teamService.SetMember(r.Context(), member); err != nil {
+		WriteError(w, r, err, "Failed to set team member")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(member, "Team member saved successfully"))
+}
+
+func (h *TeamHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	teamID, userID, ok := memberIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.teamService.RemoveMember(r.Context(), teamID, userID); err != nil {
+		WriteError(w, r, err, "Failed to remove team member")
+		return
+	}
+
+	WriteResponse(w, http.StatusOK, SuccessResponse(nil, "Team member removed successfully"))
+}
+
+// memberIDs parses the team and user IDs of a membership route, writing a
+// bad request response if either is invalid.
+func memberIDs(w http.ResponseWriter, r *http.Request) (teamID, userID int64, ok bool) {
+	vars := mux.Vars(r)
+	teamID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil || teamID <= 0 {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid team ID")
+		return 0, 0, false
+	}
+	userID, err = strconv.ParseInt(vars["userId"], 10, 64)
+	if err != nil || userID <= 0 {
+		WriteBadRequest(w, r, "Invalid query parameter", "Invalid user ID")
+		return 0, 0, false
+	}
+	return teamID, userID, true
+}
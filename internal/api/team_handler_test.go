@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sf_test/internal/core"
+	"sf_test/internal/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTeamRouter(handler *TeamHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/users", handler.CreateUser).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/teams", handler.CreateTeam).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/teams", handler.ListTeams).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/teams/{id}/members", handler.ListMembers).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/teams/{id}/members/{userId}", handler.SetMember).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/teams/{id}/members/{userId}", handler.RemoveMember).Methods(http.MethodDelete)
+	return router
+}
+
+func TestCreateUser_Success(t *testing.T) {
+	mockService := &TeamServiceMock{
+		CreateUserFunc: func(ctx context.Context, user *models.User) (int64, error) {
+			user.ID = 3
+			return 3, nil
+		},
+	}
+	router := setupTeamRouter(NewTeamHandler(mockService))
+
+	body := []byte(`{"subject":"42","email":"ada@example.com","name":"Ada"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "42", mockService.CreateUserCalls()[0].User.Subject)
+	var response map[string]interface{}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+	assert.Equal(t, float64(3), response["data"].(map[string]interface{})["id"])
+}
+
+func TestCreateTeam_InvalidBody(t *testing.T) {
+	mockService := &TeamServiceMock{}
+	router := setupTeamRouter(NewTeamHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/teams", bytes.NewReader([]byte(`{`)))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, mockService.CreateTeamCalls())
+}
+
+func TestSetMember_Success(t *testing.T) {
+	mockService := &TeamServiceMock{
+		SetMemberFunc: func(ctx context.Context, member *models.TeamMember) error {
+			return nil
+		},
+	}
+	router := setupTeamRouter(NewTeamHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/teams/2/members/3", bytes.NewReader([]byte(`{"role":"editor"}`)))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, &models.TeamMember{TeamID: 2, UserID: 3, Role: models.RoleEditor}, mockService.SetMemberCalls()[0].Member)
+}
+
+func TestSetMember_Forbidden(t *testing.T) {
+	mockService := &TeamServiceMock{
+		SetMemberFunc: func(ctx context.Context, member *models.TeamMember) error {
+			return &core.ForbiddenError{Message: "manage requires the owner role in team 2"}
+		},
+	}
+	router := setupTeamRouter(NewTeamHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/teams/2/members/3", bytes.NewReader([]byte(`{"role":"owner"}`)))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestRemoveMember_InvalidUserID(t *testing.T) {
+	mockService := &TeamServiceMock{}
+	router := setupTeamRouter(NewTeamHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/teams/2/members/abc", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, mockService.RemoveMemberCalls())
+}
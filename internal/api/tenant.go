@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+
+	"sf_test/internal/auth"
+	"sf_test/internal/core"
+	"sf_test/internal/tenant"
+)
+
+// TenantMiddleware binds the requests of authenticated principals to the
+// workspace they act in, so repositories only see that workspace's data.
+// It must run after AuthMiddleware.
+type TenantMiddleware struct {
+	workspaces core.WorkspaceService
+}
+
+func NewTenantMiddleware(workspaces core.WorkspaceService) *TenantMiddleware {
+	return &TenantMiddleware{workspaces: workspaces}
+}
+
+// Resolve is the middleware handler.
+func (m *TenantMiddleware) Resolve(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.PrincipalFrom(r.Context())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		workspaceID, err := m.workspaces.Resolve(r.Context(), principal)
+		if err != nil {
+			WriteError(w, r, err, "Failed to resolve workspace")
+			return
+		}
+		resolved := *principal
+		resolved.WorkspaceID = workspaceID
+		ctx := auth.WithPrincipal(r.Context(), &resolved)
+		next.ServeHTTP(w, r.WithContext(tenant.WithWorkspace(ctx, workspaceID)))
+	})
+}
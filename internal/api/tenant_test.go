@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sf_test/internal/auth"
+	"sf_test/internal/core"
+	"sf_test/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tenantTestHandler(mockService *WorkspaceServiceMock, workspaceID *int64) http.Handler {
+	return NewTenantMiddleware(mockService).Resolve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*workspaceID, _ = tenant.WorkspaceFrom(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func TestTenant_BindsWorkspace(t *testing.T) {
+	mockService := &WorkspaceServiceMock{
+		ResolveFunc: func(ctx context.Context, principal *auth.Principal) (int64, error) {
+			return 7, nil
+		},
+	}
+	var workspaceID int64
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sequences", nil)
+	req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: "user:42", Workspace: "acme"}))
+	rec := httptest.NewRecorder()
+
+	tenantTestHandler(mockService, &workspaceID).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, int64(7), workspaceID)
+	assert.Equal(t, "acme", mockService.ResolveCalls()[0].Principal.Workspace)
+}
+
+func TestTenant_UnknownWorkspace(t *testing.T) {
+	mockService := &WorkspaceServiceMock{
+		ResolveFunc: func(ctx context.Context, principal *auth.Principal) (int64, error) {
+			return 0, &core.ForbiddenError{Message: `unknown workspace "globex"`}
+		},
+	}
+	var workspaceID int64
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sequences", nil)
+	req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: "user:42", Workspace: "globex"}))
+	rec := httptest.NewRecorder()
+
+	tenantTestHandler(mockService, &workspaceID).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestTenant_AnonymousRequestsPassThrough(t *testing.T) {
+	mockService := &WorkspaceServiceMock{}
+	var workspaceID int64
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+	rec := httptest.NewRecorder()
+
+	tenantTestHandler(mockService, &workspaceID).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Zero(t, workspaceID)
+	assert.Empty(t, mockService.ResolveCalls())
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key so keys are recognisable in headers and
+// secret scanners.
+const APIKeyPrefix = "sfk_"
+
+// apiKeyBytes is the amount of randomness in a key.
+const apiKeyBytes = 24
+
+// displayPrefixLength is how much of a key is kept in clear to identify it.
+const displayPrefixLength = len(APIKeyPrefix) + 8
+
+// GenerateAPIKey returns a new random key, the prefix kept to identify it and
+// the hash under which it is stored. The key itself is never stored.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	secret := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+	key = APIKeyPrefix + hex.EncodeToString(secret)
+	return key, key[:displayPrefixLength], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the stored form of key. Keys carry enough randomness
+// that a plain SHA-256 cannot be reversed, and it allows lookups by hash.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey reports whether token has the shape of an API key.
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix) && len(token) == len(APIKeyPrefix)+2*apiKeyBytes
+}
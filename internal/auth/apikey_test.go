@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, hash, err := GenerateAPIKey()
+
+	assert.NoError(t, err)
+	assert.True(t, IsAPIKey(key))
+	assert.True(t, len(prefix) < len(key))
+	assert.Equal(t, key[:len(prefix)], prefix)
+	assert.Equal(t, HashAPIKey(key), hash)
+	assert.NotContains(t, hash, key[len(APIKeyPrefix):])
+
+	other, _, _, _ := GenerateAPIKey()
+	assert.NotEqual(t, key, other)
+}
+
+func TestIsAPIKey(t *testing.T) {
+	assert.False(t, IsAPIKey(""))
+	assert.False(t, IsAPIKey("sfk_short"))
+	assert.False(t, IsAPIKey("eyJhbGciOiJSUzI1NiJ9.e30.sig"))
+}
+
+func TestPrincipal_HasScope(t *testing.T) {
+	principal := &Principal{Scopes: []string{ScopeSequencesRead}}
+
+	assert.True(t, principal.HasScope(ScopeSequencesRead))
+	assert.False(t, principal.HasScope(ScopeSequencesWrite))
+}
@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// minKeySetRefresh bounds how often an unknown key ID may trigger a reload,
+// so tokens naming made-up keys cannot be used to hammer the key source.
+const minKeySetRefresh = time.Minute
+
+// maxJWKSSize bounds the size of a fetched key set document.
+const maxJWKSSize = 1 << 20
+
+// jsonWebKey is a public key in a JSON Web Key Set (RFC 7517).
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS returns the signing keys of a JSON Web Key Set by key ID. Keys of
+// unsupported types or for other uses are skipped.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid key set: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch jwk.Kty {
+		case "RSA":
+			key, err = jwk.rsaKey()
+		case "EC":
+			key, err = jwk.ecdsaKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("key set contains no signing keys")
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) rsaKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeBigInt(k.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid RSA exponent")
+	}
+	if n.BitLen() < 2048 {
+		return nil, errors.New("RSA keys must be at least 2048 bits")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (k jsonWebKey) ecdsaKey() (*ecdsa.PublicKey, error) {
+	if k.Crv != "P-256" {
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+	x, err := decodeBigInt(k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeBigInt(k.Y)
+	if err != nil {
+		return nil, err
+	}
+	key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	if !key.Curve.IsOnCurve(x, y) {
+		return nil, errors.New("point is not on the curve")
+	}
+	return key, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// KeySet is a cached JSON Web Key Set. It is reloaded from its source once
+// the refresh interval has passed, and early when a token names a key it does
+// not hold, so keys rotated in at the issuer are picked up without a restart.
+type KeySet struct {
+	load    func(ctx context.Context) ([]byte, error)
+	refresh time.Duration
+	now     func() time.Time
+
+	mu       sync.Mutex
+	keys     map[string]crypto.PublicKey
+	loadedAt time.Time
+}
+
+// NewFileKeySet returns a key set read from a local file.
+func NewFileKeySet(path string, refresh time.Duration) *KeySet {
+	return newKeySet(func(ctx context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	}, refresh)
+}
+
+// NewURLKeySet returns a key set fetched over HTTP, such as an OIDC
+// provider's jwks_uri.
+func NewURLKeySet(url string, client *http.Client, refresh time.Duration) *KeySet {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return newKeySet(func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching key set: unexpected status %s", resp.Status)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	}, refresh)
+}
+
+func newKeySet(load func(ctx context.Context) ([]byte, error), refresh time.Duration) *KeySet {
+	return &KeySet{load: load, refresh: refresh, now: time.Now}
+}
+
+// Key returns the key with the given ID. A token without a key ID may only be
+// verified when the set holds a single key.
+func (s *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	stale := s.keys == nil || (s.refresh > 0 && now.Sub(s.loadedAt) >= s.refresh)
+	if !stale && s.lookup(kid) == nil && now.Sub(s.loadedAt) >= minKeySetRefresh {
+		stale = true
+	}
+	if stale {
+		if err := s.reload(ctx, now); err != nil {
+			if s.keys == nil {
+				return nil, err
+			}
+			// Keep serving the keys we have while the source is unavailable.
+			log.Printf("Failed to refresh key set: %v", err)
+		}
+	}
+
+	key := s.lookup(kid)
+	if key == nil {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+func (s *KeySet) lookup(kid string) crypto.PublicKey {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key
+		}
+	}
+	return s.keys[kid]
+}
+
+func (s *KeySet) reload(ctx context.Context, now time.Time) error {
+	data, err := s.load(ctx)
+	if err == nil {
+		var keys map[string]crypto.PublicKey
+		if keys, err = ParseJWKS(data); err == nil {
+			s.keys = keys
+		}
+	}
+	// Failed loads also wait out minKeySetRefresh before being retried.
+	s.loadedAt = now
+	return err
+}
@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken is wrapped by every error caused by the token itself, as
+// opposed to failures loading the keys needed to check it.
+var ErrInvalidToken = errors.New("invalid token")
+
+// ErrUnsupportedCredential is returned by authenticators that were handed a
+// credential of a kind they do not handle.
+var ErrUnsupportedCredential = errors.New("unsupported credential")
+
+// JWTConfig describes the tokens a JWTAuthenticator accepts and how their
+// claims map onto a principal.
+type JWTConfig struct {
+	Issuer   string
+	Audience string
+	// Leeway is the clock skew tolerated when checking exp and nbf.
+	Leeway time.Duration
+	// UserClaim, WorkspaceClaim and RolesClaim name the claims holding the
+	// user ID, workspace ID and roles. They default to "sub", "workspace"
+	// and "roles".
+	UserClaim      string
+	WorkspaceClaim string
+	RolesClaim     string
+	// RoleScopes grants scopes to holders of a role, in addition to the
+	// known scopes listed in the token's "scope" claim.
+	RoleScopes map[string][]string
+}
+
+// JWTAuthenticator authenticates requests bearing RS256 or ES256 signed JWTs,
+// such as OIDC access tokens.
+type JWTAuthenticator struct {
+	keys   *KeySet
+	config JWTConfig
+	now    func() time.Time
+}
+
+func NewJWTAuthenticator(keys *KeySet, config JWTConfig) *JWTAuthenticator {
+	if config.UserClaim == "" {
+		config.UserClaim = "sub"
+	}
+	if config.WorkspaceClaim == "" {
+		config.WorkspaceClaim = "workspace"
+	}
+	if config.RolesClaim == "" {
+		config.RolesClaim = "roles"
+	}
+	return &JWTAuthenticator{keys: keys, config: config, now: time.Now}
+}
+
+// Authenticate verifies token and returns the principal its claims describe.
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	if strings.Count(token, ".") != 2 {
+		return nil, ErrUnsupportedCredential
+	}
+	claims, err := a.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user := claimString(claims[a.config.UserClaim])
+	if user == "" {
+		return nil, fmt.Errorf("%w: missing %q claim", ErrInvalidToken, a.config.UserClaim)
+	}
+	workspace := claimString(claims[a.config.WorkspaceClaim])
+	roles := claimStrings(claims[a.config.RolesClaim])
+
+	var scopes []string
+	granted := func(scope string) {
+		for _, s := range scopes {
+			if s == scope {
+				return
+			}
+		}
+		scopes = append(scopes, scope)
+	}
+	if scope, ok := claims["scope"].(string); ok {
+		for _, s := range strings.Fields(scope) {
+			if IsScope(s) {
+				granted(s)
+			}
+		}
+	}
+	for _, role := range roles {
+		for _, s := range a.config.RoleScopes[role] {
+			granted(s)
+		}
+	}
+
+	name, _ := claims["name"].(string)
+	return &Principal{
+		Subject:   "user:" + user,
+		Name:      name,
+		Scopes:    scopes,
+		UserID:    user,
+		Workspace: workspace,
+		Roles:     roles,
+	}, nil
+}
+
+// Verify checks the signature, issuer, audience and validity period of token
+// and returns its claims.
+func (a *JWTAuthenticator) Verify(ctx context.Context, token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Alg != "RS256" && header.Alg != "ES256" {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+
+	key, err := a.keys.Key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !verifySignature(header.Alg, key, digest[:], signature) {
+		return nil, fmt.Errorf("%w: signature verification failed", ErrInvalidToken)
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err := a.checkClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// verifySignature checks signature with key, which must be of the type alg
+// calls for so that a token cannot choose how its own key is interpreted.
+func verifySignature(alg string, key crypto.PublicKey, digest, signature []byte) bool {
+	switch alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest, signature) == nil
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(ecKey, digest, r, s)
+	}
+	return false
+}
+
+func (a *JWTAuthenticator) checkClaims(claims map[string]interface{}) error {
+	now := a.now()
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(a.config.Leeway)) {
+		return fmt.Errorf("%w: token has expired", ErrInvalidToken)
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(a.config.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+	if a.config.Issuer != "" && claims["iss"] != a.config.Issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if a.config.Audience != "" {
+		found := false
+		for _, aud := range claimStrings(claims["aud"]) {
+			if aud == a.config.Audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: token is not intended for this audience", ErrInvalidToken)
+		}
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil || json.Unmarshal(data, v) != nil {
+		return fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+	return nil
+}
+
+// claimString returns a string or numeric claim as a string.
+func claimString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprintf("%.0f", v)
+	}
+	return ""
+}
+
+// claimStrings returns a claim that is either a string or a list of strings.
+func claimStrings(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
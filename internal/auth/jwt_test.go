@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testRSAKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	testECKey, _  = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	testNow       = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// jwksJSON renders a key set holding the public halves of keys.
+func jwksJSON(keys map[string]crypto.Signer) []byte {
+	var set struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	for kid, key := range keys {
+		switch pub := key.Public().(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, map[string]string{
+				"kty": "RSA", "kid": kid, "use": "sig",
+				"n": b64(pub.N.Bytes()), "e": b64(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case *ecdsa.PublicKey:
+			set.Keys = append(set.Keys, map[string]string{
+				"kty": "EC", "kid": kid, "crv": "P-256",
+				"x": b64(pub.X.FillBytes(make([]byte, 32))), "y": b64(pub.Y.FillBytes(make([]byte, 32))),
+			})
+		}
+	}
+	data, _ := json.Marshal(set)
+	return data
+}
+
+// signToken returns a JWT with claims signed by key.
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("signing token: %v", err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatalf("signing token: %v", err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signingInput + "." + b64(signature)
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":       "https://issuer.test/",
+		"aud":       []string{"sequence-flow-api", "other"},
+		"sub":       "42",
+		"name":      "Ada",
+		"workspace": "acme",
+		"roles":     []string{"editor"},
+		"scope":     "openid sequences:read",
+		"exp":       testNow.Add(time.Hour).Unix(),
+		"iat":       testNow.Unix(),
+	}
+}
+
+// writeKeySet writes a key set file and returns its path.
+func writeKeySet(t *testing.T, path string, keys map[string]crypto.Signer) string {
+	if path == "" {
+		path = filepath.Join(t.TempDir(), "jwks.json")
+	}
+	if err := os.WriteFile(path, jwksJSON(keys), 0o600); err != nil {
+		t.Fatalf("writing key set: %v", err)
+	}
+	return path
+}
+
+func newTestAuthenticator(keys *KeySet) *JWTAuthenticator {
+	keys.now = func() time.Time { return testNow }
+	authenticator := NewJWTAuthenticator(keys, JWTConfig{
+		Issuer:     "https://issuer.test/",
+		Audience:   "sequence-flow-api",
+		RoleScopes: map[string][]string{"editor": {ScopeSequencesWrite}},
+	})
+	authenticator.now = func() time.Time { return testNow }
+	return authenticator
+}
+
+func TestJWTAuthenticator_ValidTokens(t *testing.T) {
+	path := writeKeySet(t, "", map[string]crypto.Signer{"rsa-1": testRSAKey, "ec-1": testECKey})
+	authenticator := newTestAuthenticator(NewFileKeySet(path, time.Hour))
+
+	for _, token := range []string{
+		signToken(t, "RS256", "rsa-1", testRSAKey, validClaims()),
+		signToken(t, "ES256", "ec-1", testECKey, validClaims()),
+	} {
+		principal, err := authenticator.Authenticate(context.Background(), token)
+		assert.NoError(t, err)
+		assert.Equal(t, "user:42", principal.Subject)
+		assert.Equal(t, "42", principal.UserID)
+		assert.Equal(t, "acme", principal.Workspace)
+		assert.Equal(t, "Ada", principal.Name)
+		assert.Equal(t, []string{"editor"}, principal.Roles)
+		assert.Equal(t, []string{ScopeSequencesRead, ScopeSequencesWrite}, principal.Scopes)
+	}
+}
+
+func TestJWTAuthenticator_RejectsInvalidTokens(t *testing.T) {
+	path := writeKeySet(t, "", map[string]crypto.Signer{"rsa-1": testRSAKey, "ec-1": testECKey})
+	authenticator := newTestAuthenticator(NewFileKeySet(path, time.Hour))
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	withClaim := func(name string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+	valid := signToken(t, "RS256", "rsa-1", testRSAKey, validClaims())
+	parts := strings.Split(valid, ".")
+	noneHeader, _ := json.Marshal(map[string]string{"alg": "none", "kid": "rsa-1"})
+
+	tests := map[string]string{
+		"expired":          signToken(t, "RS256", "rsa-1", testRSAKey, withClaim("exp", testNow.Add(-time.Hour).Unix())),
+		"missing exp":      signToken(t, "RS256", "rsa-1", testRSAKey, withClaim("exp", nil)),
+		"not yet valid":    signToken(t, "RS256", "rsa-1", testRSAKey, withClaim("nbf", testNow.Add(time.Hour).Unix())),
+		"wrong issuer":     signToken(t, "RS256", "rsa-1", testRSAKey, withClaim("iss", "https://evil.test/")),
+		"wrong audience":   signToken(t, "RS256", "rsa-1", testRSAKey, withClaim("aud", "other")),
+		"missing subject":  signToken(t, "RS256", "rsa-1", testRSAKey, withClaim("sub", nil)),
+		"wrong key":        signToken(t, "ES256", "ec-1", otherKey, validClaims()),
+		"key type mixup":   signToken(t, "ES256", "rsa-1", testECKey, validClaims()),
+		"tampered payload": parts[0] + "." + b64([]byte(`{"sub":"1","exp":9999999999}`)) + "." + parts[2],
+		"alg none":         b64(noneHeader) + "." + parts[1] + ".",
+	}
+	for name, token := range tests {
+		_, err := authenticator.Authenticate(context.Background(), token)
+		assert.True(t, errors.Is(err, ErrInvalidToken), "%s: got %v", name, err)
+	}
+}
+
+func TestJWTAuthenticator_UnsupportedCredential(t *testing.T) {
+	authenticator := newTestAuthenticator(NewFileKeySet("unused", time.Hour))
+
+	_, err := authenticator.Authenticate(context.Background(), "sfk_0123456789")
+
+	assert.ErrorIs(t, err, ErrUnsupportedCredential)
+}
+
+func TestKeySet_PicksUpRotatedKeys(t *testing.T) {
+	path := writeKeySet(t, "", map[string]crypto.Signer{"rsa-1": testRSAKey})
+	keys := NewFileKeySet(path, 24*time.Hour)
+	authenticator := newTestAuthenticator(keys)
+
+	_, err := authenticator.Authenticate(context.Background(), signToken(t, "RS256", "rsa-1", testRSAKey, validClaims()))
+	assert.NoError(t, err)
+
+	// The issuer rotates in a new key; tokens signed with it are accepted once
+	// the unknown key ID may trigger a reload.
+	writeKeySet(t, path, map[string]crypto.Signer{"rsa-1": testRSAKey, "ec-2": testECKey})
+	rotated := signToken(t, "ES256", "ec-2", testECKey, validClaims())
+
+	_, err = authenticator.Authenticate(context.Background(), rotated)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	keys.now = func() time.Time { return testNow.Add(minKeySetRefresh) }
+	_, err = authenticator.Authenticate(context.Background(), rotated)
+	assert.NoError(t, err)
+}
+
+func TestKeySet_URLSourceIsCached(t *testing.T) {
+	fetches := 0
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(jwksJSON(map[string]crypto.Signer{"ec-1": testECKey}))
+	}))
+	defer server.Close()
+
+	keys := NewURLKeySet(server.URL, server.Client(), time.Hour)
+	authenticator := newTestAuthenticator(keys)
+	token := signToken(t, "ES256", "ec-1", testECKey, validClaims())
+
+	for i := 0; i < 3; i++ {
+		_, err := authenticator.Authenticate(context.Background(), token)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, fetches)
+
+	// Once the keys are stale, an unavailable source leaves the cached keys in use.
+	failing = true
+	keys.now = func() time.Time { return testNow.Add(2 * time.Hour) }
+	_, err := authenticator.Authenticate(context.Background(), token)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fetches)
+}
+
+func TestParseJWKS_RejectsWeakRSAKeys(t *testing.T) {
+	weak, _ := rsa.GenerateKey(rand.Reader, 1024)
+
+	_, err := ParseJWKS(jwksJSON(map[string]crypto.Signer{"weak": weak}))
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,81 @@
+// Package auth defines the authenticated principal of a request and the
+// credentials that resolve to one.
+package auth
+
+import "context"
+
+// Scopes granted to principals and required by routes.
+const (
+	ScopeSequencesRead  = "sequences:read"
+	ScopeSequencesWrite = "sequences:write"
+	ScopeContactsWrite  = "contacts:write"
+	ScopeJobsRead       = "jobs:read"
+	ScopeSendsAdmin     = "sends:admin"
+	ScopeKeysAdmin      = "keys:admin"
+	ScopeTeamsAdmin     = "teams:admin"
+	ScopeAuditRead      = "audit:read"
+)
+
+// Scopes lists every known scope.
+var Scopes = []string{
+	ScopeSequencesRead,
+	ScopeSequencesWrite,
+	ScopeContactsWrite,
+	ScopeJobsRead,
+	ScopeSendsAdmin,
+	ScopeKeysAdmin,
+	ScopeTeamsAdmin,
+	ScopeAuditRead,
+}
+
+// IsScope reports whether scope is a known scope.
+func IsScope(scope string) bool {
+	for _, known := range Scopes {
+		if known == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject identifies the caller, such as "api_key:12" or "user:42".
+	Subject string
+	Name    string
+	Scopes  []string
+	// WorkspaceID is the workspace the caller acts in. Callers using a
+	// token name their workspace in Workspace instead, and WorkspaceID is
+	// set once it has been resolved.
+	WorkspaceID int64
+	Workspace   string
+	// APIKeyID is set for callers using an API key.
+	APIKeyID int64
+	// UserID and Roles are set for callers using a token issued to a user.
+	// API keys created by a user also carry its UserID.
+	UserID string
+	Roles  []string
+}
+
+// HasScope reports whether the principal was granted scope.
+func (p *Principal) HasScope(scope string) bool {
+	for _, granted := range p.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal carried by ctx, if any.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
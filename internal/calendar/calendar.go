@@ -0,0 +1,91 @@
+// Package calendar counts days around weekends and holidays, so sends can be
+// kept off the days nobody reads their email.
+package calendar
+
+import (
+	"sf_test/internal/models"
+	"time"
+)
+
+// Calendar knows which days are holidays. Days, including weekends, are
+// calendar days in the calendar's location. A nil Calendar has no holidays
+// and counts days in UTC.
+type Calendar struct {
+	holidays map[civilDate]bool
+	loc      *time.Location
+}
+
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func dateOf(t time.Time) civilDate {
+	year, month, day := t.Date()
+	return civilDate{year, month, day}
+}
+
+// New returns a calendar with the given holidays, counting days in loc.
+// A nil loc counts them in UTC.
+func New(holidays []*models.Holiday, loc *time.Location) *Calendar {
+	if loc == nil {
+		loc = time.UTC
+	}
+	c := &Calendar{holidays: make(map[civilDate]bool, len(holidays)), loc: loc}
+	for _, holiday := range holidays {
+		c.holidays[dateOf(holiday.Date.UTC())] = true
+	}
+	return c
+}
+
+// local returns t in the calendar's location.
+func (c *Calendar) local(t time.Time) time.Time {
+	if c == nil {
+		return t.UTC()
+	}
+	return t.In(c.loc)
+}
+
+// IsHoliday reports whether t falls on a holiday.
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	return c != nil && c.holidays[dateOf(c.local(t))]
+}
+
+// IsBusinessDay reports whether t falls on a weekday that is not a holiday.
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	switch c.local(t).Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	return !c.IsHoliday(t)
+}
+
+// AddBusinessDays returns the time of day of t on the nth business day after
+// it. With n 0 it returns t moved to the next business day if it is not on one.
+// The result is in t's location.
+func (c *Calendar) AddBusinessDays(t time.Time, n int) time.Time {
+	day := c.local(t)
+	if n <= 0 {
+		for !c.IsBusinessDay(day) {
+			day = day.AddDate(0, 0, 1)
+		}
+		return day.In(t.Location())
+	}
+	for n > 0 {
+		day = day.AddDate(0, 0, 1)
+		if c.IsBusinessDay(day) {
+			n--
+		}
+	}
+	return day.In(t.Location())
+}
+
+// SkipHolidays returns t moved day by day past any holidays it falls on.
+func (c *Calendar) SkipHolidays(t time.Time) time.Time {
+	day := c.local(t)
+	for c.IsHoliday(day) {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day.In(t.Location())
+}
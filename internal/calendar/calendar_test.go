@@ -0,0 +1,120 @@
+package calendar
+
+import (
+	"sf_test/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(month time.Month, d, hour int) time.Time {
+	return time.Date(2024, month, d, hour, 0, 0, 0, time.UTC)
+}
+
+// easter is a calendar with Good Friday and Easter Monday 2024 off.
+var easter = New([]*models.Holiday{{Date: day(time.March, 29, 0)}, {Date: day(time.April, 1, 0)}}, time.UTC)
+
+func TestCalendar_AddBusinessDays(t *testing.T) {
+	tests := []struct {
+		name string
+		from time.Time
+		days int
+		want time.Time
+	}{
+		{"within the week", day(time.March, 4, 9), 2, day(time.March, 6, 9)},
+		{"over a weekend", day(time.March, 8, 9), 1, day(time.March, 11, 9)},
+		{"from a weekend", day(time.March, 9, 9), 1, day(time.March, 11, 9)},
+		{"none on a weekend", day(time.March, 9, 9), 0, day(time.March, 11, 9)},
+		{"none on a weekday", day(time.March, 4, 9), 0, day(time.March, 4, 9)},
+		{"over holidays", day(time.March, 28, 9), 1, day(time.April, 2, 9)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, easter.AddBusinessDays(tt.from, tt.days))
+		})
+	}
+}
+
+func TestCalendar_SkipHolidays(t *testing.T) {
+	assert.Equal(t, day(time.March, 30, 15), easter.SkipHolidays(day(time.March, 29, 15)), "weekends are not skipped")
+	assert.Equal(t, day(time.April, 2, 15), easter.SkipHolidays(day(time.April, 1, 15)))
+	assert.Equal(t, day(time.April, 3, 15), easter.SkipHolidays(day(time.April, 3, 15)))
+}
+
+func TestCalendar_CountsDaysInItsLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	c := New([]*models.Holiday{{Date: day(time.March, 29, 0)}}, tokyo)
+
+	// Friday 20:00 UTC is already Saturday in Tokyo.
+	friday := day(time.March, 8, 20)
+	assert.True(t, easter.IsBusinessDay(friday))
+	assert.False(t, c.IsBusinessDay(friday))
+	assert.Equal(t, day(time.March, 10, 20), c.AddBusinessDays(friday, 0))
+
+	// Thursday 16:00 UTC is Good Friday in Tokyo.
+	assert.False(t, easter.IsHoliday(day(time.March, 28, 16)))
+	assert.True(t, c.IsHoliday(day(time.March, 28, 16)))
+	assert.Equal(t, day(time.March, 29, 16), c.SkipHolidays(day(time.March, 28, 16)))
+}
+
+func TestCalendar_NilHasNoHolidays(t *testing.T) {
+	var c *Calendar
+	assert.False(t, c.IsHoliday(day(time.March, 29, 0)))
+	assert.Equal(t, day(time.March, 11, 9), c.AddBusinessDays(day(time.March, 8, 9), 1))
+}
+
+func TestParseICal(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Example//Holidays//EN",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20241225",
+		"DTEND;VALUE=DATE:20241227",
+		"SUMMARY:Christmas\\, and",
+		"  Boxing Day",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;TZID=Pacific/Auckland:20240101T090000",
+		"SUMMARY:New Year's Day",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20241225T100000Z",
+		"SUMMARY:Carols",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	holidays, err := ParseICal(strings.NewReader(ics))
+	require.NoError(t, err)
+	assert.Equal(t, []*models.Holiday{
+		{Date: day(time.January, 1, 0), Name: "New Year's Day"},
+		{Date: day(time.December, 25, 0), Name: "Christmas, and Boxing Day"},
+		{Date: day(time.December, 26, 0), Name: "Christmas, and Boxing Day"},
+	}, holidays)
+}
+
+func TestParseICal_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		ics  string
+		want string
+	}{
+		{"not a calendar", "BEGIN:VEVENT\nEND:VEVENT", "must begin with BEGIN:VCALENDAR"},
+		{"malformed line", "BEGIN:VCALENDAR\nhello", "line 2: malformed content line"},
+		{"missing start", "BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\nEND:VCALENDAR", "line 2: event has no DTSTART"},
+		{"invalid date", "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:2024-12-25\nEND:VEVENT\nEND:VCALENDAR", "line 3: DTSTART: invalid date"},
+		{"too long", "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:20240101\nDTEND;VALUE=DATE:20260101\nEND:VEVENT\nEND:VCALENDAR", "more than 366 days"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseICal(strings.NewReader(tt.ics))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
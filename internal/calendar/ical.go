@@ -0,0 +1,191 @@
+package calendar
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sf_test/internal/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxEventDays bounds how many holidays a single event may span.
+const maxEventDays = 366
+
+// ParseICal reads the holidays from an iCalendar (RFC 5545) file, one per day
+// its events cover. All-day events cover the days from DTSTART up to DTEND,
+// which is exclusive; timed events cover the day they start on. Recurrence
+// rules are not expanded, so a recurring event counts once. The holidays are
+// returned by date with one per day, named after the first event on it.
+func ParseICal(r io.Reader) ([]*models.Holiday, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.EqualFold(lines[0].text, "BEGIN:VCALENDAR") {
+		return nil, errors.New("not an iCalendar file: it must begin with BEGIN:VCALENDAR")
+	}
+
+	byDate := map[civilDate]*models.Holiday{}
+	var event *icalEvent
+	for _, line := range lines {
+		name, params, value, ok := splitContentLine(line.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: malformed content line", line.number)
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			event = &icalEvent{line: line.number}
+		case name == "END" && strings.EqualFold(value, "VEVENT") && event != nil:
+			days, err := event.days()
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", event.line, err)
+			}
+			for _, day := range days {
+				if _, ok := byDate[dateOf(day)]; !ok {
+					byDate[dateOf(day)] = &models.Holiday{Date: day, Name: event.summary}
+				}
+			}
+			event = nil
+		case event == nil:
+		case name == "DTSTART":
+			if event.start, event.allDay, err = parseICalTime(params, value); err != nil {
+				return nil, fmt.Errorf("line %d: DTSTART: %w", line.number, err)
+			}
+		case name == "DTEND":
+			if event.end, _, err = parseICalTime(params, value); err != nil {
+				return nil, fmt.Errorf("line %d: DTEND: %w", line.number, err)
+			}
+		case name == "SUMMARY":
+			event.summary = unescapeText(value)
+		}
+	}
+	holidays := make([]*models.Holiday, 0, len(byDate))
+	for _, holiday := range byDate {
+		holidays = append(holidays, holiday)
+	}
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date.Before(holidays[j].Date) })
+	return holidays, nil
+}
+
+type icalEvent struct {
+	line       int
+	start, end time.Time
+	allDay     bool
+	summary    string
+}
+
+// days returns midnight UTC of each day the event covers.
+func (e *icalEvent) days() ([]time.Time, error) {
+	if e.start.IsZero() {
+		return nil, errors.New("event has no DTSTART")
+	}
+	first := midnight(e.start)
+	if !e.allDay || e.end.IsZero() || !e.end.After(e.start) {
+		return []time.Time{first}, nil
+	}
+	var days []time.Time
+	for day := first; day.Before(midnight(e.end)); day = day.AddDate(0, 0, 1) {
+		if len(days) == maxEventDays {
+			return nil, fmt.Errorf("event spans more than %d days", maxEventDays)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func midnight(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// parseICalTime parses a DATE or DATE-TIME value and reports whether it is a
+// date. Date-times keep their own zone, so the day they start on is the day
+// of their calendar.
+func parseICalTime(params map[string]string, value string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.Parse("20060102", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+		}
+		return t, true, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+		}
+		return t, false, nil
+	}
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+	}
+	return t, false, nil
+}
+
+type contentLine struct {
+	number int
+	text   string
+}
+
+// unfoldLines joins the lines RFC 5545 folds by starting continuations with
+// a space or tab, and drops empty lines.
+func unfoldLines(r io.Reader) ([]contentLine, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var lines []contentLine
+	number := 0
+	for scanner.Scan() {
+		number++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+		if text != "" {
+			lines = append(lines, contentLine{number: number, text: text})
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// splitContentLine splits "NAME;PARAM=VALUE:value" into its upper-cased
+// name, its parameters and its value.
+func splitContentLine(line string) (name string, params map[string]string, value string, ok bool) {
+	colon := -1
+	quoted := false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return "", nil, "", false
+	}
+	parts := strings.Split(line[:colon], ";")
+	params = map[string]string{}
+	for _, param := range parts[1:] {
+		key, val, _ := strings.Cut(param, "=")
+		params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+var textUnescaper = strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+func unescapeText(value string) string {
+	return strings.TrimSpace(textUnescaper.Replace(value))
+}
@@ -0,0 +1,38 @@
+// Package clock abstracts telling the time and waiting for it to pass, so
+// code that schedules work can run on a simulated clock in tests.
+package clock
+
+import "time"
+
+// Clock tells the time and makes timers.
+type Clock interface {
+	Now() time.Time
+	// NewTimer returns a timer that fires once d has passed.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer delivers the time on its channel once it fires, like time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	// Stop prevents the timer from firing and reports whether it was
+	// still waiting to.
+	Stop() bool
+	// Reset makes the timer fire once d has passed from now and reports
+	// whether it was still waiting to fire.
+	Reset(d time.Duration) bool
+}
+
+// System is the clock of the operating system.
+var System Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) Timer { return systemTimer{time.NewTimer(d)} }
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time { return t.Timer.C }
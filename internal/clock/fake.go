@@ -0,0 +1,129 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only moves when told to, so tests can run days
+// of scheduling in moments. Its timers fire as Advance or Set moves the time
+// past them.
+type Fake struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	timers  []*fakeTimer
+}
+
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.changed = sync.NewCond(&f.mu)
+	return f
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1)}
+	f.timers = append(f.timers, t)
+	t.schedule(d)
+	return t
+}
+
+// Advance moves the time forward by d.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.set(f.now.Add(d))
+}
+
+// Set moves the time to now, which must not be before the current time.
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.set(now)
+}
+
+func (f *Fake) set(now time.Time) {
+	if now.Before(f.now) {
+		panic("clock: fake time cannot go backwards")
+	}
+	f.now = now
+	for _, t := range f.timers {
+		if t.waiting && !t.at.After(now) {
+			t.fire()
+		}
+	}
+}
+
+// BlockUntil waits until n timers are waiting to fire, so a test knows the
+// code under test has gone to sleep before it advances the time.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for f.waiting() < n {
+		f.changed.Wait()
+	}
+}
+
+func (f *Fake) waiting() int {
+	n := 0
+	for _, t := range f.timers {
+		if t.waiting {
+			n++
+		}
+	}
+	return n
+}
+
+type fakeTimer struct {
+	clock   *Fake
+	c       chan time.Time
+	at      time.Time
+	waiting bool
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	waiting := t.waiting
+	t.waiting = false
+	return waiting
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	waiting := t.waiting
+	t.schedule(d)
+	return waiting
+}
+
+// schedule makes the timer fire d from now. The clock's lock must be held.
+func (t *fakeTimer) schedule(d time.Duration) {
+	t.at = t.clock.now.Add(d)
+	if d <= 0 {
+		t.fire()
+		return
+	}
+	t.waiting = true
+	t.clock.changed.Broadcast()
+}
+
+// fire delivers the time unless an earlier firing was not received yet, like
+// time.Timer. The clock's lock must be held.
+func (t *fakeTimer) fire() {
+	t.waiting = false
+	select {
+	case t.c <- t.clock.now:
+	default:
+	}
+}
@@ -0,0 +1,57 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+
+func fired(t Timer) bool {
+	select {
+	case <-t.C():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestFake_TimersFireWhenTimeReachesThem(t *testing.T) {
+	clock := NewFake(start)
+	timer := clock.NewTimer(time.Hour)
+
+	clock.Advance(59 * time.Minute)
+	assert.False(t, fired(timer))
+	clock.Advance(time.Minute)
+	assert.True(t, fired(timer))
+	assert.Equal(t, start.Add(time.Hour), clock.Now())
+
+	// A fired timer can be reset, and a stopped one does not fire.
+	assert.False(t, timer.Reset(time.Minute))
+	assert.True(t, timer.Stop())
+	clock.Set(start.Add(24 * time.Hour))
+	assert.False(t, fired(timer))
+
+	assert.True(t, fired(clock.NewTimer(0)), "timers with no wait fire at once")
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	clock := NewFake(start)
+	done := make(chan struct{})
+	go func() {
+		clock.BlockUntil(1)
+		close(done)
+	}()
+
+	timer := clock.NewTimer(time.Second)
+	<-done
+	clock.Advance(time.Second)
+	assert.True(t, fired(timer))
+}
+
+func TestFake_CannotGoBackwards(t *testing.T) {
+	clock := NewFake(start)
+	assert.Panics(t, func() { clock.Set(start.Add(-time.Second)) })
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sf_test/internal/auth"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+	"strconv"
+)
+
+type apiKeyService struct {
+	repo db.APIKeyRepository
+}
+
+func NewAPIKeyService(repo db.APIKeyRepository) APIKeyService {
+	return &apiKeyService{repo: repo}
+}
+
+// CreateAPIKey stores a new key and returns it with its secret, which cannot
+// be retrieved again. Callers may only grant scopes they hold themselves, and
+// the key acts as the caller's user so that user's team roles apply to it.
+// The command line, which has no principal, may grant any scope.
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, name string, scopes []string) (*models.CreatedAPIKey, error) {
+	key := &models.APIKey{Name: name, Scopes: scopes}
+	if err := key.Validate(); err != nil {
+		return nil, newValidationError(err)
+	}
+	for _, scope := range scopes {
+		if !auth.IsScope(scope) {
+			return nil, &ValidationError{
+				Message: fmt.Sprintf("unknown scope %q", scope),
+				Fields:  []FieldError{{Field: "scopes", Rule: "oneof", Message: fmt.Sprintf("%q is not a known scope", scope)}},
+			}
+		}
+	}
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		for _, scope := range scopes {
+			if !principal.HasScope(scope) {
+				return nil, &ForbiddenError{Message: fmt.Sprintf("cannot grant scope %q you do not hold", scope)}
+			}
+		}
+		key.UserID = principal.UserID
+	}
+
+	secret, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	key.Prefix = prefix
+	key.Hash = hash
+	if _, err := s.repo.Create(ctx, key); err != nil {
+		return nil, translateError(err, "api key", 0)
+	}
+	return &models.CreatedAPIKey{APIKey: *key, Key: secret}, nil
+}
+
+func (s *apiKeyService) ListAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, id int64) error {
+	return translateError(s.repo.Revoke(ctx, id), "api key", id)
+}
+
+// Authenticate resolves a raw key to the principal it was issued for.
+func (s *apiKeyService) Authenticate(ctx context.Context, secret string) (*auth.Principal, error) {
+	if !auth.IsAPIKey(secret) {
+		return nil, auth.ErrUnsupportedCredential
+	}
+	key, err := s.repo.GetByHash(ctx, auth.HashAPIKey(secret))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &UnauthenticatedError{Message: "invalid API key"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, &UnauthenticatedError{Message: "API key has been revoked"}
+	}
+
+	if err := s.repo.TouchLastUsed(ctx, key.ID); err != nil {
+		// Usage tracking must not lock clients out.
+		log.Printf("Failed to record API key use: %v", err)
+	}
+	return &auth.Principal{
+		Subject:     "api_key:" + strconv.FormatInt(key.ID, 10),
+		Name:        key.Name,
+		Scopes:      key.Scopes,
+		WorkspaceID: key.WorkspaceID,
+		APIKeyID:    key.ID,
+		UserID:      key.UserID,
+	}, nil
+}
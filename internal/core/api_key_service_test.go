@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"sf_test/internal/auth"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAPIKeyRepository struct {
+	db.APIKeyRepository
+	created []*models.APIKey
+	byHash  map[string]*models.APIKey
+}
+
+func (r *fakeAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) (int64, error) {
+	key.ID = int64(len(r.created) + 1)
+	r.created = append(r.created, key)
+	return key.ID, nil
+}
+
+func (r *fakeAPIKeyRepository) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	key, ok := r.byHash[hash]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return key, nil
+}
+
+func (r *fakeAPIKeyRepository) TouchLastUsed(ctx context.Context, id int64) error {
+	return nil
+}
+
+func TestAPIKeyService_CreateOnlyGrantsHeldScopes(t *testing.T) {
+	repo := &fakeAPIKeyRepository{}
+	service := NewAPIKeyService(repo)
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{
+		Subject: "api_key:1",
+		Scopes:  []string{auth.ScopeKeysAdmin},
+	})
+
+	_, err := service.CreateAPIKey(admin, "escalated", []string{auth.ScopeSequencesWrite})
+	assert.True(t, isForbidden(err), "got %v", err)
+	assert.Empty(t, repo.created)
+
+	created, err := service.CreateAPIKey(admin, "deputy", []string{auth.ScopeKeysAdmin})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{auth.ScopeKeysAdmin}, created.Scopes)
+
+	// The command line has no principal and bootstraps keys with any scope.
+	_, err = service.CreateAPIKey(context.Background(), "bootstrap", auth.Scopes)
+	assert.NoError(t, err)
+	assert.Len(t, repo.created, 2)
+}
+
+func TestAPIKeyService_KeysActAsTheirCreator(t *testing.T) {
+	repo := &fakeAPIKeyRepository{}
+	service := NewAPIKeyService(repo)
+	user := auth.WithPrincipal(context.Background(), &auth.Principal{
+		Subject: "user:editor",
+		UserID:  "editor",
+		Scopes:  []string{auth.ScopeKeysAdmin, auth.ScopeSequencesWrite},
+	})
+
+	created, err := service.CreateAPIKey(user, "ci", []string{auth.ScopeSequencesWrite})
+	assert.NoError(t, err)
+	assert.Equal(t, "editor", created.UserID)
+
+	repo.byHash = map[string]*models.APIKey{created.Hash: &created.APIKey}
+	principal, err := service.Authenticate(context.Background(), created.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, "editor", principal.UserID)
+}
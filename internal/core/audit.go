@@ -0,0 +1,110 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sf_test/internal/auth"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+	"sf_test/internal/requestid"
+)
+
+// auditIgnoredFields change with every write and would only add noise to
+// audit entries. A sequence's steps are audited as steps of their own.
+var auditIgnoredFields = map[string]bool{
+	"version":   true,
+	"createdAt": true,
+	"updatedAt": true,
+	"steps":     true,
+}
+
+// recordAudit appends an audit entry describing the change from before to
+// after, either of which is nil for creates and deletes. It must be called
+// in the transaction making the change.
+func recordAudit(ctx context.Context, repo db.AuditRepository, action, resourceType string, resourceID int64, before, after interface{}) error {
+	changes, err := auditChanges(before, after)
+	if err != nil {
+		return err
+	}
+	actor := "system"
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		actor = principal.Subject
+	}
+	return repo.Append(ctx, &models.AuditEntry{
+		Actor:        actor,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Changes:      changes,
+		RequestID:    requestid.RequestIDFrom(ctx),
+	})
+}
+
+// auditChanges compares the JSON representations of before and after field
+// by field and returns the fields that differ.
+func auditChanges(before, after interface{}) (map[string]models.AuditChange, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]models.AuditChange{}
+	for name, value := range beforeFields {
+		if !bytes.Equal(value, afterFields[name]) {
+			changes[name] = models.AuditChange{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = models.AuditChange{After: value}
+		}
+	}
+	return changes, nil
+}
+
+// jsonFields returns the JSON encoding of each audited field of v, which is
+// nil or a value encoding to a JSON object.
+func jsonFields(v interface{}) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if v == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range fields {
+		if auditIgnoredFields[name] || bytes.Equal(value, []byte("null")) {
+			delete(fields, name)
+		}
+	}
+	return fields, nil
+}
+
+type auditService struct {
+	repo       db.AuditRepository
+	authorizer Authorizer
+}
+
+func NewAuditService(repo db.AuditRepository, authorizer Authorizer) AuditService {
+	return &auditService{repo: repo, authorizer: authorizer}
+}
+
+func (s *auditService) ListAudit(ctx context.Context, opts models.ListOptions) ([]*models.AuditEntry, string, error) {
+	if err := s.authorizer.Authorize(ctx, ActionRead, nil); err != nil {
+		return nil, "", err
+	}
+	entries, next, err := s.repo.List(ctx, opts)
+	if err != nil {
+		return nil, "", translateError(err, "audit entry", 0)
+	}
+	return entries, next, nil
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+	"sf_test/internal/requestid"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAuditRepository struct {
+	db.AuditRepository
+	entries []*models.AuditEntry
+	err     error
+}
+
+func (r *fakeAuditRepository) Append(ctx context.Context, entry *models.AuditEntry) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func TestAuditChanges(t *testing.T) {
+	before := &models.Step{ID: 1, SequenceID: 7, Subject: "Welcome", Content: "Hello", Version: 1}
+	after := &models.Step{ID: 1, SequenceID: 7, Subject: "Welcome", Content: "Hello again", Version: 2}
+
+	changes, err := auditChanges(before, after)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]models.AuditChange{
+		"content": {Before: json.RawMessage(`"Hello"`), After: json.RawMessage(`"Hello again"`)},
+	}, changes)
+
+	changes, err = auditChanges(nil, after)
+	assert.NoError(t, err)
+	assert.Equal(t, json.RawMessage(`"Hello again"`), changes["content"].After)
+	assert.Nil(t, changes["content"].Before)
+	assert.NotContains(t, changes, "version")
+}
+
+func TestStepService_RecordsAuditEntries(t *testing.T) {
+	team := int64(1)
+	sequences := &fakeSequenceRepository{sequences: map[int64]*models.Sequence{7: {ID: 7, TeamID: &team}}}
+	steps := &fakeStepRepository{steps: map[int64]*models.Step{1: {ID: 1, SequenceID: 7, Subject: "Welcome", Content: "Hello"}}}
+	audit := &fakeAuditRepository{}
+	tx := &fakeTransactor{}
+	service := NewStepService(tx, steps, sequences, audit, testAuthorizer())
+	ctx := requestid.WithRequestID(asUser("editor"), "req-1")
+
+	err := service.UpdateStep(ctx, &models.Step{ID: 1, Subject: "Welcome", Content: "Hello again"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tx.calls)
+	if assert.Len(t, audit.entries, 1) {
+		entry := audit.entries[0]
+		assert.Equal(t, "user:editor", entry.Actor)
+		assert.Equal(t, models.AuditActionUpdate, entry.Action)
+		assert.Equal(t, models.AuditResourceStep, entry.ResourceType)
+		assert.Equal(t, int64(1), entry.ResourceID)
+		assert.Equal(t, "req-1", entry.RequestID)
+		assert.Equal(t, []string{"content"}, keys(entry.Changes))
+	}
+}
+
+func TestStepService_FailsWhenAuditEntryCannotBeWritten(t *testing.T) {
+	sequences := &fakeSequenceRepository{sequences: map[int64]*models.Sequence{7: {ID: 7}}}
+	steps := &fakeStepRepository{steps: map[int64]*models.Step{1: {ID: 1, SequenceID: 7, Subject: "Welcome", Content: "Hello"}}}
+	audit := &fakeAuditRepository{err: errors.New("audit log unavailable")}
+	service := NewStepService(&fakeTransactor{}, steps, sequences, audit, testAuthorizer())
+
+	err := service.UpdateStep(context.Background(), &models.Step{ID: 1, Subject: "Welcome", Content: "Hello again"})
+
+	// The error rolls back the transaction holding the update.
+	assert.EqualError(t, err, "audit log unavailable")
+}
+
+func keys(changes map[string]models.AuditChange) []string {
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	return names
+}
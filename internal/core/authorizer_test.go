@@ -0,0 +1,202 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"testing"
+
+	"sf_test/internal/auth"
+	"sf_test/internal/clock"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTeamRepository struct {
+	db.TeamRepository
+	memberships map[string][]*models.TeamMember
+}
+
+func (r *fakeTeamRepository) MembershipsOf(ctx context.Context, subject string) ([]*models.TeamMember, error) {
+	return r.memberships[subject], nil
+}
+
+type fakeSequenceRepository struct {
+	db.SequenceRepository
+	sequences map[int64]*models.Sequence
+	deleted   []int64
+}
+
+func (r *fakeSequenceRepository) Get(ctx context.Context, id int64) (*models.Sequence, error) {
+	if sequence, ok := r.sequences[id]; ok {
+		return sequence, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeSequenceRepository) SetActive(ctx context.Context, id int64, active bool, version int64) (int64, error) {
+	r.sequences[id].Active = active
+	return 2, nil
+}
+
+func (r *fakeSequenceRepository) Delete(ctx context.Context, id int64, version int64) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+type fakeStepRepository struct {
+	db.StepRepository
+	steps   map[int64]*models.Step
+	updated []int64
+}
+
+func (r *fakeStepRepository) Get(ctx context.Context, id int64) (*models.Step, error) {
+	if step, ok := r.steps[id]; ok {
+		return step, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeStepRepository) Update(ctx context.Context, step *models.Step) error {
+	r.updated = append(r.updated, step.ID)
+	return nil
+}
+
+func (r *fakeStepRepository) ListBySequenceID(ctx context.Context, sequenceID int64) ([]*models.Step, error) {
+	var steps []*models.Step
+	for _, step := range r.steps {
+		if step.SequenceID == sequenceID {
+			steps = append(steps, step)
+		}
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].StepOrder < steps[j].StepOrder })
+	return steps, nil
+}
+
+func (r *fakeStepRepository) List(ctx context.Context, sequenceID int64, opts models.ListOptions) ([]*models.Step, string, error) {
+	return []*models.Step{r.steps[1]}, "", nil
+}
+
+// Team 1 has a viewer, an editor and an owner; user "other" edits team 2.
+func testAuthorizer() Authorizer {
+	return NewAuthorizer(&fakeTeamRepository{memberships: map[string][]*models.TeamMember{
+		"viewer": {{TeamID: 1, Role: models.RoleViewer}},
+		"editor": {{TeamID: 1, Role: models.RoleEditor}},
+		"owner":  {{TeamID: 1, Role: models.RoleOwner}},
+		"other":  {{TeamID: 2, Role: models.RoleEditor}},
+	}})
+}
+
+func asUser(userID string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "user:" + userID, UserID: userID})
+}
+
+func isForbidden(err error) bool {
+	var forbidden *ForbiddenError
+	return errors.As(err, &forbidden)
+}
+
+func TestAuthorizer_Roles(t *testing.T) {
+	authorizer := testAuthorizer()
+	team := int64(1)
+
+	tests := []struct {
+		user    string
+		action  Action
+		teamID  *int64
+		allowed bool
+	}{
+		{"viewer", ActionRead, &team, true},
+		{"viewer", ActionEdit, &team, false},
+		{"editor", ActionEdit, &team, true},
+		{"editor", ActionManage, &team, false},
+		{"owner", ActionManage, &team, true},
+		{"other", ActionRead, &team, true},
+		{"other", ActionEdit, &team, false},
+		{"stranger", ActionRead, &team, false},
+		{"editor", ActionEdit, nil, false},
+		{"owner", ActionEdit, nil, true},
+	}
+	for _, tt := range tests {
+		err := authorizer.Authorize(asUser(tt.user), tt.action, tt.teamID)
+		if tt.allowed {
+			assert.NoError(t, err, "%s %s", tt.user, tt.action)
+		} else {
+			assert.True(t, isForbidden(err), "%s %s: got %v", tt.user, tt.action, err)
+		}
+	}
+}
+
+func TestAuthorizer_CallersWithoutUsers(t *testing.T) {
+	authorizer := testAuthorizer()
+	apiKey := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "api_key:1", APIKeyID: 1})
+	team := int64(1)
+
+	// Keys without a user only change sequences without a team.
+	assert.NoError(t, authorizer.Authorize(apiKey, ActionManage, nil))
+	assert.NoError(t, authorizer.Authorize(apiKey, ActionRead, &team))
+	assert.True(t, isForbidden(authorizer.Authorize(apiKey, ActionEdit, &team)))
+
+	// The command line is not restricted.
+	assert.NoError(t, authorizer.Authorize(context.Background(), ActionManage, &team))
+}
+
+func TestStepService_APIKeysActAsTheirUser(t *testing.T) {
+	team := int64(1)
+	sequences := &fakeSequenceRepository{sequences: map[int64]*models.Sequence{7: {ID: 7, TeamID: &team}}}
+	steps := &fakeStepRepository{steps: map[int64]*models.Step{1: {ID: 1, SequenceID: 7, Subject: "Hi", Content: "Hello"}}}
+	service := NewStepService(&fakeTransactor{}, steps, sequences, &fakeAuditRepository{}, testAuthorizer())
+	step := &models.Step{ID: 1, SequenceID: 7, Subject: "Welcome", Content: "Hello again"}
+	keyOf := func(userID string) context.Context {
+		return auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "api_key:1", APIKeyID: 1, UserID: userID})
+	}
+
+	// A key created by an editor of team 2 cannot write to team 1's sequence.
+	err := service.UpdateStep(keyOf("other"), step)
+	assert.True(t, isForbidden(err), "got %v", err)
+	assert.Empty(t, steps.updated)
+
+	assert.NoError(t, service.UpdateStep(keyOf("editor"), step))
+	assert.Equal(t, []int64{1}, steps.updated)
+}
+
+func TestStepService_EnforcesTeamRoles(t *testing.T) {
+	team := int64(1)
+	sequences := &fakeSequenceRepository{sequences: map[int64]*models.Sequence{7: {ID: 7, TeamID: &team}}}
+	steps := &fakeStepRepository{steps: map[int64]*models.Step{1: {ID: 1, SequenceID: 7, Subject: "Hi", Content: "Hello"}}}
+	service := NewStepService(&fakeTransactor{}, steps, sequences, &fakeAuditRepository{}, testAuthorizer())
+	step := &models.Step{ID: 1, SequenceID: 7, Subject: "Welcome", Content: "Hello again"}
+
+	_, _, err := service.ListSteps(asUser("viewer"), 7, models.ListOptions{})
+	assert.NoError(t, err)
+
+	for _, user := range []string{"viewer", "other"} {
+		err = service.UpdateStep(asUser(user), step)
+		assert.True(t, isForbidden(err), "%s: got %v", user, err)
+	}
+	assert.Empty(t, steps.updated)
+
+	assert.NoError(t, service.UpdateStep(asUser("editor"), step))
+	assert.Equal(t, []int64{1}, steps.updated)
+}
+
+func TestSequenceService_OnlyOwnersActivateAndDelete(t *testing.T) {
+	team := int64(1)
+	repo := &fakeSequenceRepository{sequences: map[int64]*models.Sequence{7: {ID: 7, TeamID: &team}}}
+	service := NewSequenceService(&fakeTransactor{}, repo, &fakeStepRepository{}, &fakeAuditRepository{}, &fakeHolidayRepository{}, testAuthorizer(), clock.System)
+
+	_, err := service.SetSequenceActive(asUser("editor"), 7, true, 0)
+	assert.True(t, isForbidden(err), "got %v", err)
+	assert.True(t, isForbidden(service.DeleteSequence(asUser("editor"), 7, 0)))
+	assert.False(t, repo.sequences[7].Active)
+	assert.Empty(t, repo.deleted)
+
+	_, err = service.SetSequenceActive(asUser("owner"), 7, true, 0)
+	assert.NoError(t, err)
+	assert.True(t, repo.sequences[7].Active)
+	assert.NoError(t, service.DeleteSequence(asUser("owner"), 7, 0))
+	assert.Equal(t, []int64{7}, repo.deleted)
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+)
+
+// BatchError reports the operation that made a batch fail. Nothing in the
+// batch has been applied.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("operation %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+type batchService struct {
+	tx        db.Transactor
+	sequences SequenceService
+	steps     StepService
+}
+
+// NewBatchService returns a service that applies batches through the
+// sequence and step services, so every operation gets their checks.
+func NewBatchService(tx db.Transactor, sequences SequenceService, steps StepService) BatchService {
+	return &batchService{tx: tx, sequences: sequences, steps: steps}
+}
+
+func (s *batchService) Execute(ctx context.Context, req *models.BatchRequest) ([]models.BatchResult, error) {
+	if len(req.Operations) == 0 {
+		return nil, &ValidationError{Message: "batch has no operations"}
+	}
+	if len(req.Operations) > models.MaxBatchOperations {
+		return nil, &ValidationError{Message: fmt.Sprintf("batch has more than %d operations", models.MaxBatchOperations)}
+	}
+
+	// Operations may read what earlier ones wrote, so the batch runs
+	// serializably; each attempt starts over with no results.
+	var results []models.BatchResult
+	err := s.tx.WithinTx(db.Serializable(ctx), func(ctx context.Context) error {
+		results = make([]models.BatchResult, 0, len(req.Operations))
+		for i, op := range req.Operations {
+			result, err := s.execute(ctx, op, results)
+			if err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+			result.Index = i
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// execute applies one operation. previous holds the results of the operations
+// before it, for resolving references.
+func (s *batchService) execute(ctx context.Context, op models.BatchOperation, previous []models.BatchResult) (models.BatchResult, error) {
+	result := models.BatchResult{Op: op.Op, Resource: op.Resource, ID: op.ID}
+	if op.Op != models.BatchOpCreate && op.ID <= 0 {
+		return result, &ValidationError{Message: op.Op + " requires an id"}
+	}
+
+	switch {
+	case op.Resource == models.BatchResourceSequence && op.Op == models.BatchOpCreate:
+		var sequence models.Sequence
+		if err := decodeBatchBody(op.Body, &sequence); err != nil {
+			return result, err
+		}
+		id, err := s.sequences.CreateSequence(ctx, &sequence)
+		result.ID = id
+		return result, err
+
+	case op.Resource == models.BatchResourceSequence && op.Op == models.BatchOpPatch:
+		sequence, err := s.sequences.PatchSequence(ctx, op.ID, op.Body, op.Version)
+		if err != nil {
+			return result, err
+		}
+		result.Version = sequence.Version
+		return result, nil
+
+	case op.Resource == models.BatchResourceStep && op.Op == models.BatchOpCreate:
+		var step models.Step
+		if err := decodeBatchBody(op.Body, &step); err != nil {
+			return result, err
+		}
+		if op.SequenceRef != nil {
+			sequenceID, err := resolveSequenceRef(*op.SequenceRef, previous)
+			if err != nil {
+				return result, err
+			}
+			step.SequenceID = sequenceID
+		}
+		id, err := s.steps.CreateStep(ctx, &step)
+		result.ID = id
+		return result, err
+
+	case op.Resource == models.BatchResourceStep && op.Op == models.BatchOpUpdate:
+		var step models.Step
+		if err := decodeBatchBody(op.Body, &step); err != nil {
+			return result, err
+		}
+		step.ID = op.ID
+		step.Version = op.Version
+		if err := s.steps.UpdateStep(ctx, &step); err != nil {
+			return result, err
+		}
+		result.Version = step.Version
+		return result, nil
+
+	case op.Resource == models.BatchResourceStep && op.Op == models.BatchOpPatch:
+		step, err := s.steps.PatchStep(ctx, op.ID, op.Body, op.Version)
+		if err != nil {
+			return result, err
+		}
+		result.Version = step.Version
+		return result, nil
+
+	case op.Resource == models.BatchResourceStep && op.Op == models.BatchOpDelete:
+		return result, s.steps.DeleteStep(ctx, op.ID, op.Version)
+	}
+
+	return result, &ValidationError{Message: fmt.Sprintf("unsupported operation %q on %q", op.Op, op.Resource)}
+}
+
+func decodeBatchBody(body json.RawMessage, v interface{}) error {
+	if len(body) == 0 {
+		return &ValidationError{Message: "operation requires a body"}
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return &ValidationError{Message: "invalid operation body: " + err.Error(), Err: err}
+	}
+	return nil
+}
+
+// resolveSequenceRef returns the ID of the sequence created by an earlier operation.
+func resolveSequenceRef(ref int, previous []models.BatchResult) (int64, error) {
+	if ref < 0 || ref >= len(previous) {
+		return 0, &ValidationError{Message: fmt.Sprintf("sequenceRef %d does not name an earlier operation", ref)}
+	}
+	target := previous[ref]
+	if target.Resource != models.BatchResourceSequence || target.Op != models.BatchOpCreate {
+		return 0, &ValidationError{Message: fmt.Sprintf("sequenceRef %d is not a sequence create", ref)}
+	}
+	return target.ID, nil
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"sf_test/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTransactor runs fn directly and records whether it was used.
+type fakeTransactor struct {
+	calls int
+}
+
+func (t *fakeTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.calls++
+	return fn(ctx)
+}
+
+type fakeSequenceService struct {
+	SequenceService
+	nextID int64
+}
+
+func (s *fakeSequenceService) CreateSequence(ctx context.Context, sequence *models.Sequence) (int64, error) {
+	s.nextID++
+	return s.nextID, nil
+}
+
+type fakeStepService struct {
+	StepService
+	created []models.Step
+	deleted []int64
+}
+
+func (s *fakeStepService) CreateStep(ctx context.Context, step *models.Step) (int64, error) {
+	s.created = append(s.created, *step)
+	return int64(100 + len(s.created)), nil
+}
+
+func (s *fakeStepService) UpdateStep(ctx context.Context, step *models.Step) error {
+	step.Version++
+	return nil
+}
+
+func (s *fakeStepService) DeleteStep(ctx context.Context, id int64, version int64) error {
+	if id == 404 {
+		return &NotFoundError{Resource: "step", ID: id}
+	}
+	s.deleted = append(s.deleted, id)
+	return nil
+}
+
+func batchOp(op, resource string, id int64, body string) models.BatchOperation {
+	operation := models.BatchOperation{Op: op, Resource: resource, ID: id}
+	if body != "" {
+		operation.Body = json.RawMessage(body)
+	}
+	return operation
+}
+
+func TestBatchService_ExecutesInOrderInOneTransaction(t *testing.T) {
+	tx := &fakeTransactor{}
+	steps := &fakeStepService{}
+	service := NewBatchService(tx, &fakeSequenceService{}, steps)
+
+	ref := 0
+	createStep := batchOp("create", "step", 0, `{"subject":"Welcome","content":"Hi"}`)
+	createStep.SequenceRef = &ref
+	update := batchOp("update", "step", 7, `{"subject":"Follow up","content":"Hello again"}`)
+	update.Version = 2
+
+	results, err := service.Execute(context.Background(), &models.BatchRequest{Operations: []models.BatchOperation{
+		batchOp("create", "sequence", 0, `{"name":"Onboarding"}`),
+		createStep,
+		update,
+		batchOp("delete", "step", 8, ""),
+	}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tx.calls)
+	assert.Len(t, results, 4)
+	assert.Equal(t, int64(1), results[0].ID)
+	assert.Equal(t, int64(101), results[1].ID)
+	assert.Equal(t, int64(1), steps.created[0].SequenceID)
+	assert.Equal(t, int64(3), results[2].Version)
+	assert.Equal(t, []int64{8}, steps.deleted)
+	assert.Equal(t, 3, results[3].Index)
+}
+
+func TestBatchService_ReportsFailedOperation(t *testing.T) {
+	service := NewBatchService(&fakeTransactor{}, &fakeSequenceService{}, &fakeStepService{})
+
+	_, err := service.Execute(context.Background(), &models.BatchRequest{Operations: []models.BatchOperation{
+		batchOp("delete", "step", 8, ""),
+		batchOp("delete", "step", 404, ""),
+	}})
+
+	var batchErr *BatchError
+	assert.True(t, errors.As(err, &batchErr))
+	assert.Equal(t, 1, batchErr.Index)
+	var notFound *NotFoundError
+	assert.True(t, errors.As(err, &notFound))
+}
+
+func TestBatchService_RejectsInvalidOperations(t *testing.T) {
+	ref := 0
+	badRef := batchOp("create", "step", 0, `{"subject":"Welcome","content":"Hi"}`)
+	badRef.SequenceRef = &ref
+
+	tests := map[string][]models.BatchOperation{
+		"empty":           {},
+		"unsupported":     {batchOp("delete", "sequence", 1, "")},
+		"missing id":      {batchOp("update", "step", 0, `{}`)},
+		"missing body":    {batchOp("create", "step", 0, "")},
+		"invalid body":    {batchOp("create", "step", 0, `{"waitDays":"two"}`)},
+		"ref not created": {batchOp("delete", "step", 8, ""), badRef},
+	}
+
+	for name, operations := range tests {
+		service := NewBatchService(&fakeTransactor{}, &fakeSequenceService{}, &fakeStepService{})
+		_, err := service.Execute(context.Background(), &models.BatchRequest{Operations: operations})
+
+		var validationErr *ValidationError
+		assert.True(t, errors.As(err, &validationErr), name)
+	}
+}
@@ -0,0 +1,367 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sf_test/internal/clock"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Contact fields a CSV column can be mapped to.
+const (
+	ContactFieldEmail     = "email"
+	ContactFieldFirstName = "firstName"
+	ContactFieldLastName  = "lastName"
+	ContactFieldCompany   = "company"
+	ContactFieldCountry   = "country"
+)
+
+// contactImportBatchSize is the number of rows written per transaction.
+const contactImportBatchSize = 500
+
+// ContactImportRequest describes a CSV upload to import as contacts.
+type ContactImportRequest struct {
+	CSV []byte
+	// Mapping maps CSV column headers to contact fields.
+	Mapping map[string]string
+	// SequenceID, when set, enrolls every imported contact into the sequence.
+	SequenceID int64
+}
+
+type contactService struct {
+	tx             db.Transactor
+	contactRepo    db.ContactRepository
+	enrollmentRepo db.EnrollmentRepository
+	sequenceRepo   db.SequenceRepository
+	jobRepo        db.JobRepository
+	holidayRepo    db.HolidayRepository
+	authorizer     Authorizer
+	clock          clock.Clock
+
+	// imports tracks running imports so Shutdown can wait for them, and
+	// stopImports is cancelled when they must stop early.
+	imports       sync.WaitGroup
+	stopImports   context.Context
+	cancelImports context.CancelFunc
+}
+
+func NewContactService(tx db.Transactor, contactRepo db.ContactRepository, enrollmentRepo db.EnrollmentRepository, sequenceRepo db.SequenceRepository, jobRepo db.JobRepository, holidayRepo db.HolidayRepository, authorizer Authorizer, clk clock.Clock) ContactService {
+	stopImports, cancelImports := context.WithCancel(context.Background())
+	return &contactService{
+		tx:             tx,
+		contactRepo:    contactRepo,
+		enrollmentRepo: enrollmentRepo,
+		sequenceRepo:   sequenceRepo,
+		jobRepo:        jobRepo,
+		holidayRepo:    holidayRepo,
+		authorizer:     authorizer,
+		clock:          clk,
+		stopImports:    stopImports,
+		cancelImports:  cancelImports,
+	}
+}
+
+func (s *contactService) ImportContacts(ctx context.Context, req *ContactImportRequest) (*models.Job, error) {
+	reader := csv.NewReader(bytes.NewReader(req.CSV))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, &ValidationError{Message: "csv file is empty"}
+		}
+		return nil, &ValidationError{Message: "invalid csv: " + err.Error(), Err: err}
+	}
+	columns, err := resolveContactColumns(header, req.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, &ValidationError{Message: "invalid csv: " + err.Error(), Err: err}
+	}
+
+	if req.SequenceID > 0 {
+		sequence, err := s.sequenceRepo.Get(ctx, req.SequenceID)
+		if err != nil {
+			if isNotFound(err) {
+				return nil, &ValidationError{
+					Message: "sequence not found",
+					Fields:  []FieldError{{Field: "sequenceId", Rule: "exists", Message: fmt.Sprintf("sequence %d does not exist", req.SequenceID)}},
+				}
+			}
+			return nil, err
+		}
+		// Enrolling contacts changes the sequence's audience.
+		if err := s.authorizer.Authorize(ctx, ActionEdit, sequence.TeamID); err != nil {
+			return nil, err
+		}
+	}
+
+	job := &models.Job{
+		Type:       models.JobTypeContactImport,
+		Status:     models.JobStatusPending,
+		TotalItems: len(rows),
+	}
+	job.ID, err = s.jobRepo.Create(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	// The import outlives the request, so it must not be cancelled with it,
+	// only by Shutdown.
+	importCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(s.stopImports, cancel)
+	s.imports.Add(1)
+	go func() {
+		defer s.imports.Done()
+		defer stop()
+		defer cancel()
+		s.runImport(importCtx, job, columns, rows, req.SequenceID)
+	}()
+
+	return job, nil
+}
+
+// Shutdown waits for running imports to finish. When ctx is done first, the
+// imports are stopped and their jobs marked failed, so none is left running.
+func (s *contactService) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.imports.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.cancelImports()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// resolveContactColumns maps each contact field to its column index in the header.
+func resolveContactColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	indexes := make(map[string]int)
+	for i, name := range header {
+		indexes[strings.TrimSpace(name)] = i
+	}
+
+	columns := make(map[string]int)
+	for column, field := range mapping {
+		switch field {
+		case ContactFieldEmail, ContactFieldFirstName, ContactFieldLastName, ContactFieldCompany, ContactFieldCountry:
+		default:
+			return nil, mappingError(fmt.Sprintf("unknown contact field %q", field))
+		}
+		index, ok := indexes[column]
+		if !ok {
+			return nil, mappingError(fmt.Sprintf("column %q not found in csv header", column))
+		}
+		if _, ok := columns[field]; ok {
+			return nil, mappingError(fmt.Sprintf("contact field %q is mapped more than once", field))
+		}
+		columns[field] = index
+	}
+	if _, ok := columns[ContactFieldEmail]; !ok {
+		return nil, mappingError("a column must be mapped to the email field")
+	}
+	return columns, nil
+}
+
+func mappingError(message string) error {
+	return &ValidationError{
+		Message: "invalid mapping",
+		Fields:  []FieldError{{Field: "mapping", Rule: "mapping", Message: message}},
+	}
+}
+
+func (s *contactService) runImport(ctx context.Context, job *models.Job, columns map[string]int, rows [][]string, sequenceID int64) {
+	job.Status = models.JobStatusRunning
+	if err := s.jobRepo.UpdateProgress(ctx, job); err != nil {
+		if ctx.Err() != nil {
+			s.finishJob(ctx, job, err)
+			return
+		}
+		log.Printf("Failed to start contact import job %d: %v", job.ID, err)
+		return
+	}
+
+	seen := make(map[string]bool)
+	for start := 0; start < len(rows); start += contactImportBatchSize {
+		end := start + contactImportBatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			return s.importBatch(ctx, job, columns, rows[start:end], start, seen, sequenceID)
+		})
+		if err != nil {
+			s.finishJob(ctx, job, err)
+			return
+		}
+	}
+	s.finishJob(ctx, job, nil)
+}
+
+// importBatch creates the contacts for one batch of rows and records its
+// progress in the same transaction. offset is the index of the first row.
+func (s *contactService) importBatch(ctx context.Context, job *models.Job, columns map[string]int, rows [][]string, offset int, seen map[string]bool, sequenceID int64) error {
+	var itemErrors []models.JobItemError
+	var contacts []*models.Contact
+	reject := func(i int, reason string) {
+		// Items are numbered as CSV records, with the header as record 1.
+		itemErrors = append(itemErrors, models.JobItemError{Item: offset + i + 2, Reason: reason, Data: rows[i]})
+	}
+
+	for i, row := range rows {
+		value := func(field string) string {
+			index, ok := columns[field]
+			if !ok || index >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[index])
+		}
+		email, err := models.NormalizeEmail(value(ContactFieldEmail))
+		if err != nil {
+			reject(i, err.Error())
+			continue
+		}
+		country, err := models.NormalizeCountry(value(ContactFieldCountry))
+		if err != nil {
+			reject(i, err.Error())
+			continue
+		}
+		if reason := checkContactLengths(value); reason != "" {
+			reject(i, reason)
+			continue
+		}
+		if seen[email] {
+			reject(i, "duplicate email in file")
+			continue
+		}
+		seen[email] = true
+		contacts = append(contacts, &models.Contact{
+			Email:     email,
+			FirstName: value(ContactFieldFirstName),
+			LastName:  value(ContactFieldLastName),
+			Company:   value(ContactFieldCompany),
+			Country:   country,
+		})
+	}
+
+	emails := make([]string, 0, len(contacts))
+	for _, contact := range contacts {
+		emails = append(emails, contact.Email)
+	}
+	existing, err := s.contactRepo.FindIDsByEmail(ctx, emails)
+	if err != nil {
+		return err
+	}
+
+	contactIDs := make([]int64, 0, len(contacts))
+	succeeded, skipped := 0, 0
+	for _, contact := range contacts {
+		if id, ok := existing[contact.Email]; ok {
+			contactIDs = append(contactIDs, id)
+			skipped++
+			continue
+		}
+		id, created, err := s.contactRepo.Create(ctx, contact)
+		if err != nil {
+			return err
+		}
+		contactIDs = append(contactIDs, id)
+		if created {
+			succeeded++
+		} else {
+			skipped++
+		}
+	}
+
+	if sequenceID > 0 && len(contactIDs) > 0 {
+		if err := s.enroll(ctx, sequenceID, contactIDs); err != nil {
+			return err
+		}
+	}
+	if len(itemErrors) > 0 {
+		if err := s.jobRepo.AddItemErrors(ctx, job.ID, itemErrors); err != nil {
+			return err
+		}
+	}
+
+	progress := *job
+	progress.ProcessedItems += len(rows)
+	progress.SucceededItems += succeeded
+	progress.SkippedItems += skipped
+	progress.FailedItems += len(itemErrors)
+	if err := s.jobRepo.UpdateProgress(ctx, &progress); err != nil {
+		return err
+	}
+	*job = progress
+	return nil
+}
+
+// checkContactLengths returns why a row's names or company are too long to
+// store, or an empty string when they fit.
+func checkContactLengths(value func(field string) string) string {
+	for _, field := range []string{ContactFieldFirstName, ContactFieldLastName, ContactFieldCompany} {
+		if utf8.RuneCountInString(value(field)) > models.ContactFieldMaxLength {
+			return fmt.Sprintf("%s must be at most %d characters", field, models.ContactFieldMaxLength)
+		}
+	}
+	return ""
+}
+
+// enroll enrolls the contacts into the sequence and schedules their first
+// step, keeping it off the holidays of each contact's country.
+func (s *contactService) enroll(ctx context.Context, sequenceID int64, contactIDs []int64) error {
+	sequence, err := s.sequenceRepo.Get(ctx, sequenceID)
+	if err != nil {
+		return err
+	}
+	var firstStepID int64
+	now := s.clock.Now()
+	dueAt := func(country string) (time.Time, error) { return now, nil }
+	if len(sequence.Steps) > 0 {
+		first := &sequence.Steps[0]
+		firstStepID = first.ID
+		dueAt = func(country string) (time.Time, error) {
+			cal, err := holidayCalendar(ctx, s.holidayRepo, country, time.UTC)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return stepDueAt(now, first, cal), nil
+		}
+	}
+	_, err = s.enrollmentRepo.EnrollContacts(ctx, sequenceID, contactIDs, firstStepID, dueAt)
+	return err
+}
+
+func (s *contactService) finishJob(ctx context.Context, job *models.Job, jobErr error) {
+	if jobErr != nil && ctx.Err() != nil {
+		// The import was stopped by Shutdown; its job must still be finished.
+		jobErr = errors.New("import was interrupted by a server shutdown")
+		ctx = context.WithoutCancel(ctx)
+	}
+	now := s.clock.Now()
+	job.FinishedAt = &now
+	job.Status = models.JobStatusCompleted
+	if jobErr != nil {
+		job.Status = models.JobStatusFailed
+		job.Error = jobErr.Error()
+	}
+	if err := s.jobRepo.UpdateProgress(ctx, job); err != nil {
+		log.Printf("Failed to finish contact import job %d: %v", job.ID, err)
+	}
+}
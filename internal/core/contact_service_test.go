@@ -0,0 +1,299 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sf_test/internal/clock"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+	"sf_test/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeContactRepository struct {
+	db.ContactRepository
+	mu       sync.Mutex
+	existing map[string]int64
+	// inserted holds contacts that a concurrent import creates after
+	// FindIDsByEmail has looked for them.
+	inserted map[string]int64
+	created  []*models.Contact
+	// block, when set, holds every Create until it is closed.
+	block chan struct{}
+}
+
+func (r *fakeContactRepository) Create(ctx context.Context, contact *models.Contact) (int64, bool, error) {
+	if r.block != nil {
+		select {
+		case <-r.block:
+		case <-ctx.Done():
+			return 0, false, ctx.Err()
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id, ok := r.inserted[contact.Email]; ok {
+		return id, false, nil
+	}
+	r.created = append(r.created, contact)
+	return int64(100 + len(r.created)), true, nil
+}
+
+func (r *fakeContactRepository) FindIDsByEmail(ctx context.Context, emails []string) (map[string]int64, error) {
+	found := make(map[string]int64)
+	for _, email := range emails {
+		if id, ok := r.existing[email]; ok {
+			found[email] = id
+		}
+	}
+	return found, nil
+}
+
+type fakeJobRepository struct {
+	db.JobRepository
+	mu         sync.Mutex
+	job        models.Job
+	itemErrors []models.JobItemError
+}
+
+func (r *fakeJobRepository) Create(ctx context.Context, job *models.Job) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.job = *job
+	r.job.ID = 1
+	return 1, nil
+}
+
+func (r *fakeJobRepository) UpdateProgress(ctx context.Context, job *models.Job) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.job = *job
+	return nil
+}
+
+func (r *fakeJobRepository) AddItemErrors(ctx context.Context, jobID int64, itemErrors []models.JobItemError) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.itemErrors = append(r.itemErrors, itemErrors...)
+	return nil
+}
+
+func (r *fakeJobRepository) current() models.Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.job
+}
+
+func newTestContactService(contacts *fakeContactRepository, jobs *fakeJobRepository) ContactService {
+	return NewContactService(&fakeTransactor{}, contacts, &fakeEnrollmentRepository{}, &fakeSequenceRepository{}, jobs, &fakeHolidayRepository{}, testAuthorizer(), clock.NewFake(sendStart))
+}
+
+var importRequest = &ContactImportRequest{
+	CSV:     []byte("Email\nada@example.com\ngrace@example.com\n"),
+	Mapping: map[string]string{"Email": ContactFieldEmail},
+}
+
+func TestContactService_ShutdownWaitsForImports(t *testing.T) {
+	contacts := &fakeContactRepository{block: make(chan struct{})}
+	jobs := &fakeJobRepository{}
+	service := newTestContactService(contacts, jobs)
+
+	_, err := service.ImportContacts(tenant.WithWorkspace(context.Background(), 1), importRequest)
+	assert.NoError(t, err)
+
+	stopped := make(chan error)
+	go func() { stopped <- service.Shutdown(context.Background()) }()
+	select {
+	case <-stopped:
+		t.Fatal("Shutdown returned while an import was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(contacts.block)
+	assert.NoError(t, <-stopped)
+	job := jobs.current()
+	assert.Equal(t, models.JobStatusCompleted, job.Status)
+	assert.Equal(t, 2, job.SucceededItems)
+}
+
+func TestContactService_ShutdownFailsUnfinishedImports(t *testing.T) {
+	contacts := &fakeContactRepository{block: make(chan struct{})}
+	jobs := &fakeJobRepository{}
+	service := newTestContactService(contacts, jobs)
+
+	_, err := service.ImportContacts(tenant.WithWorkspace(context.Background(), 1), importRequest)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, service.Shutdown(ctx), context.DeadlineExceeded)
+
+	job := jobs.current()
+	assert.Equal(t, models.JobStatusFailed, job.Status)
+	assert.Equal(t, "import was interrupted by a server shutdown", job.Error)
+	assert.NotNil(t, job.FinishedAt)
+	assert.Empty(t, contacts.created)
+}
+
+func TestResolveContactColumns(t *testing.T) {
+	header := []string{" Email ", "First", "Country"}
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		want    map[string]int
+	}{
+		{"trims header names", map[string]string{"Email": ContactFieldEmail, "Country": ContactFieldCountry}, map[string]int{ContactFieldEmail: 0, ContactFieldCountry: 2}},
+		{"unknown field", map[string]string{"Email": ContactFieldEmail, "First": "nickname"}, nil},
+		{"missing column", map[string]string{"Email": ContactFieldEmail, "Last": ContactFieldLastName}, nil},
+		{"field mapped twice", map[string]string{"Email": ContactFieldEmail, "First": ContactFieldEmail}, nil},
+		{"no email", map[string]string{"First": ContactFieldFirstName}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, err := resolveContactColumns(header, tt.mapping)
+			if tt.want == nil {
+				var validation *ValidationError
+				assert.ErrorAs(t, err, &validation)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, columns)
+		})
+	}
+}
+
+func TestContactService_ImportBatch(t *testing.T) {
+	columns := map[string]int{ContactFieldEmail: 0, ContactFieldFirstName: 1, ContactFieldCountry: 2}
+	tests := []struct {
+		name       string
+		rows       [][]string
+		offset     int
+		seen       map[string]bool
+		existing   map[string]int64
+		inserted   map[string]int64
+		created    []*models.Contact
+		itemErrors []models.JobItemError
+		succeeded  int
+		skipped    int
+	}{
+		{
+			name:      "normalizes fields",
+			rows:      [][]string{{" Ada@Example.COM ", " Ada ", "de"}},
+			created:   []*models.Contact{{Email: "ada@example.com", FirstName: "Ada", Country: "DE"}},
+			succeeded: 1,
+		},
+		{
+			name: "rejects invalid rows",
+			rows: [][]string{{"not an email", "Ada", ""}, {"grace@example.com", "Grace", "Germany"}, {""}},
+			itemErrors: []models.JobItemError{
+				{Item: 2, Reason: "invalid email address", Data: []string{"not an email", "Ada", ""}},
+				{Item: 3, Reason: "country must be an ISO 3166-1 alpha-2 code", Data: []string{"grace@example.com", "Grace", "Germany"}},
+				{Item: 4, Reason: "email is required", Data: []string{""}},
+			},
+		},
+		{
+			name:    "dedupes within the file",
+			rows:    [][]string{{"ada@example.com"}, {"ADA@example.com"}},
+			created: []*models.Contact{{Email: "ada@example.com"}},
+			itemErrors: []models.JobItemError{
+				{Item: 3, Reason: "duplicate email in file", Data: []string{"ADA@example.com"}},
+			},
+			succeeded: 1,
+		},
+		{
+			name:   "dedupes across batches",
+			rows:   [][]string{{"ada@example.com"}},
+			offset: contactImportBatchSize,
+			seen:   map[string]bool{"ada@example.com": true},
+			itemErrors: []models.JobItemError{
+				{Item: contactImportBatchSize + 2, Reason: "duplicate email in file", Data: []string{"ada@example.com"}},
+			},
+		},
+		{
+			name:      "skips existing contacts",
+			rows:      [][]string{{"ada@example.com"}, {"grace@example.com"}},
+			existing:  map[string]int64{"ada@example.com": 7},
+			created:   []*models.Contact{{Email: "grace@example.com"}},
+			succeeded: 1,
+			skipped:   1,
+		},
+		{
+			name:      "skips contacts created by a concurrent import",
+			rows:      [][]string{{"ada@example.com"}, {"grace@example.com"}},
+			inserted:  map[string]int64{"ada@example.com": 7},
+			created:   []*models.Contact{{Email: "grace@example.com"}},
+			succeeded: 1,
+			skipped:   1,
+		},
+		{
+			name:    "rejects values too long to store",
+			rows:    [][]string{{"ada@example.com", strings.Repeat("é", models.ContactFieldMaxLength+1)}, {"grace@example.com", strings.Repeat("é", models.ContactFieldMaxLength)}},
+			created: []*models.Contact{{Email: "grace@example.com", FirstName: strings.Repeat("é", models.ContactFieldMaxLength)}},
+			itemErrors: []models.JobItemError{
+				{Item: 2, Reason: "firstName must be at most 255 characters", Data: []string{"ada@example.com", strings.Repeat("é", models.ContactFieldMaxLength+1)}},
+			},
+			succeeded: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contacts := &fakeContactRepository{existing: tt.existing, inserted: tt.inserted}
+			jobs := &fakeJobRepository{}
+			service := newTestContactService(contacts, jobs).(*contactService)
+			job := &models.Job{ID: 1, Status: models.JobStatusRunning, ProcessedItems: tt.offset}
+			seen := tt.seen
+			if seen == nil {
+				seen = make(map[string]bool)
+			}
+
+			err := service.importBatch(context.Background(), job, columns, tt.rows, tt.offset, seen, 0)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.created, contacts.created)
+			assert.Equal(t, tt.itemErrors, jobs.itemErrors)
+			assert.Equal(t, tt.offset+len(tt.rows), job.ProcessedItems)
+			assert.Equal(t, tt.succeeded, job.SucceededItems)
+			assert.Equal(t, tt.skipped, job.SkippedItems)
+			assert.Equal(t, len(tt.itemErrors), job.FailedItems)
+			assert.Equal(t, *job, jobs.current(), "progress is recorded with the batch")
+		})
+	}
+}
+
+func TestContactService_ImportsInBatches(t *testing.T) {
+	contacts := &fakeContactRepository{}
+	jobs := &fakeJobRepository{}
+	tx := &fakeTransactor{}
+	service := NewContactService(tx, contacts, &fakeEnrollmentRepository{}, &fakeSequenceRepository{}, jobs, &fakeHolidayRepository{}, testAuthorizer(), clock.NewFake(sendStart))
+
+	csv := "email\n"
+	for i := 0; i < contactImportBatchSize; i++ {
+		csv += fmt.Sprintf("contact%d@example.com\n", i)
+	}
+	// The last row repeats the first and is the first row of the second batch.
+	csv += "contact0@example.com\n"
+
+	_, err := service.ImportContacts(tenant.WithWorkspace(context.Background(), 1), &ContactImportRequest{
+		CSV:     []byte(csv),
+		Mapping: map[string]string{"email": ContactFieldEmail},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, service.Shutdown(context.Background()))
+
+	assert.Equal(t, 2, tx.calls)
+	job := jobs.current()
+	assert.Equal(t, models.JobStatusCompleted, job.Status)
+	assert.Equal(t, contactImportBatchSize+1, job.ProcessedItems)
+	assert.Equal(t, contactImportBatchSize, job.SucceededItems)
+	assert.Equal(t, []models.JobItemError{
+		{Item: contactImportBatchSize + 2, Reason: "duplicate email in file", Data: []string{"contact0@example.com"}},
+	}, jobs.itemErrors)
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"sf_test/internal/calendar"
+	"sf_test/internal/clock"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+	"time"
+)
+
+type enrollmentService struct {
+	enrollmentRepo db.EnrollmentRepository
+	sendRepo       db.SendRepository
+	sequenceRepo   db.SequenceRepository
+	holidayRepo    db.HolidayRepository
+	authorizer     Authorizer
+	clock          clock.Clock
+}
+
+func NewEnrollmentService(enrollmentRepo db.EnrollmentRepository, sendRepo db.SendRepository, sequenceRepo db.SequenceRepository, holidayRepo db.HolidayRepository, authorizer Authorizer, clk clock.Clock) EnrollmentService {
+	return &enrollmentService{
+		enrollmentRepo: enrollmentRepo,
+		sendRepo:       sendRepo,
+		sequenceRepo:   sequenceRepo,
+		holidayRepo:    holidayRepo,
+		authorizer:     authorizer,
+		clock:          clk,
+	}
+}
+
+// GetTimeline shows the steps sent to the enrollment's contact with their
+// outcome, and when the remaining steps are planned to go out.
+func (s *enrollmentService) GetTimeline(ctx context.Context, id int64, loc *time.Location) (*models.Timeline, error) {
+	enrollment, err := s.enrollmentRepo.Get(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "enrollment", id)
+	}
+	sequence, err := authorizeSequence(ctx, s.authorizer, s.sequenceRepo, ActionRead, enrollment.SequenceID)
+	if err != nil {
+		return nil, err
+	}
+	sends, err := s.sendRepo.ListByEnrollment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := holidayCalendar(ctx, s.holidayRepo, enrollment.ContactCountry, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := &models.Timeline{
+		EnrollmentID: enrollment.ID,
+		SequenceID:   enrollment.SequenceID,
+		ContactID:    enrollment.ContactID,
+		Status:       enrollment.Status,
+		Country:      enrollment.ContactCountry,
+		Timezone:     loc.String(),
+		Steps:        planTimeline(sequence.Steps, sends, enrollment.CreatedAt, enrollment.Status == models.EnrollmentStatusActive, s.clock.Now(), cal, loc),
+	}
+	timeline.Warnings = timelineWarnings(timeline.Steps)
+	return timeline, nil
+}
+
+func (s *enrollmentService) PreviewTimeline(ctx context.Context, sequenceID int64, country string, loc *time.Location) (*models.Timeline, error) {
+	country, err := models.NormalizeCountry(country)
+	if err != nil {
+		return nil, &ValidationError{
+			Message: "invalid country",
+			Fields:  []FieldError{{Field: "country", Rule: "iso3166_1_alpha2", Message: err.Error()}},
+		}
+	}
+	sequence, err := authorizeSequence(ctx, s.authorizer, s.sequenceRepo, ActionRead, sequenceID)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := holidayCalendar(ctx, s.holidayRepo, country, loc)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	timeline := &models.Timeline{
+		SequenceID: sequenceID,
+		Country:    country,
+		Timezone:   loc.String(),
+		Steps:      planTimeline(sequence.Steps, nil, now, true, now, cal, loc),
+	}
+	timeline.Warnings = timelineWarnings(timeline.Steps)
+	return timeline, nil
+}
+
+// planTimeline lays out the steps of an enrollment made at enrolledAt, given
+// the sends scheduled for it so far. Like the send worker, it plans each step
+// from when the step before it went out, and sends nothing before now. An
+// inactive enrollment, or one whose send failed, has its remaining steps
+// unscheduled. Planned steps skip the holidays in cal, and their days are
+// counted in loc.
+func planTimeline(steps []models.Step, sends []*models.Send, enrolledAt time.Time, active bool, now time.Time, cal *calendar.Calendar, loc *time.Location) []models.TimelineStep {
+	sendsByStep := make(map[int64]*models.Send, len(sends))
+	for _, send := range sends {
+		sendsByStep[send.StepID] = send
+	}
+	at := func(t time.Time) *time.Time {
+		t = t.In(loc)
+		return &t
+	}
+
+	timeline := make([]models.TimelineStep, 0, len(steps))
+	previous := enrolledAt
+	for i := range steps {
+		step := &steps[i]
+		item := models.TimelineStep{StepID: step.ID, StepOrder: step.StepOrder, Subject: step.Subject, WaitDays: step.WaitDays, WaitUnit: step.EffectiveWaitUnit()}
+		send, scheduled := sendsByStep[step.ID]
+		if scheduled {
+			item.Status, item.Attempts, item.LastError = send.Status, send.Attempts, send.LastError
+		}
+		switch {
+		case scheduled && send.Status == models.SendStatusSent && send.SentAt != nil:
+			item.SentAt = at(*send.SentAt)
+			previous = *send.SentAt
+		case scheduled && send.Status == models.SendStatusPending && active:
+			previous = latest(send.DueAt, now)
+			item.PlannedAt = at(previous)
+		case scheduled && send.Status == models.SendStatusFailed:
+			active = false
+		case active:
+			previous = latest(stepDueAt(previous.In(loc), step, cal), now)
+			item.Status = models.TimelineStatusPlanned
+			item.PlannedAt = at(previous)
+		default:
+			item.Status = models.TimelineStatusUnscheduled
+		}
+		timeline = append(timeline, item)
+	}
+	return timeline
+}
+
+// timelineWarnings says what planned times leave out, if any step is planned.
+func timelineWarnings(steps []models.TimelineStep) []string {
+	for _, step := range steps {
+		if step.PlannedAt != nil {
+			return []string{"planned times assume each step is sent as soon as it is due: sending windows, mailbox limits and the send worker's polling interval are not taken into account"}
+		}
+	}
+	return []string{}
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
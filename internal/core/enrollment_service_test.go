@@ -0,0 +1,121 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"sf_test/internal/calendar"
+	"sf_test/internal/clock"
+	"sf_test/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var timelineSteps = []models.Step{
+	{ID: 1, StepOrder: 0, Subject: "Hello"},
+	{ID: 2, StepOrder: 1, Subject: "Follow up", WaitDays: 2},
+	{ID: 3, StepOrder: 2, Subject: "Last chance", WaitDays: 3},
+}
+
+func timeRef(t time.Time) *time.Time { return &t }
+
+func TestPlanTimeline(t *testing.T) {
+	sentAt := sendStart.Add(time.Hour)
+	now := sendStart.Add(26 * time.Hour)
+	sends := []*models.Send{
+		{StepID: 1, Status: models.SendStatusSent, SentAt: &sentAt, Attempts: 1},
+		{StepID: 2, Status: models.SendStatusPending, DueAt: sentAt.AddDate(0, 0, 2), Attempts: 1, LastError: "smtp unavailable"},
+	}
+	berlin := time.FixedZone("CET", 3600)
+
+	timeline := planTimeline(timelineSteps, sends, sendStart, true, now, nil, berlin)
+
+	assert.Equal(t, []models.TimelineStep{
+		{StepID: 1, StepOrder: 0, Subject: "Hello", WaitUnit: models.WaitUnitDays, Status: models.SendStatusSent, SentAt: timeRef(sentAt.In(berlin)), Attempts: 1},
+		{StepID: 2, StepOrder: 1, Subject: "Follow up", WaitDays: 2, WaitUnit: models.WaitUnitDays, Status: models.SendStatusPending, PlannedAt: timeRef(sentAt.AddDate(0, 0, 2).In(berlin)), Attempts: 1, LastError: "smtp unavailable"},
+		{StepID: 3, StepOrder: 2, Subject: "Last chance", WaitDays: 3, WaitUnit: models.WaitUnitDays, Status: models.TimelineStatusPlanned, PlannedAt: timeRef(sentAt.AddDate(0, 0, 5).In(berlin))},
+	}, timeline)
+}
+
+func TestPlanTimeline_SkipsWeekendsAndHolidays(t *testing.T) {
+	steps := []models.Step{
+		{ID: 1, StepOrder: 0, Subject: "Hello"},
+		{ID: 2, StepOrder: 1, Subject: "Follow up", WaitDays: 5, WaitUnit: models.WaitUnitBusinessDays},
+		{ID: 3, StepOrder: 2, Subject: "Last chance", WaitDays: 48, WaitUnit: models.WaitUnitHours},
+	}
+	// Wednesday of the second week is a holiday.
+	cal := calendar.New([]*models.Holiday{holiday("", sendStart.AddDate(0, 0, 9))}, time.UTC)
+
+	timeline := planTimeline(steps, nil, sendStart, true, sendStart, cal, time.UTC)
+
+	assert.Equal(t, sendStart, *timeline[0].PlannedAt)
+	assert.Equal(t, sendStart.AddDate(0, 0, 7), *timeline[1].PlannedAt, "five business days after a Monday is the next Monday")
+	assert.Equal(t, sendStart.AddDate(0, 0, 10), *timeline[2].PlannedAt, "48 hours later is the holiday, so the day after")
+}
+
+func TestPlanTimeline_CountsDaysInTimezone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := []models.Step{
+		{ID: 1, StepOrder: 0, Subject: "Hello"},
+		{ID: 2, StepOrder: 1, Subject: "Follow up", WaitDays: 1, WaitUnit: models.WaitUnitBusinessDays},
+	}
+	// Thursday 20:00 UTC is already Friday in Tokyo, so the next business
+	// day there is Monday.
+	thursday := sendStart.AddDate(0, 0, 3).Add(11 * time.Hour)
+	cal := calendar.New(nil, tokyo)
+
+	timeline := planTimeline(steps, nil, thursday, true, thursday, cal, tokyo)
+	assert.Equal(t, thursday.AddDate(0, 0, 3).In(tokyo), *timeline[1].PlannedAt)
+}
+
+func TestPlanTimeline_OverdueAndFailed(t *testing.T) {
+	now := sendStart.AddDate(0, 0, 10)
+
+	// Sends that are overdue, say because the sequence was inactive, are
+	// planned from now.
+	timeline := planTimeline(timelineSteps, nil, sendStart, true, now, nil, time.UTC)
+	assert.Equal(t, now, *timeline[0].PlannedAt)
+	assert.Equal(t, now.AddDate(0, 0, 2), *timeline[1].PlannedAt)
+
+	// Nothing follows a failed send.
+	failed := []*models.Send{{StepID: 1, Status: models.SendStatusFailed, Attempts: 5, LastError: "mailbox full"}}
+	timeline = planTimeline(timelineSteps, failed, sendStart, true, now, nil, time.UTC)
+	assert.Equal(t, models.SendStatusFailed, timeline[0].Status)
+	assert.Nil(t, timeline[0].PlannedAt)
+	assert.Equal(t, models.TimelineStatusUnscheduled, timeline[1].Status)
+	assert.Equal(t, models.TimelineStatusUnscheduled, timeline[2].Status)
+}
+
+func TestEnrollmentService_GetTimeline(t *testing.T) {
+	team := int64(1)
+	sequences := &fakeSequenceRepository{sequences: map[int64]*models.Sequence{7: {ID: 7, TeamID: &team, Steps: timelineSteps}}}
+	enrollments := &fakeEnrollmentRepository{enrollments: map[int64]*models.Enrollment{
+		3: {ID: 3, SequenceID: 7, ContactID: 9, Status: models.EnrollmentStatusActive, CreatedAt: sendStart},
+	}}
+	sends := &fakeSendRepository{sends: map[int64]*models.Send{
+		1: {ID: 1, EnrollmentID: 3, StepID: 1, Status: models.SendStatusPending, DueAt: sendStart},
+	}}
+	service := NewEnrollmentService(enrollments, sends, sequences, &fakeHolidayRepository{}, testAuthorizer(), clock.NewFake(sendStart))
+
+	timeline, err := service.GetTimeline(asUser("viewer"), 3, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), timeline.ContactID)
+	assert.Equal(t, "UTC", timeline.Timezone)
+	assert.Len(t, timeline.Steps, 3)
+	assert.Equal(t, sendStart.AddDate(0, 0, 5), *timeline.Steps[2].PlannedAt)
+	assert.Len(t, timeline.Warnings, 1, "planned times come with what they leave out")
+
+	_, err = service.GetTimeline(asUser("viewer"), 4, time.UTC)
+	var notFound *NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+	_, err = service.GetTimeline(asUser("outsider"), 3, time.UTC)
+	assert.True(t, isForbidden(err))
+
+	preview, err := service.PreviewTimeline(asUser("viewer"), 7, "", time.UTC)
+	assert.NoError(t, err)
+	assert.Zero(t, preview.EnrollmentID)
+	assert.Equal(t, sendStart, *preview.Steps[0].PlannedAt)
+}
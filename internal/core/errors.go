@@ -0,0 +1,150 @@
+package core
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// NotFoundError is returned when a requested resource does not exist.
+type NotFoundError struct {
+	Resource string
+	ID       int64
+}
+
+func (e *NotFoundError) Error() string {
+	if e.ID == 0 {
+		return e.Resource + " not found"
+	}
+	return fmt.Sprintf("%s %d not found", e.Resource, e.ID)
+}
+
+// FieldError describes why a single field failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when input is rejected. Fields is set when the
+// failure can be attributed to specific fields.
+type ValidationError struct {
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ConflictError is returned when a change clashes with the current state,
+// such as a duplicate unique key.
+type ConflictError struct {
+	Message string
+	Err     error
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// PreconditionFailedError is returned when a conditional request no longer
+// matches the stored resource.
+type PreconditionFailedError struct {
+	Message string
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return e.Message
+}
+
+// UnauthenticatedError is returned when a request carries missing, invalid
+// or revoked credentials.
+type UnauthenticatedError struct {
+	Message string
+}
+
+func (e *UnauthenticatedError) Error() string {
+	return e.Message
+}
+
+// ForbiddenError is returned when the caller is authenticated but not
+// allowed to perform the operation.
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
+
+// newValidationError wraps err, expanding validator errors into per-field details.
+func newValidationError(err error) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return &ValidationError{Message: err.Error(), Err: err}
+	}
+
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		fields = append(fields, FieldError{
+			Field:   fieldError.Namespace(),
+			Rule:    fieldError.Tag(),
+			Message: fieldErrorMessage(fieldError),
+		})
+	}
+	return &ValidationError{Message: "validation failed", Fields: fields, Err: err}
+}
+
+func fieldErrorMessage(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return fieldError.Field() + " is required"
+	case "min":
+		return fmt.Sprintf("%s must be at least %s characters", fieldError.Field(), fieldError.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s characters", fieldError.Field(), fieldError.Param())
+	case "gte":
+		return fmt.Sprintf("%s must be greater than or equal to %s", fieldError.Field(), fieldError.Param())
+	}
+	return fmt.Sprintf("%s failed the %s rule", fieldError.Field(), fieldError.Tag())
+}
+
+// translateError converts repository errors into domain errors for the given
+// resource. Errors it does not recognise are returned unchanged.
+func translateError(err error, resource string, id int64) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, db.ErrNotFound):
+		return &NotFoundError{Resource: resource, ID: id}
+	case errors.Is(err, db.ErrVersionMismatch):
+		return &PreconditionFailedError{Message: fmt.Sprintf("%s %d has been modified since version was read", resource, id)}
+	case errors.Is(err, models.ErrInvalidCursor), errors.Is(err, models.ErrInvalidSort):
+		return &ValidationError{Message: err.Error(), Err: err}
+	case db.IsUniqueViolation(err):
+		return &ConflictError{Message: resource + " already exists", Err: err}
+	case db.IsForeignKeyViolation(err):
+		return &ValidationError{Message: resource + " references a resource that does not exist", Err: err}
+	}
+	return err
+}
+
+// isNotFound reports whether err means the requested row does not exist.
+func isNotFound(err error) bool {
+	var notFound *NotFoundError
+	return errors.Is(err, sql.ErrNoRows) || errors.Is(err, db.ErrNotFound) || errors.As(err, &notFound)
+}
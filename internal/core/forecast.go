@@ -0,0 +1,112 @@
+package core
+
+import (
+	"fmt"
+	"sf_test/internal/calendar"
+	"sf_test/internal/models"
+	"sort"
+	"time"
+)
+
+// forecastHorizon is how many days a forecast simulates at most.
+const forecastHorizon = 366
+
+// forecastCohort is a number of contacts due to be sent the same step on the
+// same day.
+type forecastCohort struct {
+	step  int
+	due   time.Time
+	count int
+}
+
+// forecastSends simulates the scheduler sending steps, in order, to
+// contactCount contacts enrolled on start, with up to capacity sends a day.
+// Like the send worker, it sends the earliest due sends first and schedules
+// each next step from the day the previous one went out, so sends held back
+// by the capacity push the rest of the contact's steps back too. Nothing is
+// sent on the holidays in cal.
+func forecastSends(steps []models.Step, contactCount int, start time.Time, capacity int, cal *calendar.Calendar) *models.Forecast {
+	forecast := &models.Forecast{
+		StartDate:     start.Format(models.ForecastDateLayout),
+		DailyCapacity: capacity,
+		Days:          []models.ForecastDay{},
+		Warnings:      []string{},
+	}
+	pending := []forecastCohort{{step: 0, due: stepDueAt(start, &steps[0], cal), count: contactCount}}
+	schedule := func(cohort forecastCohort) {
+		for i := range pending {
+			if pending[i].step == cohort.step && pending[i].due.Equal(cohort.due) {
+				pending[i].count += cohort.count
+				return
+			}
+		}
+		pending = append(pending, cohort)
+		sort.SliceStable(pending, func(i, j int) bool {
+			if !pending[i].due.Equal(pending[j].due) {
+				return pending[i].due.Before(pending[j].due)
+			}
+			return pending[i].step < pending[j].step
+		})
+	}
+
+	total, delayed, maxDelay := 0, 0, 0
+	day := start
+	for ; len(pending) > 0 && day.Before(start.AddDate(0, 0, forecastHorizon)); day = day.AddDate(0, 0, 1) {
+		if cal.IsHoliday(day) {
+			continue
+		}
+		sent := make([]int, len(steps))
+		remaining := capacity
+		for remaining > 0 && len(pending) > 0 && pending[0].due.Before(day.AddDate(0, 0, 1)) {
+			cohort := &pending[0]
+			n := min(cohort.count, remaining)
+			remaining -= n
+			cohort.count -= n
+			sent[cohort.step] += n
+			total += n
+			if delay := int(day.Sub(cohort.due.Truncate(24*time.Hour)).Hours() / 24); delay > 0 {
+				delayed += n
+				maxDelay = max(maxDelay, delay)
+			}
+			step := cohort.step
+			if cohort.count == 0 {
+				pending = pending[1:]
+			}
+			if step+1 < len(steps) {
+				schedule(forecastCohort{step: step + 1, due: stepDueAt(day, &steps[step+1], cal), count: n})
+			} else {
+				forecast.FinishDate = day.Format(models.ForecastDateLayout)
+			}
+		}
+
+		if remaining == capacity {
+			continue
+		}
+		forecastDay := models.ForecastDay{Date: day.Format(models.ForecastDateLayout), Total: capacity - remaining}
+		for i, n := range sent {
+			if n > 0 {
+				forecastDay.Steps = append(forecastDay.Steps, models.ForecastStepSends{StepID: steps[i].ID, StepOrder: steps[i].StepOrder, Sends: n})
+			}
+		}
+		forecast.Days = append(forecast.Days, forecastDay)
+	}
+
+	if delayed > 0 {
+		unit := "days"
+		if maxDelay == 1 {
+			unit = "day"
+		}
+		forecast.Warnings = append(forecast.Warnings, fmt.Sprintf(
+			"the audience exceeds the daily capacity of %d sends: %d of %d sends go out late, by up to %d %s", capacity, delayed, total, maxDelay, unit))
+	}
+	if len(pending) > 0 {
+		unfinished := 0
+		for _, cohort := range pending {
+			unfinished += cohort.count
+		}
+		forecast.FinishDate = ""
+		forecast.Warnings = append(forecast.Warnings, fmt.Sprintf(
+			"%d contacts have not finished the sequence after %d days", unfinished, forecastHorizon))
+	}
+	return forecast
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"sf_test/internal/calendar"
+	"sf_test/internal/clock"
+	"sf_test/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var forecastSteps = []models.Step{
+	{ID: 1, StepOrder: 0},
+	{ID: 2, StepOrder: 1, WaitDays: 2},
+	{ID: 3, StepOrder: 2, WaitDays: 3},
+}
+
+func TestForecastSends_WithinCapacity(t *testing.T) {
+	forecast := forecastSends(forecastSteps, 100, sendStart, 500, nil)
+
+	assert.Equal(t, []models.ForecastDay{
+		{Date: "2024-03-04", Total: 100, Steps: []models.ForecastStepSends{{StepID: 1, StepOrder: 0, Sends: 100}}},
+		{Date: "2024-03-06", Total: 100, Steps: []models.ForecastStepSends{{StepID: 2, StepOrder: 1, Sends: 100}}},
+		{Date: "2024-03-09", Total: 100, Steps: []models.ForecastStepSends{{StepID: 3, StepOrder: 2, Sends: 100}}},
+	}, forecast.Days)
+	assert.Equal(t, "2024-03-09", forecast.FinishDate)
+	assert.Empty(t, forecast.Warnings)
+}
+
+func TestForecastSends_WaitUnitsAndHolidays(t *testing.T) {
+	cal := calendar.New([]*models.Holiday{holiday("", sendStart.AddDate(0, 0, 2))}, time.UTC)
+	forecast := forecastSends(forecastSteps, 100, sendStart, 500, cal)
+
+	assert.Equal(t, []string{"2024-03-04", "2024-03-07", "2024-03-10"}, forecastDates(forecast))
+
+	hourly := []models.Step{{ID: 1, StepOrder: 0}, {ID: 2, StepOrder: 1, WaitDays: 4, WaitUnit: models.WaitUnitHours}}
+	forecast = forecastSends(hourly, 10, sendStart, 500, nil)
+
+	assert.Equal(t, []models.ForecastDay{
+		{Date: "2024-03-04", Total: 20, Steps: []models.ForecastStepSends{{StepID: 1, StepOrder: 0, Sends: 10}, {StepID: 2, StepOrder: 1, Sends: 10}}},
+	}, forecast.Days, "steps waiting hours go out the same day")
+}
+
+func forecastDates(forecast *models.Forecast) []string {
+	var dates []string
+	for _, day := range forecast.Days {
+		dates = append(dates, day.Date)
+	}
+	return dates
+}
+
+func TestForecastSends_OverCapacity(t *testing.T) {
+	forecast := forecastSends(forecastSteps, 100, sendStart, 40, nil)
+
+	// Contacts held back on the first day fall behind for the whole sequence,
+	// and earlier due sends go first.
+	assert.Equal(t, []models.ForecastDay{
+		{Date: "2024-03-04", Total: 40, Steps: []models.ForecastStepSends{{StepID: 1, StepOrder: 0, Sends: 40}}},
+		{Date: "2024-03-05", Total: 40, Steps: []models.ForecastStepSends{{StepID: 1, StepOrder: 0, Sends: 40}}},
+		{Date: "2024-03-06", Total: 40, Steps: []models.ForecastStepSends{{StepID: 1, StepOrder: 0, Sends: 20}, {StepID: 2, StepOrder: 1, Sends: 20}}},
+		{Date: "2024-03-07", Total: 40, Steps: []models.ForecastStepSends{{StepID: 2, StepOrder: 1, Sends: 40}}},
+		{Date: "2024-03-08", Total: 40, Steps: []models.ForecastStepSends{{StepID: 2, StepOrder: 1, Sends: 40}}},
+		{Date: "2024-03-09", Total: 20, Steps: []models.ForecastStepSends{{StepID: 3, StepOrder: 2, Sends: 20}}},
+		{Date: "2024-03-10", Total: 40, Steps: []models.ForecastStepSends{{StepID: 3, StepOrder: 2, Sends: 40}}},
+		{Date: "2024-03-11", Total: 40, Steps: []models.ForecastStepSends{{StepID: 3, StepOrder: 2, Sends: 40}}},
+	}, forecast.Days)
+	assert.Equal(t, "2024-03-11", forecast.FinishDate)
+	assert.Len(t, forecast.Warnings, 1)
+	assert.Contains(t, forecast.Warnings[0], "exceeds the daily capacity of 40 sends")
+}
+
+func TestForecastSends_BeyondHorizon(t *testing.T) {
+	forecast := forecastSends(forecastSteps[:1], 1000, sendStart, 1, nil)
+
+	assert.Len(t, forecast.Days, forecastHorizon)
+	assert.Empty(t, forecast.FinishDate)
+	assert.Len(t, forecast.Warnings, 2)
+	assert.Contains(t, forecast.Warnings[1], "634 contacts have not finished")
+}
+
+func TestSequenceService_ForecastSequence(t *testing.T) {
+	repo := &fakeSequenceRepository{sequences: map[int64]*models.Sequence{
+		7: {ID: 7, Steps: forecastSteps},
+		8: {ID: 8},
+	}}
+	service := NewSequenceService(&fakeTransactor{}, repo, &fakeStepRepository{}, &fakeAuditRepository{}, &fakeHolidayRepository{}, testAuthorizer(), clock.NewFake(sendStart.Add(5*time.Hour)))
+	req := &models.ForecastRequest{
+		ContactCount: 60,
+		Mailboxes:    []models.ForecastMailbox{{Email: "a@example.com", DailyLimit: 20}, {Email: "b@example.com", DailyLimit: 30}},
+	}
+
+	forecast, err := service.ForecastSequence(asUser("viewer"), 7, req)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), forecast.SequenceID)
+	assert.Equal(t, "2024-03-04", forecast.StartDate, "defaults to today")
+	assert.Equal(t, 50, forecast.DailyCapacity)
+	assert.Equal(t, "2024-03-10", forecast.FinishDate)
+
+	var validationErr *ValidationError
+	_, err = service.ForecastSequence(asUser("viewer"), 8, req)
+	assert.True(t, errors.As(err, &validationErr), "a sequence without steps cannot be forecast")
+	_, err = service.ForecastSequence(asUser("viewer"), 7, &models.ForecastRequest{ContactCount: 60})
+	assert.True(t, errors.As(err, &validationErr), "mailboxes are required")
+}
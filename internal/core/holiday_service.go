@@ -0,0 +1,48 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"sf_test/internal/calendar"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+)
+
+type holidayService struct {
+	holidayRepo db.HolidayRepository
+	authorizer  Authorizer
+}
+
+func NewHolidayService(holidayRepo db.HolidayRepository, authorizer Authorizer) HolidayService {
+	return &holidayService{holidayRepo: holidayRepo, authorizer: authorizer}
+}
+
+// ImportHolidays changes when every sequence of the workspace sends, so only
+// users who may manage it can import holidays.
+func (s *holidayService) ImportHolidays(ctx context.Context, country string, ics []byte) (*models.HolidayImportResult, error) {
+	country, err := models.NormalizeCountry(country)
+	if err != nil {
+		return nil, &ValidationError{
+			Message: "invalid country",
+			Fields:  []FieldError{{Field: "country", Rule: "iso3166_1_alpha2", Message: err.Error()}},
+		}
+	}
+	holidays, err := calendar.ParseICal(bytes.NewReader(ics))
+	if err != nil {
+		return nil, &ValidationError{Message: "invalid iCalendar file: " + err.Error(), Err: err}
+	}
+	if err := s.authorizer.Authorize(ctx, ActionManage, nil); err != nil {
+		return nil, err
+	}
+	if err := s.holidayRepo.Replace(ctx, country, holidays); err != nil {
+		return nil, err
+	}
+	return &models.HolidayImportResult{Country: country, Imported: len(holidays)}, nil
+}
+
+func (s *holidayService) ListHolidays(ctx context.Context) ([]*models.Holiday, error) {
+	if err := s.authorizer.Authorize(ctx, ActionRead, nil); err != nil {
+		return nil, err
+	}
+	return s.holidayRepo.List(ctx)
+}
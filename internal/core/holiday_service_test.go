@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"sf_test/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeHolidayRepository struct {
+	holidays []*models.Holiday
+}
+
+func (r *fakeHolidayRepository) Replace(ctx context.Context, country string, holidays []*models.Holiday) error {
+	kept := r.holidays[:0]
+	for _, holiday := range r.holidays {
+		if holiday.Country != country {
+			kept = append(kept, holiday)
+		}
+	}
+	for _, holiday := range holidays {
+		holiday.Country = country
+		kept = append(kept, holiday)
+	}
+	r.holidays = kept
+	return nil
+}
+
+func (r *fakeHolidayRepository) List(ctx context.Context) ([]*models.Holiday, error) {
+	return r.holidays, nil
+}
+
+func (r *fakeHolidayRepository) ListForCountry(ctx context.Context, country string) ([]*models.Holiday, error) {
+	var holidays []*models.Holiday
+	for _, holiday := range r.holidays {
+		if holiday.Country == "" || holiday.Country == country {
+			holidays = append(holidays, holiday)
+		}
+	}
+	return holidays, nil
+}
+
+// holiday returns a holiday on the day of t.
+func holiday(country string, t time.Time) *models.Holiday {
+	return &models.Holiday{Country: country, Date: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+func TestHolidayService_ImportHolidays(t *testing.T) {
+	repo := &fakeHolidayRepository{holidays: []*models.Holiday{holiday("DE", sendStart), holiday("", sendStart)}}
+	service := NewHolidayService(repo, testAuthorizer())
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20241003",
+		"SUMMARY:Tag der Deutschen Einheit",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	result, err := service.ImportHolidays(context.Background(), " de ", []byte(ics))
+	assert.NoError(t, err)
+	assert.Equal(t, &models.HolidayImportResult{Country: "DE", Imported: 1}, result)
+	holidays, err := service.ListHolidays(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, holidays, 2, "the country's holidays are replaced") {
+		assert.Equal(t, "", holidays[0].Country)
+		assert.Equal(t, "Tag der Deutschen Einheit", holidays[1].Name)
+	}
+
+	var validationErr *ValidationError
+	_, err = service.ImportHolidays(context.Background(), "Germany", []byte(ics))
+	assert.ErrorAs(t, err, &validationErr)
+	_, err = service.ImportHolidays(context.Background(), "", []byte("not a calendar"))
+	assert.ErrorAs(t, err, &validationErr)
+	_, err = service.ImportHolidays(asUser("viewer"), "", []byte(ics))
+	assert.True(t, isForbidden(err))
+}
+
+func TestStepDueAt(t *testing.T) {
+	// The Friday after sendStart is a holiday.
+	friday := sendStart.AddDate(0, 0, 4)
+	cal, err := holidayCalendar(context.Background(), &fakeHolidayRepository{holidays: []*models.Holiday{holiday("", friday)}}, "", time.UTC)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name string
+		step models.Step
+		want time.Time
+	}{
+		{"calendar days", models.Step{WaitDays: 2}, sendStart.AddDate(0, 0, 2)},
+		{"calendar days onto a holiday", models.Step{WaitDays: 4}, sendStart.AddDate(0, 0, 5)},
+		{"business days", models.Step{WaitDays: 4, WaitUnit: models.WaitUnitBusinessDays}, sendStart.AddDate(0, 0, 7)},
+		{"hours", models.Step{WaitDays: 30, WaitUnit: models.WaitUnitHours}, sendStart.Add(30 * time.Hour)},
+		{"hours onto a holiday", models.Step{WaitDays: 96, WaitUnit: models.WaitUnitHours}, sendStart.AddDate(0, 0, 5)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, stepDueAt(sendStart, &tt.step, cal))
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+	"time"
+)
+
+// ErrIdempotencyKeyReused is returned when an idempotency key is replayed
+// with a different request body than the one it was first used with.
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request body")
+
+// idempotencyLockTimeout is how long an unfinished request holds its key.
+// After that the request is assumed lost and a retry may take the key over.
+const idempotencyLockTimeout = time.Minute
+
+const maxIdempotencyKeyLength = 255
+
+type idempotencyService struct {
+	repo db.IdempotencyRepository
+	ttl  time.Duration
+}
+
+// NewIdempotencyService returns a service that keeps responses for ttl.
+func NewIdempotencyService(repo db.IdempotencyRepository, ttl time.Duration) IdempotencyService {
+	return &idempotencyService{repo: repo, ttl: ttl}
+}
+
+func (s *idempotencyService) Begin(ctx context.Context, scope, key, requestHash string) (*models.IdempotencyRecord, error) {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return nil, &ValidationError{
+			Message: "invalid idempotency key",
+			Fields:  []FieldError{{Field: "Idempotency-Key", Rule: "max", Message: "Idempotency-Key must be between 1 and 255 characters"}},
+		}
+	}
+
+	record := &models.IdempotencyRecord{Scope: scope, Key: key, RequestHash: requestHash}
+	reserved, err := s.repo.Reserve(ctx, record, s.ttl, idempotencyLockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	existing, err := s.repo.Get(ctx, scope, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The record expired between the two queries.
+		return nil, &ConflictError{Message: "a request with this idempotency key is in progress"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if existing.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if !existing.Completed() {
+		return nil, &ConflictError{Message: "a request with this idempotency key is in progress"}
+	}
+	return existing, nil
+}
+
+func (s *idempotencyService) Complete(ctx context.Context, record *models.IdempotencyRecord) error {
+	return s.repo.Complete(ctx, record)
+}
+
+func (s *idempotencyService) Release(ctx context.Context, scope, key string) error {
+	return s.repo.Delete(ctx, scope, key)
+}
+
+func (s *idempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpired(ctx)
+}
@@ -2,20 +2,132 @@ package core
 
 import (
 	"context"
+	"sf_test/internal/auth"
 	"sf_test/internal/models"
+	"time"
 )
 
 // SequenceService defines the interface for sequence-related operations.
 type SequenceService interface {
 	CreateSequence(ctx context.Context, sequence *models.Sequence) (int64, error)
-	UpdateTracking(ctx context.Context, id int64, openTracking, clickTracking bool) error
+	UpdateTracking(ctx context.Context, id int64, openTracking, clickTracking bool, version int64) (int64, error)
 	GetSequence(ctx context.Context, id int64) (*models.Sequence, error)
+	PatchSequence(ctx context.Context, id int64, patch []byte, version int64) (*models.Sequence, error)
+	ExportSequence(ctx context.Context, id int64) (*models.SequenceBundle, error)
+	ImportSequence(ctx context.Context, bundle *models.SequenceBundle, dryRun bool) (*models.ImportResult, error)
+	ForecastSequence(ctx context.Context, id int64, req *models.ForecastRequest) (*models.Forecast, error)
+	ListSequences(ctx context.Context, opts models.ListOptions) ([]*models.Sequence, string, error)
+	SetSequenceActive(ctx context.Context, id int64, active bool, version int64) (int64, error)
+	DeleteSequence(ctx context.Context, id int64, version int64) error
 }
 
 // StepService defines the interface for step-related operations.
 type StepService interface {
 	CreateStep(ctx context.Context, step *models.Step) (int64, error)
 	UpdateStep(ctx context.Context, step *models.Step) error
-	DeleteStep(ctx context.Context, id int64) error
-	ListSteps(ctx context.Context, sequenceID int64) ([]*models.Step, error)
+	PatchStep(ctx context.Context, id int64, patch []byte, version int64) (*models.Step, error)
+	DeleteStep(ctx context.Context, id int64, version int64) error
+	ListSteps(ctx context.Context, sequenceID int64, opts models.ListOptions) ([]*models.Step, string, error)
+}
+
+// SequencePlanner reconciles declarative sequence definitions with the database.
+type SequencePlanner interface {
+	Plan(ctx context.Context, definitions []SequenceDefinition, prune bool) (*Plan, error)
+	Apply(ctx context.Context, definitions []SequenceDefinition, prune bool) (*Plan, error)
+}
+
+// ContactService defines the interface for contact-related operations.
+type ContactService interface {
+	ImportContacts(ctx context.Context, req *ContactImportRequest) (*models.Job, error)
+	// Shutdown waits for imports in progress, failing those still running
+	// when ctx is done.
+	Shutdown(ctx context.Context) error
+}
+
+// EnrollmentService shows when each step of a sequence is sent to a contact.
+// Times are given in the location passed.
+type EnrollmentService interface {
+	GetTimeline(ctx context.Context, id int64, loc *time.Location) (*models.Timeline, error)
+	// PreviewTimeline plans the sends of a contact in the country, which may
+	// be empty, enrolled in the sequence now.
+	PreviewTimeline(ctx context.Context, sequenceID int64, country string, loc *time.Location) (*models.Timeline, error)
+}
+
+// HolidayService manages the holidays sends skip. Holidays without a
+// country apply to every contact, the others to the contacts in their country.
+type HolidayService interface {
+	// ImportHolidays replaces the country's holidays, or the workspace-wide
+	// ones when country is empty, with those of an iCalendar file.
+	ImportHolidays(ctx context.Context, country string, ics []byte) (*models.HolidayImportResult, error)
+	ListHolidays(ctx context.Context) ([]*models.Holiday, error)
+}
+
+// JobService defines the interface for background job tracking.
+type JobService interface {
+	GetJob(ctx context.Context, id int64) (*models.Job, error)
+	ListJobErrors(ctx context.Context, id int64) ([]models.JobItemError, error)
+	ListJobs(ctx context.Context, opts models.ListOptions) ([]*models.Job, string, error)
+}
+
+// SearchService defines the interface for full-text search across sequences and steps.
+type SearchService interface {
+	Search(ctx context.Context, query string, limit int) ([]*models.SearchHit, error)
+}
+
+// IdempotencyService records the responses of requests made with an
+// idempotency key. Begin returns the stored record when the request is a
+// replay, or nil once the key is reserved for the caller, who must then
+// either Complete it with the response or Release it.
+type IdempotencyService interface {
+	Begin(ctx context.Context, scope, key, requestHash string) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, record *models.IdempotencyRecord) error
+	Release(ctx context.Context, scope, key string) error
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+// BatchService applies an ordered list of sequence and step operations in a
+// single transaction. If any operation fails the whole batch is rolled back
+// and a *BatchError naming the operation is returned.
+type BatchService interface {
+	Execute(ctx context.Context, req *models.BatchRequest) ([]models.BatchResult, error)
+}
+
+// APIKeyService manages API keys and resolves them to principals.
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, name string, scopes []string) (*models.CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context) ([]*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+	Authenticate(ctx context.Context, secret string) (*auth.Principal, error)
+}
+
+// WorkspaceService manages workspaces and resolves the workspace a principal
+// acts in.
+type WorkspaceService interface {
+	CreateWorkspace(ctx context.Context, slug, name string) (*models.Workspace, error)
+	ListWorkspaces(ctx context.Context) ([]*models.Workspace, error)
+	Resolve(ctx context.Context, principal *auth.Principal) (int64, error)
+}
+
+// Authorizer decides whether the caller may perform an action on sequences
+// owned by a team, or on sequences without a team when teamID is nil. It
+// returns a *ForbiddenError when the caller may not.
+type Authorizer interface {
+	Authorize(ctx context.Context, action Action, teamID *int64) error
+}
+
+// TeamService manages users, teams and their members' roles.
+type TeamService interface {
+	CreateUser(ctx context.Context, user *models.User) (int64, error)
+	ListUsers(ctx context.Context) ([]*models.User, error)
+	CreateTeam(ctx context.Context, team *models.Team) (int64, error)
+	ListTeams(ctx context.Context) ([]*models.Team, error)
+	SetMember(ctx context.Context, member *models.TeamMember) error
+	RemoveMember(ctx context.Context, teamID, userID int64) error
+	ListMembers(ctx context.Context, teamID int64) ([]*models.TeamMember, error)
+}
+
+// AuditService reads the audit log of changes made through the sequence and
+// step services.
+type AuditService interface {
+	ListAudit(ctx context.Context, opts models.ListOptions) ([]*models.AuditEntry, string, error)
 }
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"context"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+)
+
+type jobService struct {
+	repo db.JobRepository
+}
+
+func NewJobService(repo db.JobRepository) JobService {
+	return &jobService{repo: repo}
+}
+
+func (s *jobService) GetJob(ctx context.Context, id int64) (*models.Job, error) {
+	job, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "job", id)
+	}
+	return job, nil
+}
+
+func (s *jobService) ListJobErrors(ctx context.Context, id int64) ([]models.JobItemError, error) {
+	if _, err := s.GetJob(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListItemErrors(ctx, id)
+}
+
+func (s *jobService) ListJobs(ctx context.Context, opts models.ListOptions) ([]*models.Job, string, error) {
+	jobs, next, err := s.repo.List(ctx, opts)
+	if err != nil {
+		return nil, "", translateError(err, "job", 0)
+	}
+	return jobs, next, nil
+}
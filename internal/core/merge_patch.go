@@ -0,0 +1,76 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// mergePatch applies an RFC 7396 JSON merge patch to a JSON document.
+func mergePatch(document, patch []byte) ([]byte, error) {
+	var target, changes interface{}
+	if err := json.Unmarshal(document, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeValue(target, changes))
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		// Anything other than an object replaces the target outright.
+		return patch
+	}
+	result, ok := target.(map[string]interface{})
+	if !ok {
+		result = make(map[string]interface{})
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(result, name)
+			continue
+		}
+		result[name] = mergeValue(result[name], value)
+	}
+	return result
+}
+
+// applyPatch merges patch into the JSON form of current and decodes the result
+// into merged. Only the fields listed in patchable may appear in the patch.
+func applyPatch(current interface{}, patch []byte, patchable []string, merged interface{}) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &fields); err != nil || fields == nil {
+		return &ValidationError{Message: "patch must be a JSON object"}
+	}
+
+	allowed := make(map[string]bool, len(patchable))
+	for _, name := range patchable {
+		allowed[name] = true
+	}
+	var rejected []FieldError
+	for name := range fields {
+		if !allowed[name] {
+			rejected = append(rejected, FieldError{Field: name, Rule: "readonly", Message: fmt.Sprintf("%s cannot be patched", name)})
+		}
+	}
+	if len(rejected) > 0 {
+		sort.Slice(rejected, func(i, j int) bool { return rejected[i].Field < rejected[j].Field })
+		return &ValidationError{Message: "patch contains fields that cannot be changed", Fields: rejected}
+	}
+
+	document, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	document, err = mergePatch(document, patch)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(document, merged); err != nil {
+		return &ValidationError{Message: "patch has values of the wrong type", Err: err}
+	}
+	return nil
+}
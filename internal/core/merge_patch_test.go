@@ -0,0 +1,82 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"sf_test/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The examples from RFC 7396 appendix A.
+func TestMergePatch_RFCExamples(t *testing.T) {
+	tests := []struct {
+		document, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		got, err := mergePatch([]byte(tt.document), []byte(tt.patch))
+		assert.NoError(t, err)
+		assert.JSONEq(t, tt.want, string(got), "patch %s onto %s", tt.patch, tt.document)
+	}
+}
+
+func TestApplyPatch_OnlyTouchesSuppliedFields(t *testing.T) {
+	current := &models.Sequence{ID: 1, Name: "Onboarding", OpenTrackingEnabled: true, Version: 3}
+
+	var merged models.Sequence
+	err := applyPatch(current, []byte(`{"clickTrackingEnabled":true}`), sequencePatchFields, &merged)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Onboarding", merged.Name)
+	assert.True(t, merged.OpenTrackingEnabled)
+	assert.True(t, merged.ClickTrackingEnabled)
+	assert.Equal(t, int64(3), merged.Version)
+}
+
+func TestApplyPatch_RejectsReadOnlyFields(t *testing.T) {
+	var merged models.Step
+	err := applyPatch(&models.Step{ID: 1}, []byte(`{"id":2,"version":9,"subject":"Hello"}`), stepPatchFields, &merged)
+
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []string{"id", "version"}, []string{validationErr.Fields[0].Field, validationErr.Fields[1].Field})
+}
+
+func TestApplyPatch_RejectsNonObjects(t *testing.T) {
+	for _, patch := range []string{`null`, `[]`, `"name"`, `{`} {
+		var merged models.Step
+		err := applyPatch(&models.Step{}, []byte(patch), stepPatchFields, &merged)
+
+		var validationErr *ValidationError
+		assert.True(t, errors.As(err, &validationErr), "patch %s", patch)
+	}
+}
+
+func TestApplyPatch_WrongType(t *testing.T) {
+	var merged models.Step
+	err := applyPatch(&models.Step{}, []byte(`{"waitDays":"two"}`), stepPatchFields, &merged)
+
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	var typeErr *json.UnmarshalTypeError
+	assert.True(t, errors.As(err, &typeErr))
+}
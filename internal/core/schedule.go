@@ -0,0 +1,33 @@
+package core
+
+import (
+	"context"
+	"sf_test/internal/calendar"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+	"time"
+)
+
+// stepDueAt returns when the step falls due, counting its wait from when the
+// contact was enrolled or the previous step was sent. Business days skip
+// weekends, and every unit moves the send past the holidays in cal.
+func stepDueAt(from time.Time, step *models.Step, cal *calendar.Calendar) time.Time {
+	switch step.EffectiveWaitUnit() {
+	case models.WaitUnitHours:
+		return cal.SkipHolidays(from.Add(time.Duration(step.WaitDays) * time.Hour))
+	case models.WaitUnitBusinessDays:
+		return cal.AddBusinessDays(from, step.WaitDays)
+	default:
+		return cal.SkipHolidays(from.AddDate(0, 0, step.WaitDays))
+	}
+}
+
+// holidayCalendar returns the calendar of the holidays applying to contacts
+// in the country, counting days in loc.
+func holidayCalendar(ctx context.Context, holidayRepo db.HolidayRepository, country string, loc *time.Location) (*calendar.Calendar, error) {
+	holidays, err := holidayRepo.ListForCountry(ctx, country)
+	if err != nil {
+		return nil, err
+	}
+	return calendar.New(holidays, loc), nil
+}
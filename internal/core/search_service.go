@@ -0,0 +1,37 @@
+package core
+
+import (
+	"context"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+	"strings"
+)
+
+// maxSearchLimit caps the number of hits a single search returns.
+const maxSearchLimit = 100
+
+type searchService struct {
+	repo       db.SearchRepository
+	authorizer Authorizer
+}
+
+func NewSearchService(repo db.SearchRepository, authorizer Authorizer) SearchService {
+	return &searchService{repo: repo, authorizer: authorizer}
+}
+
+func (s *searchService) Search(ctx context.Context, query string, limit int) ([]*models.SearchHit, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, &ValidationError{
+			Message: "search query is required",
+			Fields:  []FieldError{{Field: "q", Rule: "required", Message: "q is required"}},
+		}
+	}
+	if err := s.authorizer.Authorize(ctx, ActionRead, nil); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = 20
+	}
+	return s.repo.Search(ctx, query, limit)
+}
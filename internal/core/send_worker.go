@@ -0,0 +1,227 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sf_test/internal/clock"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+	"sf_test/internal/tenant"
+	"strconv"
+	"time"
+)
+
+// EmailSender delivers an email.
+type EmailSender interface {
+	SendEmail(recipient, subject, body string) error
+}
+
+// SendWorkerConfig controls how often the send worker polls and how it
+// retries failed deliveries.
+type SendWorkerConfig struct {
+	// PollInterval is how often every workspace is checked for due sends
+	// regardless of notifications, in case one was missed.
+	PollInterval time.Duration
+	// MaxAttempts is how many times a send is tried before it fails.
+	MaxAttempts int
+	// RetryDelay is the wait before the first retry; it doubles with each
+	// further attempt.
+	RetryDelay time.Duration
+}
+
+// SendWorker delivers due sends. It sleeps until the next send is due, a
+// notification announces new work, or the poll interval passes, so it
+// neither polls the database constantly nor leaves sends waiting.
+//
+// Each send is delivered in the transaction that locks it, so several
+// workers can run side by side. A send whose transaction fails after the
+// email went out is delivered again.
+type SendWorker struct {
+	tx             db.Transactor
+	sendRepo       db.SendRepository
+	stepRepo       db.StepRepository
+	enrollmentRepo db.EnrollmentRepository
+	workspaceRepo  db.WorkspaceRepository
+	holidayRepo    db.HolidayRepository
+	sender         EmailSender
+	clock          clock.Clock
+	config         SendWorkerConfig
+
+	// nextDue holds when each workspace's next send is due.
+	nextDue  map[int64]time.Time
+	lastPoll time.Time
+}
+
+func NewSendWorker(tx db.Transactor, sendRepo db.SendRepository, stepRepo db.StepRepository, enrollmentRepo db.EnrollmentRepository, workspaceRepo db.WorkspaceRepository, holidayRepo db.HolidayRepository, sender EmailSender, clk clock.Clock, config SendWorkerConfig) *SendWorker {
+	return &SendWorker{
+		tx:             tx,
+		sendRepo:       sendRepo,
+		stepRepo:       stepRepo,
+		enrollmentRepo: enrollmentRepo,
+		workspaceRepo:  workspaceRepo,
+		holidayRepo:    holidayRepo,
+		sender:         sender,
+		clock:          clk,
+		config:         config,
+		nextDue:        map[int64]time.Time{},
+	}
+}
+
+// Run delivers sends until ctx is done, returning once the send in progress
+// is finished. Notifications carry the ID of the workspace with new work; a
+// closed notification channel leaves the worker polling.
+func (w *SendWorker) Run(ctx context.Context, notifications <-chan db.Notification) {
+	w.poll(ctx)
+	timer := w.clock.NewTimer(w.untilNextWake())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification, ok := <-notifications:
+			if !ok {
+				notifications = nil
+				break
+			}
+			workspaceID, err := strconv.ParseInt(notification.Payload, 10, 64)
+			if notification.Reconnected || err != nil {
+				w.poll(ctx)
+			} else {
+				w.drain(ctx, workspaceID)
+			}
+		case <-timer.C():
+			w.wake(ctx)
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C():
+			default:
+			}
+		}
+		timer.Reset(w.untilNextWake())
+	}
+}
+
+// wake polls every workspace once the poll interval has passed, and
+// otherwise drains the workspaces whose next send is due.
+func (w *SendWorker) wake(ctx context.Context) {
+	now := w.clock.Now()
+	if now.Sub(w.lastPoll) >= w.config.PollInterval {
+		w.poll(ctx)
+		return
+	}
+	for workspaceID, dueAt := range w.nextDue {
+		if !dueAt.After(now) {
+			w.drain(ctx, workspaceID)
+		}
+	}
+}
+
+// untilNextWake returns the time until the next send is due or the next
+// poll, whichever comes first.
+func (w *SendWorker) untilNextWake() time.Duration {
+	wake := w.lastPoll.Add(w.config.PollInterval)
+	for _, dueAt := range w.nextDue {
+		if dueAt.Before(wake) {
+			wake = dueAt
+		}
+	}
+	if wait := wake.Sub(w.clock.Now()); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// poll drains every workspace.
+func (w *SendWorker) poll(ctx context.Context) {
+	w.lastPoll = w.clock.Now()
+	workspaces, err := w.workspaceRepo.List(ctx)
+	if err != nil {
+		log.Printf("Failed to list workspaces for sends: %v", err)
+		return
+	}
+	for _, workspace := range workspaces {
+		w.drain(ctx, workspace.ID)
+	}
+}
+
+// drain delivers the workspace's due sends and records when its next one is due.
+func (w *SendWorker) drain(ctx context.Context, workspaceID int64) {
+	ctx = tenant.WithWorkspace(ctx, workspaceID)
+	for ctx.Err() == nil {
+		delivered, err := w.deliverNext(ctx)
+		if err != nil {
+			log.Printf("Failed to deliver send in workspace %d: %v", workspaceID, err)
+			break
+		}
+		if !delivered {
+			break
+		}
+	}
+
+	dueAt, err := w.sendRepo.NextDueAt(ctx)
+	if err != nil {
+		log.Printf("Failed to find next send in workspace %d: %v", workspaceID, err)
+		return
+	}
+	if dueAt == nil {
+		delete(w.nextDue, workspaceID)
+		return
+	}
+	w.nextDue[workspaceID] = *dueAt
+}
+
+// deliverNext delivers the earliest due send, if any, and reports whether
+// there was one. A send is finished even when ctx is cancelled meanwhile,
+// so an email that went out is recorded as sent when the worker stops.
+func (w *SendWorker) deliverNext(ctx context.Context) (bool, error) {
+	ctx = context.WithoutCancel(ctx)
+	delivered := false
+	err := w.tx.WithinTx(ctx, func(ctx context.Context) error {
+		send, err := w.sendRepo.ClaimDue(ctx, w.clock.Now())
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		delivered = true
+
+		if err := w.sender.SendEmail(send.Recipient, send.Subject, send.Content); err != nil {
+			if send.Attempts+1 >= w.config.MaxAttempts {
+				return w.sendRepo.MarkFailed(ctx, send.ID, err.Error())
+			}
+			retryAt := w.clock.Now().Add(w.config.RetryDelay << send.Attempts)
+			return w.sendRepo.Retry(ctx, send.ID, err.Error(), retryAt)
+		}
+		sentAt := w.clock.Now()
+		if err := w.sendRepo.MarkSent(ctx, send.ID, sentAt); err != nil {
+			return err
+		}
+		return w.scheduleNext(ctx, send, sentAt)
+	})
+	return delivered, err
+}
+
+// scheduleNext schedules the step after the one sent at sentAt, keeping it off
+// the contact's holidays, or completes the enrollment after its last step.
+func (w *SendWorker) scheduleNext(ctx context.Context, send *models.Send, sentAt time.Time) error {
+	steps, err := w.stepRepo.ListBySequenceID(ctx, send.SequenceID)
+	if err != nil {
+		return err
+	}
+	for _, step := range steps {
+		if step.StepOrder > send.StepOrder {
+			cal, err := holidayCalendar(ctx, w.holidayRepo, send.Country, time.UTC)
+			if err != nil {
+				return err
+			}
+			return w.sendRepo.Schedule(ctx, send.EnrollmentID, step.ID, stepDueAt(sentAt, step, cal))
+		}
+	}
+	return w.enrollmentRepo.Complete(ctx, send.EnrollmentID)
+}
@@ -0,0 +1,367 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"sf_test/internal/clock"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+	"sf_test/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSendRepository keeps sends in memory, ignoring workspaces. Sends it
+// scheduled take their sequence and message from steps when claimed.
+type fakeSendRepository struct {
+	mu        sync.Mutex
+	sends     map[int64]*models.Send
+	steps     map[int64]*models.Step
+	scheduled []int64
+}
+
+func (r *fakeSendRepository) add(send *models.Send) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sends[send.ID] = send
+}
+
+func (r *fakeSendRepository) get(id int64) models.Send {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.sends[id]
+}
+
+func (r *fakeSendRepository) ClaimDue(ctx context.Context, now time.Time) (*models.Send, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed *models.Send
+	for _, send := range r.sends {
+		if send.Status != models.SendStatusPending || send.DueAt.After(now) {
+			continue
+		}
+		if claimed == nil || send.DueAt.Before(claimed.DueAt) || (send.DueAt.Equal(claimed.DueAt) && send.ID < claimed.ID) {
+			claimed = send
+		}
+	}
+	if claimed == nil {
+		return nil, sql.ErrNoRows
+	}
+	send := *claimed
+	if step, ok := r.steps[send.StepID]; ok && send.SequenceID == 0 {
+		send.SequenceID, send.StepOrder, send.Subject = step.SequenceID, step.StepOrder, step.Subject
+	}
+	return &send, nil
+}
+
+func (r *fakeSendRepository) MarkSent(ctx context.Context, id int64, sentAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sends[id].Status = models.SendStatusSent
+	r.sends[id].SentAt = &sentAt
+	r.sends[id].Attempts++
+	return nil
+}
+
+func (r *fakeSendRepository) Retry(ctx context.Context, id int64, reason string, dueAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sends[id].Attempts++
+	r.sends[id].LastError = reason
+	r.sends[id].DueAt = dueAt
+	return nil
+}
+
+func (r *fakeSendRepository) MarkFailed(ctx context.Context, id int64, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sends[id].Status = models.SendStatusFailed
+	r.sends[id].Attempts++
+	r.sends[id].LastError = reason
+	return nil
+}
+
+func (r *fakeSendRepository) Schedule(ctx context.Context, enrollmentID, stepID int64, dueAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scheduled = append(r.scheduled, stepID)
+	id := int64(len(r.sends) + 1)
+	r.sends[id] = &models.Send{ID: id, EnrollmentID: enrollmentID, StepID: stepID, Status: models.SendStatusPending, DueAt: dueAt}
+	return nil
+}
+
+func (r *fakeSendRepository) NextDueAt(ctx context.Context) (*time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var next *time.Time
+	for _, send := range r.sends {
+		if send.Status == models.SendStatusPending && (next == nil || send.DueAt.Before(*next)) {
+			dueAt := send.DueAt
+			next = &dueAt
+		}
+	}
+	return next, nil
+}
+
+func (r *fakeSendRepository) ListByEnrollment(ctx context.Context, enrollmentID int64) ([]*models.Send, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sends []*models.Send
+	for _, send := range r.sends {
+		if send.EnrollmentID == enrollmentID {
+			copied := *send
+			sends = append(sends, &copied)
+		}
+	}
+	sort.Slice(sends, func(i, j int) bool { return sends[i].StepOrder < sends[j].StepOrder })
+	return sends, nil
+}
+
+type fakeEnrollmentRepository struct {
+	db.EnrollmentRepository
+	mu          sync.Mutex
+	enrollments map[int64]*models.Enrollment
+	completed   []int64
+}
+
+func (r *fakeEnrollmentRepository) Get(ctx context.Context, id int64) (*models.Enrollment, error) {
+	enrollment, ok := r.enrollments[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *enrollment
+	return &copied, nil
+}
+
+func (r *fakeEnrollmentRepository) Complete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.completed = append(r.completed, id)
+	return nil
+}
+
+// fakeEmailSender records delivered subjects, failing while err is set.
+type fakeEmailSender struct {
+	mu       sync.Mutex
+	subjects []string
+	err      error
+	// onSend, when set, is called before each email is delivered.
+	onSend func()
+}
+
+func (s *fakeEmailSender) SendEmail(recipient, subject, body string) error {
+	if s.onSend != nil {
+		s.onSend()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.subjects = append(s.subjects, subject)
+	return nil
+}
+
+func (s *fakeEmailSender) delivered() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.subjects...)
+}
+
+// sendStart is when the send worker tests begin, a Monday morning.
+var sendStart = time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+
+func newTestSendWorker(sends *fakeSendRepository, sender *fakeEmailSender, enrollments *fakeEnrollmentRepository, clk clock.Clock) *SendWorker {
+	steps := &fakeStepRepository{steps: map[int64]*models.Step{
+		10: {ID: 10, SequenceID: 7, StepOrder: 0},
+		11: {ID: 11, SequenceID: 7, StepOrder: 1, WaitDays: 2},
+		20: {ID: 20, SequenceID: 8, StepOrder: 0, Subject: "Hello"},
+		21: {ID: 21, SequenceID: 8, StepOrder: 1, WaitDays: 1, Subject: "Follow up"},
+		22: {ID: 22, SequenceID: 8, StepOrder: 2, WaitDays: 3, Subject: "Last chance"},
+	}}
+	sends.steps = steps.steps
+	return NewSendWorker(&fakeTransactor{}, sends, steps, enrollments, &fakeWorkspaceRepository{workspaces: []*models.Workspace{{ID: 1, Slug: "default"}}}, &fakeHolidayRepository{}, sender, clk, SendWorkerConfig{
+		PollInterval: time.Hour,
+		MaxAttempts:  2,
+		RetryDelay:   time.Minute,
+	})
+}
+
+func TestSendWorker_DeliversAndSchedulesNextStep(t *testing.T) {
+	sends := &fakeSendRepository{sends: map[int64]*models.Send{
+		1: {ID: 1, EnrollmentID: 3, SequenceID: 7, StepID: 10, StepOrder: 0, Status: models.SendStatusPending, DueAt: sendStart, Subject: "Welcome"},
+		2: {ID: 2, EnrollmentID: 4, SequenceID: 7, StepID: 11, StepOrder: 1, Status: models.SendStatusPending, DueAt: sendStart, Subject: "Checking in"},
+	}}
+	sender := &fakeEmailSender{}
+	enrollments := &fakeEnrollmentRepository{}
+	worker := newTestSendWorker(sends, sender, enrollments, clock.NewFake(sendStart))
+
+	worker.drain(context.Background(), 1)
+
+	assert.ElementsMatch(t, []string{"Welcome", "Checking in"}, sender.delivered())
+	assert.Equal(t, models.SendStatusSent, sends.get(1).Status)
+	assert.Equal(t, timeRef(sendStart), sends.get(1).SentAt, "sends are stamped with the worker's clock")
+	assert.Equal(t, []int64{11}, sends.scheduled)
+	assert.Equal(t, []int64{4}, enrollments.completed)
+	assert.Equal(t, map[int64]time.Time{1: sendStart.AddDate(0, 0, 2)}, worker.nextDue)
+}
+
+func TestSendWorker_SchedulesNextStepPastHolidays(t *testing.T) {
+	sends := &fakeSendRepository{sends: map[int64]*models.Send{
+		1: {ID: 1, EnrollmentID: 3, SequenceID: 7, StepID: 10, StepOrder: 0, Status: models.SendStatusPending, DueAt: sendStart, Country: "FR"},
+	}}
+	worker := newTestSendWorker(sends, &fakeEmailSender{}, &fakeEnrollmentRepository{}, clock.NewFake(sendStart))
+	wednesday := sendStart.AddDate(0, 0, 2)
+	worker.holidayRepo = &fakeHolidayRepository{holidays: []*models.Holiday{holiday("FR", wednesday), holiday("DE", wednesday.AddDate(0, 0, 1))}}
+
+	worker.drain(context.Background(), 1)
+
+	assert.Equal(t, []int64{11}, sends.scheduled)
+	assert.Equal(t, wednesday.AddDate(0, 0, 1), sends.get(2).DueAt, "the contact's country's holidays apply, others do not")
+}
+
+func TestSendWorker_RetriesThenFails(t *testing.T) {
+	sends := &fakeSendRepository{sends: map[int64]*models.Send{
+		1: {ID: 1, EnrollmentID: 3, SequenceID: 7, StepID: 10, Status: models.SendStatusPending, DueAt: sendStart},
+	}}
+	sender := &fakeEmailSender{err: errors.New("smtp unavailable")}
+	fakeClock := clock.NewFake(sendStart)
+	worker := newTestSendWorker(sends, sender, &fakeEnrollmentRepository{}, fakeClock)
+	ctx := tenant.WithWorkspace(context.Background(), 1)
+
+	delivered, err := worker.deliverNext(ctx)
+	assert.NoError(t, err)
+	assert.True(t, delivered)
+	send := sends.get(1)
+	assert.Equal(t, models.SendStatusPending, send.Status)
+	assert.Equal(t, "smtp unavailable", send.LastError)
+	assert.Equal(t, sendStart.Add(time.Minute), send.DueAt)
+
+	delivered, err = worker.deliverNext(ctx)
+	assert.NoError(t, err)
+	assert.False(t, delivered, "the retry is not due yet")
+
+	fakeClock.Advance(time.Minute)
+	_, err = worker.deliverNext(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, models.SendStatusFailed, sends.get(1).Status)
+	assert.Equal(t, 2, sends.get(1).Attempts)
+}
+
+func TestSendWorker_WakesOnNotification(t *testing.T) {
+	sends := &fakeSendRepository{sends: map[int64]*models.Send{}}
+	sender := &fakeEmailSender{}
+	worker := newTestSendWorker(sends, sender, &fakeEnrollmentRepository{}, clock.NewFake(sendStart))
+	notifications := make(chan db.Notification)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		worker.Run(ctx, notifications)
+		close(done)
+	}()
+
+	sends.add(&models.Send{ID: 1, EnrollmentID: 3, SequenceID: 7, StepID: 11, StepOrder: 1, Status: models.SendStatusPending, DueAt: sendStart, Subject: "Welcome"})
+	notifications <- db.Notification{Channel: db.SendsDueChannel, Payload: "1"}
+
+	assert.Eventually(t, func() bool { return len(sender.delivered()) == 1 }, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+}
+
+func TestSendWorker_FinishesSendInProgressWhenStopped(t *testing.T) {
+	sends := &fakeSendRepository{sends: map[int64]*models.Send{
+		1: {ID: 1, EnrollmentID: 3, SequenceID: 7, StepID: 11, StepOrder: 1, Status: models.SendStatusPending, DueAt: sendStart, Subject: "First"},
+		2: {ID: 2, EnrollmentID: 4, SequenceID: 7, StepID: 11, StepOrder: 1, Status: models.SendStatusPending, DueAt: sendStart, Subject: "Second"},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	// The worker is stopped while the first email goes out.
+	sender := &fakeEmailSender{onSend: cancel}
+	worker := newTestSendWorker(sends, sender, &fakeEnrollmentRepository{}, clock.NewFake(sendStart))
+	worker.Run(ctx, nil)
+
+	assert.Equal(t, []string{"First"}, sender.delivered())
+	assert.Equal(t, models.SendStatusSent, sends.get(1).Status)
+	assert.Equal(t, models.SendStatusPending, sends.get(2).Status)
+}
+
+func TestSendWorker_WakesWhenSendIsDue(t *testing.T) {
+	sends := &fakeSendRepository{sends: map[int64]*models.Send{
+		1: {ID: 1, EnrollmentID: 3, SequenceID: 7, StepID: 11, StepOrder: 1, Status: models.SendStatusPending, DueAt: sendStart.Add(time.Minute), Subject: "Later"},
+	}}
+	sender := &fakeEmailSender{}
+	fakeClock := clock.NewFake(sendStart)
+	worker := newTestSendWorker(sends, sender, &fakeEnrollmentRepository{}, fakeClock)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		worker.Run(ctx, nil)
+		close(done)
+	}()
+
+	fakeClock.BlockUntil(1)
+	assert.Empty(t, sender.delivered())
+	fakeClock.Advance(time.Minute)
+	fakeClock.BlockUntil(1)
+	assert.Equal(t, []string{"Later"}, sender.delivered())
+	cancel()
+	<-done
+}
+
+// TestSendWorker_SimulatedWeek runs a week of sending on the fake clock,
+// checking every step goes out when its wait is over.
+func TestSendWorker_SimulatedWeek(t *testing.T) {
+	sends := &fakeSendRepository{sends: map[int64]*models.Send{}}
+	for id := int64(1); id <= 3; id++ {
+		sends.add(&models.Send{ID: id, EnrollmentID: id, StepID: 20, Status: models.SendStatusPending, DueAt: sendStart})
+	}
+	sender := &fakeEmailSender{}
+	enrollments := &fakeEnrollmentRepository{}
+	fakeClock := clock.NewFake(sendStart)
+	worker := newTestSendWorker(sends, sender, enrollments, fakeClock)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		worker.Run(ctx, nil)
+		close(done)
+	}()
+
+	sentAt := map[string][]time.Time{}
+	for fakeClock.Now().Before(sendStart.AddDate(0, 0, 7)) {
+		fakeClock.BlockUntil(1)
+		delivered := sender.delivered()
+		for _, subject := range delivered[countSent(sentAt):] {
+			sentAt[subject] = append(sentAt[subject], fakeClock.Now())
+		}
+		fakeClock.Advance(time.Hour)
+	}
+	cancel()
+	<-done
+
+	times := func(t time.Time) []time.Time { return []time.Time{t, t, t} }
+	assert.Equal(t, map[string][]time.Time{
+		"Hello":       times(sendStart),
+		"Follow up":   times(sendStart.AddDate(0, 0, 1)),
+		"Last chance": times(sendStart.AddDate(0, 0, 4)),
+	}, sentAt)
+	assert.ElementsMatch(t, []int64{1, 2, 3}, enrollments.completed)
+}
+
+func countSent(sentAt map[string][]time.Time) int {
+	n := 0
+	for _, times := range sentAt {
+		n += len(times)
+	}
+	return n
+}
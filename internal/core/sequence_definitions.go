@@ -0,0 +1,72 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// definitionFile is the top-level layout of a sequence definition file.
+type definitionFile struct {
+	Sequences []SequenceDefinition `json:"sequences" yaml:"sequences"`
+}
+
+// LoadSequenceDefinitions reads sequence definitions from YAML or JSON files.
+// Directories are searched (non-recursively) for .yaml, .yml and .json files.
+func LoadSequenceDefinitions(paths ...string) ([]SequenceDefinition, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		var found []string
+		for _, entry := range entries {
+			if !entry.IsDir() && isDefinitionFile(entry.Name()) {
+				found = append(found, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+
+	var definitions []SequenceDefinition
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var parsed definitionFile
+		if strings.EqualFold(filepath.Ext(file), ".json") {
+			err = json.Unmarshal(data, &parsed)
+		} else {
+			err = yaml.Unmarshal(data, &parsed)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		definitions = append(definitions, parsed.Sequences...)
+	}
+	return definitions, nil
+}
+
+func isDefinitionFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,420 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+)
+
+// SequenceDefinition is the declarative form of a sequence, identified by a
+// stable external key rather than its database ID.
+type SequenceDefinition struct {
+	Key                  string           `json:"key" yaml:"key"`
+	Name                 string           `json:"name" yaml:"name"`
+	OpenTrackingEnabled  bool             `json:"openTrackingEnabled" yaml:"openTrackingEnabled"`
+	ClickTrackingEnabled bool             `json:"clickTrackingEnabled" yaml:"clickTrackingEnabled"`
+	Steps                []StepDefinition `json:"steps" yaml:"steps"`
+}
+
+// StepDefinition is the declarative form of a step. Its order is its position
+// in the owning definition, and it is matched against existing steps by subject.
+type StepDefinition struct {
+	Subject  string `json:"subject" yaml:"subject"`
+	Content  string `json:"content" yaml:"content"`
+	WaitDays int    `json:"waitDays" yaml:"waitDays"`
+	WaitUnit string `json:"waitUnit,omitempty" yaml:"waitUnit,omitempty"`
+}
+
+// toModel converts the definition into a sequence model ready to be validated.
+func (d *SequenceDefinition) toModel() *models.Sequence {
+	sequence := &models.Sequence{
+		Name:                 d.Name,
+		ExternalKey:          d.Key,
+		OpenTrackingEnabled:  d.OpenTrackingEnabled,
+		ClickTrackingEnabled: d.ClickTrackingEnabled,
+	}
+	for i, step := range d.Steps {
+		sequence.Steps = append(sequence.Steps, models.Step{
+			Subject:   step.Subject,
+			Content:   step.Content,
+			StepOrder: i,
+			WaitDays:  step.WaitDays,
+			WaitUnit:  step.WaitUnit,
+		})
+	}
+	return sequence
+}
+
+// Validate checks the definition and the sequence it describes.
+func (d *SequenceDefinition) Validate() error {
+	if d.Key == "" {
+		return errors.New("key is required")
+	}
+	subjects := make(map[string]bool)
+	for _, step := range d.Steps {
+		if subjects[step.Subject] {
+			return fmt.Errorf("step subject %q is used more than once", step.Subject)
+		}
+		subjects[step.Subject] = true
+	}
+	return d.toModel().Validate()
+}
+
+// PlanAction is the kind of change a plan entry performs.
+type PlanAction string
+
+const (
+	PlanCreate  PlanAction = "create"
+	PlanUpdate  PlanAction = "update"
+	PlanReorder PlanAction = "reorder"
+	PlanDelete  PlanAction = "delete"
+)
+
+// PlanChange is a single change needed to bring the database in line with the definitions.
+type PlanChange struct {
+	Action   PlanAction `json:"action"`
+	Resource string     `json:"resource"`
+	Key      string     `json:"key"`
+	Subject  string     `json:"subject,omitempty"`
+	Detail   string     `json:"detail,omitempty"`
+
+	sequence *models.Sequence
+	step     *models.Step
+	// stored is the sequence or step as it is before an update.
+	stored interface{}
+}
+
+// Plan is the ordered list of changes computed by a SequencePlanner.
+type Plan struct {
+	Changes []PlanChange `json:"changes"`
+}
+
+// Empty reports whether the plan has nothing to do.
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Count returns the number of changes with the given action.
+func (p *Plan) Count(action PlanAction) int {
+	n := 0
+	for _, change := range p.Changes {
+		if change.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Print writes a human readable summary of the plan.
+func (p *Plan) Print(w io.Writer) {
+	symbols := map[PlanAction]string{
+		PlanCreate:  "+",
+		PlanUpdate:  "~",
+		PlanReorder: ">",
+		PlanDelete:  "-",
+	}
+	for _, change := range p.Changes {
+		target := fmt.Sprintf("%s %q", change.Resource, change.Key)
+		if change.Subject != "" {
+			target += fmt.Sprintf(" / %q", change.Subject)
+		}
+		line := fmt.Sprintf("%s %s %s", symbols[change.Action], change.Action, target)
+		if change.Detail != "" {
+			line += ": " + change.Detail
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "Plan: %d to create, %d to update, %d to reorder, %d to delete.\n",
+		p.Count(PlanCreate), p.Count(PlanUpdate), p.Count(PlanReorder), p.Count(PlanDelete))
+}
+
+type sequencePlanner struct {
+	tx           db.Transactor
+	sequenceRepo db.SequenceRepository
+	stepRepo     db.StepRepository
+	auditRepo    db.AuditRepository
+}
+
+func NewSequencePlanner(tx db.Transactor, sequenceRepo db.SequenceRepository, stepRepo db.StepRepository, auditRepo db.AuditRepository) SequencePlanner {
+	return &sequencePlanner{tx: tx, sequenceRepo: sequenceRepo, stepRepo: stepRepo, auditRepo: auditRepo}
+}
+
+func (p *sequencePlanner) Plan(ctx context.Context, definitions []SequenceDefinition, prune bool) (*Plan, error) {
+	existing, managed, err := p.loadState(ctx, definitions)
+	if err != nil {
+		return nil, err
+	}
+	return buildPlan(definitions, existing, managed, prune)
+}
+
+func (p *sequencePlanner) Apply(ctx context.Context, definitions []SequenceDefinition, prune bool) (*Plan, error) {
+	var plan *Plan
+	// Concurrent applies must not both act on the same state; the plan is
+	// recomputed on every attempt, so the transaction can be retried.
+	err := p.tx.WithinTx(db.Serializable(ctx), func(ctx context.Context) error {
+		// Recompute the plan inside the transaction so it reflects what is applied.
+		var err error
+		plan, err = p.Plan(ctx, definitions, prune)
+		if err != nil {
+			return err
+		}
+		for _, change := range plan.Changes {
+			if err := p.applyChange(ctx, change); err != nil {
+				return fmt.Errorf("%s %s %q: %w", change.Action, change.Resource, change.Key, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// loadState fetches the current sequences for the defined keys and, for
+// pruning, every sequence that carries an external key.
+func (p *sequencePlanner) loadState(ctx context.Context, definitions []SequenceDefinition) (map[string]*models.Sequence, []*models.Sequence, error) {
+	existing := make(map[string]*models.Sequence)
+	for _, definition := range definitions {
+		sequence, err := p.sequenceRepo.GetByExternalKey(ctx, definition.Key)
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return nil, nil, err
+		}
+		existing[definition.Key] = sequence
+	}
+
+	managed, err := p.sequenceRepo.ListWithExternalKey(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return existing, managed, nil
+}
+
+// applyChange makes the change and records it in the audit log, in the
+// transaction of the apply.
+func (p *sequencePlanner) applyChange(ctx context.Context, change PlanChange) error {
+	switch {
+	case change.Resource == "sequence" && change.Action == PlanCreate:
+		id, err := p.sequenceRepo.Create(ctx, change.sequence)
+		if err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, p.auditRepo, models.AuditActionCreate, models.AuditResourceSequence, id, nil, change.sequence); err != nil {
+			return err
+		}
+		for i := range change.sequence.Steps {
+			step := &change.sequence.Steps[i]
+			if err := recordAudit(ctx, p.auditRepo, models.AuditActionCreate, models.AuditResourceStep, step.ID, nil, step); err != nil {
+				return err
+			}
+		}
+		return nil
+	case change.Resource == "sequence" && change.Action == PlanUpdate:
+		stored := change.stored.(*models.Sequence)
+		if err := p.sequenceRepo.Update(ctx, change.sequence); err != nil {
+			return err
+		}
+		updated := *stored
+		updated.Name = change.sequence.Name
+		updated.OpenTrackingEnabled = change.sequence.OpenTrackingEnabled
+		updated.ClickTrackingEnabled = change.sequence.ClickTrackingEnabled
+		return recordAudit(ctx, p.auditRepo, models.AuditActionUpdate, models.AuditResourceSequence, stored.ID, stored, &updated)
+	case change.Resource == "sequence" && change.Action == PlanDelete:
+		// Pruned sequences are listed without their steps, which are
+		// deleted with them and audited as well.
+		current, err := p.sequenceRepo.Get(ctx, change.sequence.ID)
+		if err != nil {
+			return err
+		}
+		if err := p.sequenceRepo.Delete(ctx, change.sequence.ID, change.sequence.Version); err != nil {
+			return err
+		}
+		for i := range current.Steps {
+			step := &current.Steps[i]
+			if err := recordAudit(ctx, p.auditRepo, models.AuditActionDelete, models.AuditResourceStep, step.ID, step, nil); err != nil {
+				return err
+			}
+		}
+		return recordAudit(ctx, p.auditRepo, models.AuditActionDelete, models.AuditResourceSequence, current.ID, current, nil)
+	case change.Resource == "step" && change.Action == PlanCreate:
+		id, err := p.stepRepo.Create(ctx, change.step)
+		if err != nil {
+			return err
+		}
+		change.step.ID = id
+		return recordAudit(ctx, p.auditRepo, models.AuditActionCreate, models.AuditResourceStep, id, nil, change.step)
+	case change.Resource == "step" && (change.Action == PlanUpdate || change.Action == PlanReorder):
+		stored := change.stored.(*models.Step)
+		if err := p.stepRepo.Update(ctx, change.step); err != nil {
+			return err
+		}
+		updated := *stored
+		updated.Content, updated.StepOrder = change.step.Content, change.step.StepOrder
+		updated.WaitDays, updated.WaitUnit = change.step.WaitDays, change.step.EffectiveWaitUnit()
+		return recordAudit(ctx, p.auditRepo, models.AuditActionUpdate, models.AuditResourceStep, stored.ID, stored, &updated)
+	case change.Resource == "step" && change.Action == PlanDelete:
+		if err := p.stepRepo.Delete(ctx, change.step.ID, change.step.Version); err != nil {
+			return err
+		}
+		return recordAudit(ctx, p.auditRepo, models.AuditActionDelete, models.AuditResourceStep, change.step.ID, change.step, nil)
+	}
+	return fmt.Errorf("unsupported change %s %s", change.Action, change.Resource)
+}
+
+// buildPlan diffs the definitions against the current state. existing maps
+// definition keys to their stored sequence, managed lists every stored
+// sequence with an external key and is only used when prune is set.
+func buildPlan(definitions []SequenceDefinition, existing map[string]*models.Sequence, managed []*models.Sequence, prune bool) (*Plan, error) {
+	plan := &Plan{}
+	defined := make(map[string]bool)
+
+	for i := range definitions {
+		definition := &definitions[i]
+		if defined[definition.Key] {
+			return nil, fmt.Errorf("sequence key %q is defined more than once", definition.Key)
+		}
+		defined[definition.Key] = true
+		if err := definition.Validate(); err != nil {
+			return nil, fmt.Errorf("sequence %q: %w", definition.Key, err)
+		}
+
+		desired := definition.toModel()
+		current, ok := existing[definition.Key]
+		if !ok {
+			plan.Changes = append(plan.Changes, PlanChange{
+				Action:   PlanCreate,
+				Resource: "sequence",
+				Key:      definition.Key,
+				Detail:   fmt.Sprintf("%d steps", len(desired.Steps)),
+				sequence: desired,
+			})
+			continue
+		}
+
+		if detail := diffSequence(current, desired); detail != "" {
+			desired.ID = current.ID
+			desired.Version = current.Version
+			plan.Changes = append(plan.Changes, PlanChange{
+				Action:   PlanUpdate,
+				Resource: "sequence",
+				Key:      definition.Key,
+				Detail:   detail,
+				sequence: desired,
+				stored:   current,
+			})
+		}
+		plan.Changes = append(plan.Changes, diffSteps(definition.Key, current, desired.Steps)...)
+	}
+
+	if prune {
+		for _, sequence := range managed {
+			if defined[sequence.ExternalKey] {
+				continue
+			}
+			plan.Changes = append(plan.Changes, PlanChange{
+				Action:   PlanDelete,
+				Resource: "sequence",
+				Key:      sequence.ExternalKey,
+				sequence: sequence,
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+func diffSequence(current, desired *models.Sequence) string {
+	detail := ""
+	add := func(s string) {
+		if detail != "" {
+			detail += ", "
+		}
+		detail += s
+	}
+	if current.Name != desired.Name {
+		add(fmt.Sprintf("name %q -> %q", current.Name, desired.Name))
+	}
+	if current.OpenTrackingEnabled != desired.OpenTrackingEnabled {
+		add(fmt.Sprintf("openTrackingEnabled %t -> %t", current.OpenTrackingEnabled, desired.OpenTrackingEnabled))
+	}
+	if current.ClickTrackingEnabled != desired.ClickTrackingEnabled {
+		add(fmt.Sprintf("clickTrackingEnabled %t -> %t", current.ClickTrackingEnabled, desired.ClickTrackingEnabled))
+	}
+	return detail
+}
+
+// diffSteps matches desired steps to the stored ones by subject.
+func diffSteps(key string, current *models.Sequence, desired []models.Step) []PlanChange {
+	var changes []PlanChange
+	bySubject := make(map[string]models.Step)
+	for _, step := range current.Steps {
+		if _, ok := bySubject[step.Subject]; !ok {
+			bySubject[step.Subject] = step
+		}
+	}
+	matched := make(map[int64]bool)
+
+	for _, step := range desired {
+		step := step
+		step.SequenceID = current.ID
+		stored, ok := bySubject[step.Subject]
+		if !ok {
+			changes = append(changes, PlanChange{
+				Action:   PlanCreate,
+				Resource: "step",
+				Key:      key,
+				Subject:  step.Subject,
+				Detail:   fmt.Sprintf("at position %d", step.StepOrder),
+				step:     &step,
+			})
+			continue
+		}
+		matched[stored.ID] = true
+		step.ID = stored.ID
+		step.Version = stored.Version
+
+		detail := ""
+		if stored.Content != step.Content {
+			detail = "content changed"
+		}
+		if stored.WaitDays != step.WaitDays {
+			if detail != "" {
+				detail += ", "
+			}
+			detail += fmt.Sprintf("waitDays %d -> %d", stored.WaitDays, step.WaitDays)
+		}
+		if stored.EffectiveWaitUnit() != step.EffectiveWaitUnit() {
+			if detail != "" {
+				detail += ", "
+			}
+			detail += fmt.Sprintf("waitUnit %s -> %s", stored.EffectiveWaitUnit(), step.EffectiveWaitUnit())
+		}
+		switch {
+		case detail != "":
+			if stored.StepOrder != step.StepOrder {
+				detail += fmt.Sprintf(", position %d -> %d", stored.StepOrder, step.StepOrder)
+			}
+			changes = append(changes, PlanChange{Action: PlanUpdate, Resource: "step", Key: key, Subject: step.Subject, Detail: detail, step: &step, stored: &stored})
+		case stored.StepOrder != step.StepOrder:
+			detail = fmt.Sprintf("position %d -> %d", stored.StepOrder, step.StepOrder)
+			changes = append(changes, PlanChange{Action: PlanReorder, Resource: "step", Key: key, Subject: step.Subject, Detail: detail, step: &step, stored: &stored})
+		}
+	}
+
+	// Whatever is left over is no longer defined.
+	for _, stored := range current.Steps {
+		if matched[stored.ID] {
+			continue
+		}
+		stored := stored
+		changes = append(changes, PlanChange{Action: PlanDelete, Resource: "step", Key: key, Subject: stored.Subject, step: &stored})
+	}
+
+	return changes
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+	"sf_test/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func onboardingDefinition() SequenceDefinition {
+	return SequenceDefinition{
+		Key:  "onboarding",
+		Name: "Onboarding",
+		Steps: []StepDefinition{
+			{Subject: "Welcome", Content: "Hi there", WaitDays: 0},
+			{Subject: "Follow up", Content: "Any questions?", WaitDays: 2},
+		},
+	}
+}
+
+func TestBuildPlan_CreatesMissingSequence(t *testing.T) {
+	plan, err := buildPlan([]SequenceDefinition{onboardingDefinition()}, nil, nil, false)
+
+	assert.NoError(t, err)
+	assert.Len(t, plan.Changes, 1)
+	assert.Equal(t, PlanCreate, plan.Changes[0].Action)
+	assert.Equal(t, "sequence", plan.Changes[0].Resource)
+	assert.Equal(t, "onboarding", plan.Changes[0].sequence.ExternalKey)
+	assert.Equal(t, 1, plan.Changes[0].sequence.Steps[1].StepOrder)
+}
+
+func TestBuildPlan_DiffsExistingSequence(t *testing.T) {
+	existing := map[string]*models.Sequence{
+		"onboarding": {
+			ID:          7,
+			Name:        "Old name",
+			ExternalKey: "onboarding",
+			Steps: []models.Step{
+				{ID: 1, SequenceID: 7, Subject: "Follow up", Content: "Any questions?", StepOrder: 0, WaitDays: 2},
+				{ID: 2, SequenceID: 7, Subject: "Welcome", Content: "Hello", StepOrder: 1},
+				{ID: 3, SequenceID: 7, Subject: "Removed", Content: "Bye", StepOrder: 2},
+			},
+		},
+	}
+
+	plan, err := buildPlan([]SequenceDefinition{onboardingDefinition()}, existing, nil, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, plan.Count(PlanUpdate))
+	assert.Equal(t, 1, plan.Count(PlanReorder))
+	assert.Equal(t, 1, plan.Count(PlanDelete))
+	actions := map[string]PlanAction{}
+	for _, change := range plan.Changes {
+		actions[change.Resource+":"+change.Subject] = change.Action
+	}
+	assert.Equal(t, PlanUpdate, actions["sequence:"])
+	assert.Equal(t, PlanUpdate, actions["step:Welcome"])
+	assert.Equal(t, PlanReorder, actions["step:Follow up"])
+	assert.Equal(t, PlanDelete, actions["step:Removed"])
+}
+
+func TestBuildPlan_PrunesUndefinedSequences(t *testing.T) {
+	managed := []*models.Sequence{{ID: 9, ExternalKey: "legacy"}}
+
+	plan, err := buildPlan(nil, nil, managed, false)
+	assert.NoError(t, err)
+	assert.True(t, plan.Empty())
+
+	plan, err = buildPlan(nil, nil, managed, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, plan.Count(PlanDelete))
+	assert.Equal(t, "legacy", plan.Changes[0].Key)
+}
+
+func TestBuildPlan_RejectsInvalidDefinitions(t *testing.T) {
+	duplicate := onboardingDefinition()
+	_, err := buildPlan([]SequenceDefinition{onboardingDefinition(), duplicate}, nil, nil, false)
+	assert.Error(t, err)
+
+	missingKey := onboardingDefinition()
+	missingKey.Key = ""
+	_, err = buildPlan([]SequenceDefinition{missingKey}, nil, nil, false)
+	assert.Error(t, err)
+
+	repeatedSubject := onboardingDefinition()
+	repeatedSubject.Steps[1].Subject = "Welcome"
+	_, err = buildPlan([]SequenceDefinition{repeatedSubject}, nil, nil, false)
+	assert.Error(t, err)
+}
+
+func TestSequencePlanner_ApplyRecordsAudit(t *testing.T) {
+	store := db.NewMemoryStore()
+	audit := &fakeAuditRepository{}
+	planner := NewSequencePlanner(store, db.NewMemorySequenceRepository(store), db.NewMemoryStepRepository(store), audit)
+	ctx := tenant.WithWorkspace(context.Background(), 1)
+	type entry struct{ action, resource string }
+	applied := func() []entry {
+		var entries []entry
+		for _, e := range audit.entries {
+			assert.Equal(t, "system", e.Actor)
+			entries = append(entries, entry{e.Action, e.ResourceType})
+		}
+		audit.entries = nil
+		return entries
+	}
+
+	_, err := planner.Apply(ctx, []SequenceDefinition{onboardingDefinition()}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []entry{
+		{models.AuditActionCreate, models.AuditResourceSequence},
+		{models.AuditActionCreate, models.AuditResourceStep},
+		{models.AuditActionCreate, models.AuditResourceStep},
+	}, applied())
+
+	changed := onboardingDefinition()
+	changed.Name = "Welcome aboard"
+	changed.Steps = changed.Steps[1:]
+	_, err = planner.Apply(ctx, []SequenceDefinition{changed}, false)
+	assert.NoError(t, err)
+	if assert.Len(t, audit.entries, 3) {
+		assert.Contains(t, audit.entries[0].Changes, "name")
+		assert.Len(t, audit.entries[0].Changes, 1)
+		assert.Contains(t, audit.entries[1].Changes, "stepOrder")
+		assert.Len(t, audit.entries[1].Changes, 1)
+	}
+	assert.Equal(t, []entry{
+		{models.AuditActionUpdate, models.AuditResourceSequence},
+		{models.AuditActionUpdate, models.AuditResourceStep},
+		{models.AuditActionDelete, models.AuditResourceStep},
+	}, applied())
+
+	_, err = planner.Apply(ctx, nil, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []entry{
+		{models.AuditActionDelete, models.AuditResourceStep},
+		{models.AuditActionDelete, models.AuditResourceSequence},
+	}, applied())
+}
@@ -2,43 +2,312 @@ package core
 
 import (
 	"context"
-	"database/sql"
-	"errors"
+	"fmt"
+	"sf_test/internal/clock"
 	"sf_test/internal/db"
 	"sf_test/internal/models"
+	"time"
 )
 
 type sequenceService struct {
-	repo db.SequenceRepository
+	tx          db.Transactor
+	repo        db.SequenceRepository
+	stepRepo    db.StepRepository
+	auditRepo   db.AuditRepository
+	holidayRepo db.HolidayRepository
+	authorizer  Authorizer
+	clock       clock.Clock
 }
 
-func NewSequenceService(repo db.SequenceRepository) SequenceService {
-	return &sequenceService{repo: repo}
+func NewSequenceService(tx db.Transactor, repo db.SequenceRepository, stepRepo db.StepRepository, auditRepo db.AuditRepository, holidayRepo db.HolidayRepository, authorizer Authorizer, clk clock.Clock) SequenceService {
+	return &sequenceService{tx: tx, repo: repo, stepRepo: stepRepo, auditRepo: auditRepo, holidayRepo: holidayRepo, authorizer: authorizer, clock: clk}
 }
 
 func (s *sequenceService) CreateSequence(ctx context.Context, sequence *models.Sequence) (int64, error) {
 	// Validate the sequence model
 	if err := sequence.Validate(); err != nil {
+		return 0, newValidationError(err)
+	}
+	if err := s.authorizer.Authorize(ctx, ActionEdit, sequence.TeamID); err != nil {
 		return 0, err
 	}
 
 	// Save the sequence to the repository
-	return s.repo.Create(ctx, sequence)
+	var id int64
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if id, err = s.repo.Create(ctx, sequence); err != nil {
+			return err
+		}
+		sequence.ID = id
+		if err := recordAudit(ctx, s.auditRepo, models.AuditActionCreate, models.AuditResourceSequence, id, nil, sequence); err != nil {
+			return err
+		}
+		for _, step := range sequence.Steps {
+			if err := recordAudit(ctx, s.auditRepo, models.AuditActionCreate, models.AuditResourceStep, step.ID, nil, step); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, translateError(err, "sequence", 0)
+	}
+	return id, nil
 }
 
-func (s *sequenceService) UpdateTracking(ctx context.Context, id int64, openTracking, clickTracking bool) error {
-	// Update tracking flags in the repository
-	return s.repo.UpdateTracking(ctx, id, openTracking, clickTracking)
+// UpdateTracking updates the tracking flags and returns the new version. A
+// non-zero version fails with PreconditionFailedError if the sequence has
+// changed since that version.
+func (s *sequenceService) UpdateTracking(ctx context.Context, id int64, openTracking, clickTracking bool, version int64) (int64, error) {
+	var newVersion int64
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := authorizeSequence(ctx, s.authorizer, s.repo, ActionEdit, id)
+		if err != nil {
+			return err
+		}
+
+		// Update tracking flags in the repository
+		if newVersion, err = s.repo.UpdateTracking(ctx, id, openTracking, clickTracking, version); err != nil {
+			return err
+		}
+		updated := *current
+		updated.OpenTrackingEnabled = openTracking
+		updated.ClickTrackingEnabled = clickTracking
+		return recordAudit(ctx, s.auditRepo, models.AuditActionUpdate, models.AuditResourceSequence, id, current, &updated)
+	})
+	if err != nil {
+		return 0, translateError(err, "sequence", id)
+	}
+	return newVersion, nil
 }
 
 func (s *sequenceService) GetSequence(ctx context.Context, id int64) (*models.Sequence, error) {
 	// Retrieve the sequence from the repository
-	sequence, err := s.repo.Get(ctx, id)
+	return authorizeSequence(ctx, s.authorizer, s.repo, ActionRead, id)
+}
+
+// sequencePatchFields are the sequence fields a merge patch may change. Steps
+// are edited through the step endpoints.
+var sequencePatchFields = []string{"name", "externalKey", "openTrackingEnabled", "clickTrackingEnabled"}
+
+// PatchSequence applies an RFC 7396 merge patch to the sequence. A non-zero
+// version fails with PreconditionFailedError if the sequence has changed
+// since that version.
+func (s *sequenceService) PatchSequence(ctx context.Context, id int64, patch []byte, version int64) (*models.Sequence, error) {
+	current, err := authorizeSequence(ctx, s.authorizer, s.repo, ActionEdit, id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != current.Version {
+		return nil, translateError(db.ErrVersionMismatch, "sequence", id)
+	}
+
+	var sequence models.Sequence
+	if err := applyPatch(current, patch, sequencePatchFields, &sequence); err != nil {
+		return nil, err
+	}
+	if err := sequence.Validate(); err != nil {
+		return nil, newValidationError(err)
+	}
+
+	// The update is conditional on the version that was patched, so a
+	// concurrent change is reported rather than overwritten.
+	sequence.ID = current.ID
+	sequence.Version = current.Version
+	sequence.TeamID = current.TeamID
+	sequence.Active = current.Active
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, &sequence); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, models.AuditActionUpdate, models.AuditResourceSequence, id, current, &sequence)
+	})
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("sequence not found")
+		return nil, translateError(err, "sequence", id)
+	}
+	return &sequence, nil
+}
+
+func (s *sequenceService) ListSequences(ctx context.Context, opts models.ListOptions) ([]*models.Sequence, string, error) {
+	if err := s.authorizer.Authorize(ctx, ActionRead, nil); err != nil {
+		return nil, "", err
+	}
+
+	// Retrieve a page of sequences from the repository
+	sequences, next, err := s.repo.List(ctx, opts)
+	if err != nil {
+		return nil, "", translateError(err, "sequence", 0)
+	}
+	return sequences, next, nil
+}
+
+// SetSequenceActive activates or deactivates the sequence and returns the new
+// version. Only owners of the sequence's team may do so. A non-zero version
+// fails with PreconditionFailedError if the sequence has changed since that
+// version.
+func (s *sequenceService) SetSequenceActive(ctx context.Context, id int64, active bool, version int64) (int64, error) {
+	var newVersion int64
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := authorizeSequence(ctx, s.authorizer, s.repo, ActionManage, id)
+		if err != nil {
+			return err
+		}
+		if newVersion, err = s.repo.SetActive(ctx, id, active, version); err != nil {
+			return err
+		}
+		updated := *current
+		updated.Active = active
+		return recordAudit(ctx, s.auditRepo, models.AuditActionUpdate, models.AuditResourceSequence, id, current, &updated)
+	})
+	if err != nil {
+		return 0, translateError(err, "sequence", id)
+	}
+	return newVersion, nil
+}
+
+// DeleteSequence deletes the sequence and its steps. Only owners of the
+// sequence's team may do so. A non-zero version fails with
+// PreconditionFailedError if the sequence has changed since that version.
+func (s *sequenceService) DeleteSequence(ctx context.Context, id int64, version int64) error {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := authorizeSequence(ctx, s.authorizer, s.repo, ActionManage, id)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		// The sequence's steps are deleted with it.
+		for _, step := range current.Steps {
+			if err := recordAudit(ctx, s.auditRepo, models.AuditActionDelete, models.AuditResourceStep, step.ID, step, nil); err != nil {
+				return err
+			}
 		}
+		return recordAudit(ctx, s.auditRepo, models.AuditActionDelete, models.AuditResourceSequence, id, current, nil)
+	})
+	return translateError(err, "sequence", id)
+}
+
+func (s *sequenceService) ExportSequence(ctx context.Context, id int64) (*models.SequenceBundle, error) {
+	sequence, err := s.GetSequence(ctx, id)
+	if err != nil {
 		return nil, err
 	}
-	return sequence, nil
+	return models.NewSequenceBundle(sequence, s.clock.Now()), nil
+}
+
+// ForecastSequence simulates sending the sequence to req.ContactCount
+// contacts in req.Country from req.StartDate through req.Mailboxes, without
+// changing anything.
+func (s *sequenceService) ForecastSequence(ctx context.Context, id int64, req *models.ForecastRequest) (*models.Forecast, error) {
+	if err := req.Validate(); err != nil {
+		return nil, newValidationError(err)
+	}
+	sequence, err := authorizeSequence(ctx, s.authorizer, s.repo, ActionRead, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(sequence.Steps) == 0 {
+		return nil, &ValidationError{Message: "sequence has no steps to forecast"}
+	}
+
+	now := s.clock.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if req.StartDate != "" {
+		if start, err = time.Parse(models.ForecastDateLayout, req.StartDate); err != nil {
+			return nil, newValidationError(err)
+		}
+	}
+	capacity := 0
+	for _, mailbox := range req.Mailboxes {
+		capacity += mailbox.DailyLimit
+	}
+
+	cal, err := holidayCalendar(ctx, s.holidayRepo, req.Country, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast := forecastSends(sequence.Steps, req.ContactCount, start, capacity, cal)
+	forecast.SequenceID = id
+	return forecast, nil
+}
+
+func (s *sequenceService) ImportSequence(ctx context.Context, bundle *models.SequenceBundle, dryRun bool) (*models.ImportResult, error) {
+	if bundle.SchemaVersion != models.SequenceBundleSchemaVersion {
+		return nil, &ValidationError{
+			Message: fmt.Sprintf("unsupported bundle schema version %d", bundle.SchemaVersion),
+			Fields:  []FieldError{{Field: "schemaVersion", Rule: "eq", Message: fmt.Sprintf("schemaVersion must be %d", models.SequenceBundleSchemaVersion)}},
+		}
+	}
+
+	// Validate the sequence the bundle describes
+	sequence := bundle.ToSequence()
+	if err := sequence.Validate(); err != nil {
+		return nil, newValidationError(err)
+	}
+	if err := s.authorizer.Authorize(ctx, ActionEdit, sequence.TeamID); err != nil {
+		return nil, err
+	}
+
+	result := &models.ImportResult{
+		DryRun:   dryRun,
+		Sequence: models.ImportedResource{SourceID: bundle.Sequence.SourceID, Name: sequence.Name},
+		Steps:    []models.ImportedResource{},
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if sequence.ExternalKey != "" {
+			_, err := s.repo.GetByExternalKey(ctx, sequence.ExternalKey)
+			if err == nil {
+				return &ConflictError{Message: fmt.Sprintf("a sequence with external key %q already exists", sequence.ExternalKey)}
+			}
+			if !isNotFound(err) {
+				return err
+			}
+		}
+
+		if dryRun {
+			for i, step := range sequence.Steps {
+				result.Steps = append(result.Steps, models.ImportedResource{SourceID: bundle.Sequence.Steps[i].SourceID, Name: step.Subject})
+			}
+			return nil
+		}
+
+		// Create the steps separately so their new IDs can be reported
+		steps := sequence.Steps
+		sequence.Steps = nil
+		id, err := s.repo.Create(ctx, sequence)
+		if err != nil {
+			return err
+		}
+		result.Sequence.ID = id
+		sequence.ID = id
+		if err := recordAudit(ctx, s.auditRepo, models.AuditActionCreate, models.AuditResourceSequence, id, nil, sequence); err != nil {
+			return err
+		}
+
+		for i := range steps {
+			steps[i].SequenceID = id
+			stepID, err := s.stepRepo.Create(ctx, &steps[i])
+			if err != nil {
+				return err
+			}
+			steps[i].ID = stepID
+			if err := recordAudit(ctx, s.auditRepo, models.AuditActionCreate, models.AuditResourceStep, stepID, nil, &steps[i]); err != nil {
+				return err
+			}
+			result.Steps = append(result.Steps, models.ImportedResource{
+				SourceID: bundle.Sequence.Steps[i].SourceID,
+				ID:       stepID,
+				Name:     steps[i].Subject,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, translateError(err, "sequence", 0)
+	}
+	return result, nil
 }
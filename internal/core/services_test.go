@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"sf_test/internal/clock"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+	"sf_test/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newInMemoryServices wires the sequence and step services to in-memory
+// repositories sharing one store.
+func newInMemoryServices(audit db.AuditRepository) (SequenceService, StepService) {
+	store := db.NewMemoryStore()
+	sequences := db.NewMemorySequenceRepository(store)
+	steps := db.NewMemoryStepRepository(store)
+	authorizer := testAuthorizer()
+	return NewSequenceService(store, sequences, steps, audit, &fakeHolidayRepository{}, authorizer, clock.System), NewStepService(store, steps, sequences, audit, authorizer)
+}
+
+func TestServices_InMemory(t *testing.T) {
+	sequenceService, stepService := newInMemoryServices(&fakeAuditRepository{})
+	ctx := tenant.WithWorkspace(context.Background(), 1)
+
+	id, err := sequenceService.CreateSequence(ctx, &models.Sequence{
+		Name:  "Onboarding",
+		Steps: []models.Step{{Subject: "Welcome", Content: "Hello", StepOrder: 0}},
+	})
+	assert.NoError(t, err)
+
+	stepID, err := stepService.CreateStep(ctx, &models.Step{SequenceID: id, Subject: "Checking in", Content: "Any questions?", StepOrder: 1, WaitDays: 2})
+	assert.NoError(t, err)
+	err = stepService.UpdateStep(ctx, &models.Step{ID: stepID, Subject: "Still there?", Content: "Any questions?", StepOrder: 1, Version: 5})
+	var preconditionFailed *PreconditionFailedError
+	assert.True(t, errors.As(err, &preconditionFailed))
+
+	sequence, err := sequenceService.GetSequence(ctx, id)
+	assert.NoError(t, err)
+	if assert.Len(t, sequence.Steps, 2) {
+		assert.Equal(t, "Checking in", sequence.Steps[1].Subject)
+	}
+
+	_, err = stepService.CreateStep(ctx, &models.Step{SequenceID: id + 100, Subject: "Orphan", Content: "Nobody reads this"})
+	var notFound *NotFoundError
+	assert.True(t, errors.As(err, &notFound))
+
+	assert.NoError(t, sequenceService.DeleteSequence(ctx, id, sequence.Version))
+	_, err = sequenceService.GetSequence(ctx, id)
+	assert.True(t, errors.As(err, &notFound))
+}
+
+func TestServices_InMemoryRollsBackWhenAuditFails(t *testing.T) {
+	sequenceService, _ := newInMemoryServices(&fakeAuditRepository{err: errors.New("audit log unavailable")})
+	ctx := tenant.WithWorkspace(context.Background(), 1)
+
+	_, err := sequenceService.CreateSequence(ctx, &models.Sequence{Name: "Onboarding"})
+	assert.Error(t, err)
+
+	sequences, _, err := sequenceService.ListSequences(ctx, models.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, sequences)
+}
+
+func onboardingBundle() *models.SequenceBundle {
+	return &models.SequenceBundle{
+		SchemaVersion: models.SequenceBundleSchemaVersion,
+		Sequence: models.BundleSequence{
+			SourceID:    41,
+			Name:        "Onboarding",
+			ExternalKey: "onboarding",
+			Steps: []models.BundleStep{
+				{SourceID: 90, Subject: "Welcome", Content: "Hello", StepOrder: 0},
+				{SourceID: 91, Subject: "Checking in", Content: "Any questions?", StepOrder: 1, WaitDays: 2},
+			},
+		},
+	}
+}
+
+func TestSequenceService_ImportSequence(t *testing.T) {
+	audit := &fakeAuditRepository{}
+	sequenceService, _ := newInMemoryServices(audit)
+	ctx := tenant.WithWorkspace(context.Background(), 1)
+
+	t.Run("rejects other schema versions", func(t *testing.T) {
+		bundle := onboardingBundle()
+		bundle.SchemaVersion = models.SequenceBundleSchemaVersion + 1
+		_, err := sequenceService.ImportSequence(ctx, bundle, false)
+		var validation *ValidationError
+		if assert.ErrorAs(t, err, &validation) {
+			assert.Equal(t, "schemaVersion", validation.Fields[0].Field)
+		}
+	})
+
+	t.Run("dry run writes nothing", func(t *testing.T) {
+		result, err := sequenceService.ImportSequence(ctx, onboardingBundle(), true)
+		assert.NoError(t, err)
+		assert.True(t, result.DryRun)
+		assert.Zero(t, result.Sequence.ID)
+		assert.Equal(t, []models.ImportedResource{
+			{SourceID: 90, Name: "Welcome"},
+			{SourceID: 91, Name: "Checking in"},
+		}, result.Steps)
+
+		sequences, _, err := sequenceService.ListSequences(ctx, models.ListOptions{})
+		assert.NoError(t, err)
+		assert.Empty(t, sequences)
+		assert.Empty(t, audit.entries)
+	})
+
+	t.Run("maps source IDs to new IDs", func(t *testing.T) {
+		result, err := sequenceService.ImportSequence(ctx, onboardingBundle(), false)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(41), result.Sequence.SourceID)
+		assert.NotZero(t, result.Sequence.ID)
+
+		sequence, err := sequenceService.GetSequence(ctx, result.Sequence.ID)
+		assert.NoError(t, err)
+		if assert.Len(t, sequence.Steps, 2) && assert.Len(t, result.Steps, 2) {
+			for i, step := range sequence.Steps {
+				assert.Equal(t, models.ImportedResource{SourceID: int64(90 + i), ID: step.ID, Name: step.Subject}, result.Steps[i])
+			}
+			assert.Equal(t, 2, sequence.Steps[1].WaitDays)
+		}
+		assert.Len(t, audit.entries, 3)
+	})
+
+	t.Run("rejects an existing external key", func(t *testing.T) {
+		for _, dryRun := range []bool{true, false} {
+			_, err := sequenceService.ImportSequence(ctx, onboardingBundle(), dryRun)
+			var conflict *ConflictError
+			assert.ErrorAs(t, err, &conflict, "dry run %t", dryRun)
+		}
+
+		sequences, _, err := sequenceService.ListSequences(ctx, models.ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, sequences, 1)
+	})
+}
@@ -7,43 +7,143 @@ import (
 )
 
 type stepService struct {
-	repo db.StepRepository
+	tx           db.Transactor
+	repo         db.StepRepository
+	sequenceRepo db.SequenceRepository
+	auditRepo    db.AuditRepository
+	authorizer   Authorizer
 }
 
-func NewStepService(repo db.StepRepository) StepService {
-	return &stepService{repo: repo}
+func NewStepService(tx db.Transactor, repo db.StepRepository, sequenceRepo db.SequenceRepository, auditRepo db.AuditRepository, authorizer Authorizer) StepService {
+	return &stepService{tx: tx, repo: repo, sequenceRepo: sequenceRepo, auditRepo: auditRepo, authorizer: authorizer}
+}
+
+// authorizeStep loads the step and checks that the caller may act on its
+// sequence.
+func (s *stepService) authorizeStep(ctx context.Context, action Action, id int64) (*models.Step, error) {
+	step, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "step", id)
+	}
+	if _, err := authorizeSequence(ctx, s.authorizer, s.sequenceRepo, action, step.SequenceID); err != nil {
+		return nil, err
+	}
+	return step, nil
 }
 
 func (s *stepService) CreateStep(ctx context.Context, step *models.Step) (int64, error) {
 	// Validate the step model
 	if err := step.Validate(); err != nil {
+		return 0, newValidationError(err)
+	}
+	if _, err := authorizeSequence(ctx, s.authorizer, s.sequenceRepo, ActionEdit, step.SequenceID); err != nil {
 		return 0, err
 	}
 
 	// Save the step to the repository
-	return s.repo.Create(ctx, step)
+	var id int64
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if id, err = s.repo.Create(ctx, step); err != nil {
+			return err
+		}
+		step.ID = id
+		return recordAudit(ctx, s.auditRepo, models.AuditActionCreate, models.AuditResourceStep, id, nil, step)
+	})
+	if err != nil {
+		return 0, translateError(err, "step", 0)
+	}
+	return id, nil
 }
 
+// UpdateStep saves the step. A non-zero step.Version is the version the caller
+// last read; the update fails with PreconditionFailedError if it is stale.
 func (s *stepService) UpdateStep(ctx context.Context, step *models.Step) error {
 	// Validate the step model
 	if err := step.Validate(); err != nil {
-		return err
+		return newValidationError(err)
 	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.authorizeStep(ctx, ActionEdit, step.ID)
+		if err != nil {
+			return err
+		}
 
-	// Update the step in the repository
-	return s.repo.Update(ctx, step)
-}
+		// Steps cannot move between sequences.
+		step.SequenceID = current.SequenceID
 
-func (s *stepService) DeleteStep(ctx context.Context, id int64) error {
-	// Delete the step from the repository
-	return s.repo.Delete(ctx, id)
+		// Update the step in the repository
+		if err := s.repo.Update(ctx, step); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, models.AuditActionUpdate, models.AuditResourceStep, step.ID, current, step)
+	})
+	return translateError(err, "step", step.ID)
 }
 
-func (s *stepService) ListSteps(ctx context.Context, sequenceID int64) ([]*models.Step, error) {
-	// Retrieve steps for the given sequence ID
-	steps, err := s.repo.ListBySequenceID(ctx, sequenceID)
+// stepPatchFields are the step fields a merge patch may change.
+var stepPatchFields = []string{"subject", "content", "stepOrder", "waitDays", "waitUnit"}
+
+// PatchStep applies an RFC 7396 merge patch to the step. A non-zero version
+// fails with PreconditionFailedError if the step has changed since that version.
+func (s *stepService) PatchStep(ctx context.Context, id int64, patch []byte, version int64) (*models.Step, error) {
+	current, err := s.authorizeStep(ctx, ActionEdit, id)
 	if err != nil {
 		return nil, err
 	}
-	return steps, nil
+	if version != 0 && version != current.Version {
+		return nil, translateError(db.ErrVersionMismatch, "step", id)
+	}
+
+	var step models.Step
+	if err := applyPatch(current, patch, stepPatchFields, &step); err != nil {
+		return nil, err
+	}
+	if err := step.Validate(); err != nil {
+		return nil, newValidationError(err)
+	}
+
+	// The update is conditional on the version that was patched, so a
+	// concurrent change is reported rather than overwritten.
+	step.ID = current.ID
+	step.Version = current.Version
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, &step); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, models.AuditActionUpdate, models.AuditResourceStep, id, current, &step)
+	})
+	if err != nil {
+		return nil, translateError(err, "step", id)
+	}
+	return &step, nil
+}
+
+func (s *stepService) DeleteStep(ctx context.Context, id int64, version int64) error {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.authorizeStep(ctx, ActionEdit, id)
+		if err != nil {
+			return err
+		}
+
+		// Delete the step from the repository
+		if err := s.repo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, models.AuditActionDelete, models.AuditResourceStep, id, current, nil)
+	})
+	return translateError(err, "step", id)
+}
+
+func (s *stepService) ListSteps(ctx context.Context, sequenceID int64, opts models.ListOptions) ([]*models.Step, string, error) {
+	if _, err := authorizeSequence(ctx, s.authorizer, s.sequenceRepo, ActionRead, sequenceID); err != nil {
+		return nil, "", err
+	}
+
+	// Retrieve a page of steps for the given sequence ID
+	steps, next, err := s.repo.List(ctx, sequenceID, opts)
+	if err != nil {
+		return nil, "", translateError(err, "step", 0)
+	}
+	return steps, next, nil
 }
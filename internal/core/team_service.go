@@ -0,0 +1,117 @@
+package core
+
+import (
+	"context"
+	"sf_test/internal/auth"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+)
+
+type teamService struct {
+	userRepo   db.UserRepository
+	teamRepo   db.TeamRepository
+	authorizer Authorizer
+}
+
+func NewTeamService(userRepo db.UserRepository, teamRepo db.TeamRepository, authorizer Authorizer) TeamService {
+	return &teamService{userRepo: userRepo, teamRepo: teamRepo, authorizer: authorizer}
+}
+
+// CreateUser registers the user signing in as user.Subject. Users acting
+// through tokens must own a team to add users.
+func (s *teamService) CreateUser(ctx context.Context, user *models.User) (int64, error) {
+	if err := user.Validate(); err != nil {
+		return 0, newValidationError(err)
+	}
+	if err := s.authorizer.Authorize(ctx, ActionManage, nil); err != nil {
+		return 0, err
+	}
+	id, err := s.userRepo.Create(ctx, user)
+	if err != nil {
+		return 0, translateError(err, "user", 0)
+	}
+	return id, nil
+}
+
+func (s *teamService) ListUsers(ctx context.Context) ([]*models.User, error) {
+	if err := s.authorizer.Authorize(ctx, ActionRead, nil); err != nil {
+		return nil, err
+	}
+	return s.userRepo.List(ctx)
+}
+
+// CreateTeam creates a team. Users acting through tokens must own a team to
+// create another.
+func (s *teamService) CreateTeam(ctx context.Context, team *models.Team) (int64, error) {
+	if err := team.Validate(); err != nil {
+		return 0, newValidationError(err)
+	}
+	if err := s.authorizer.Authorize(ctx, ActionManage, nil); err != nil {
+		return 0, err
+	}
+	id, err := s.teamRepo.Create(ctx, team)
+	if err != nil {
+		return 0, translateError(err, "team", 0)
+	}
+	return id, nil
+}
+
+func (s *teamService) ListTeams(ctx context.Context) ([]*models.Team, error) {
+	if err := s.authorizer.Authorize(ctx, ActionRead, nil); err != nil {
+		return nil, err
+	}
+	return s.teamRepo.List(ctx)
+}
+
+// SetMember adds a user to a team or changes their role. Only the team's
+// owners and API keys without a user may change its members, so a key
+// created from the command line can add a new team's first owner.
+func (s *teamService) SetMember(ctx context.Context, member *models.TeamMember) error {
+	if err := member.Validate(); err != nil {
+		return newValidationError(err)
+	}
+	if err := s.authorizeMembers(ctx, member.TeamID); err != nil {
+		return err
+	}
+	if _, err := s.userRepo.Get(ctx, member.UserID); err != nil {
+		return translateError(err, "user", member.UserID)
+	}
+	return translateError(s.teamRepo.SetMember(ctx, member), "team member", member.UserID)
+}
+
+// RemoveMember removes a user from a team. Only the team's owners and API
+// keys without a user may change its members.
+func (s *teamService) RemoveMember(ctx context.Context, teamID, userID int64) error {
+	if err := s.authorizeMembers(ctx, teamID); err != nil {
+		return err
+	}
+	return translateError(s.teamRepo.RemoveMember(ctx, teamID, userID), "team member", userID)
+}
+
+func (s *teamService) ListMembers(ctx context.Context, teamID int64) ([]*models.TeamMember, error) {
+	if err := s.authorizeTeam(ctx, ActionRead, teamID); err != nil {
+		return nil, err
+	}
+	return s.teamRepo.ListMembers(ctx, teamID)
+}
+
+// authorizeTeam checks that the team exists and that the caller may act on it.
+func (s *teamService) authorizeTeam(ctx context.Context, action Action, teamID int64) error {
+	if _, err := s.teamRepo.Get(ctx, teamID); err != nil {
+		return translateError(err, "team", teamID)
+	}
+	return s.authorizer.Authorize(ctx, action, &teamID)
+}
+
+// authorizeMembers checks that the caller may change the team's members.
+// API keys without a user holding the teams:admin scope may change any
+// team's members; everyone else must own the team.
+func (s *teamService) authorizeMembers(ctx context.Context, teamID int64) error {
+	if principal, ok := auth.PrincipalFrom(ctx); ok && principal.UserID == "" && principal.HasScope(auth.ScopeTeamsAdmin) {
+		if _, err := s.teamRepo.Get(ctx, teamID); err != nil {
+			return translateError(err, "team", teamID)
+		}
+		return nil
+	}
+	return s.authorizeTeam(ctx, ActionManage, teamID)
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"sf_test/internal/auth"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeUserRepository struct {
+	db.UserRepository
+	users map[int64]*models.User
+}
+
+func (r *fakeUserRepository) Get(ctx context.Context, id int64) (*models.User, error) {
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+// fakeMemberRepository keeps the memberships set through it, keyed by the
+// subject of each user.
+type fakeMemberRepository struct {
+	fakeTeamRepository
+	users *fakeUserRepository
+	teams map[int64]*models.Team
+}
+
+func (r *fakeMemberRepository) Get(ctx context.Context, id int64) (*models.Team, error) {
+	if team, ok := r.teams[id]; ok {
+		return team, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeMemberRepository) SetMember(ctx context.Context, member *models.TeamMember) error {
+	subject := r.users.users[member.UserID].Subject
+	r.memberships[subject] = append(r.memberships[subject], member)
+	return nil
+}
+
+func TestTeamService_AddsFirstOwner(t *testing.T) {
+	users := &fakeUserRepository{users: map[int64]*models.User{
+		1: {ID: 1, Subject: "alice"},
+		2: {ID: 2, Subject: "bob"},
+	}}
+	teams := &fakeMemberRepository{
+		fakeTeamRepository: fakeTeamRepository{memberships: map[string][]*models.TeamMember{}},
+		users:              users,
+		teams:              map[int64]*models.Team{1: {ID: 1, Name: "Growth"}},
+	}
+	service := NewTeamService(users, teams, NewAuthorizer(teams))
+
+	// Nobody owns the new team, so no user may add its members.
+	err := service.SetMember(asUser("alice"), &models.TeamMember{TeamID: 1, UserID: 1, Role: models.RoleOwner})
+	assert.True(t, isForbidden(err), "got %v", err)
+
+	// A key without a user holding teams:admin adds the first owner.
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{
+		Subject:  "api_key:1",
+		APIKeyID: 1,
+		Scopes:   []string{auth.ScopeTeamsAdmin},
+	})
+	assert.NoError(t, service.SetMember(admin, &models.TeamMember{TeamID: 1, UserID: 1, Role: models.RoleOwner}))
+	assert.True(t, isNotFound(service.SetMember(admin, &models.TeamMember{TeamID: 2, UserID: 1, Role: models.RoleOwner})))
+
+	// The owner then manages the team's members.
+	assert.NoError(t, service.SetMember(asUser("alice"), &models.TeamMember{TeamID: 1, UserID: 2, Role: models.RoleEditor}))
+	assert.Equal(t, models.RoleEditor, teams.memberships["bob"][0].Role)
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sf_test/internal/auth"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+	"sync"
+)
+
+// slugPattern is the form of workspace slugs: lowercase words joined by dashes.
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type workspaceService struct {
+	repo db.WorkspaceRepository
+	// ids caches workspace IDs by slug. Workspaces are never renamed, so
+	// entries do not go stale.
+	ids sync.Map
+}
+
+func NewWorkspaceService(repo db.WorkspaceRepository) WorkspaceService {
+	return &workspaceService{repo: repo}
+}
+
+func (s *workspaceService) CreateWorkspace(ctx context.Context, slug, name string) (*models.Workspace, error) {
+	workspace := &models.Workspace{Slug: slug, Name: name}
+	if err := workspace.Validate(); err != nil {
+		return nil, newValidationError(err)
+	}
+	if !slugPattern.MatchString(slug) {
+		return nil, &ValidationError{
+			Message: fmt.Sprintf("invalid slug %q", slug),
+			Fields:  []FieldError{{Field: "slug", Rule: "slug", Message: "slug must be lowercase letters and digits separated by dashes"}},
+		}
+	}
+	if _, err := s.repo.Create(ctx, workspace); err != nil {
+		return nil, translateError(err, "workspace", 0)
+	}
+	return workspace, nil
+}
+
+func (s *workspaceService) ListWorkspaces(ctx context.Context) ([]*models.Workspace, error) {
+	return s.repo.List(ctx)
+}
+
+// Resolve returns the workspace the principal acts in. API keys belong to a
+// workspace; tokens name theirs by slug.
+func (s *workspaceService) Resolve(ctx context.Context, principal *auth.Principal) (int64, error) {
+	if principal.WorkspaceID != 0 {
+		return principal.WorkspaceID, nil
+	}
+	if principal.Workspace == "" {
+		return 0, &ForbiddenError{Message: "credentials are not bound to a workspace"}
+	}
+	if id, ok := s.ids.Load(principal.Workspace); ok {
+		return id.(int64), nil
+	}
+
+	workspace, err := s.repo.GetBySlug(ctx, principal.Workspace)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, &ForbiddenError{Message: fmt.Sprintf("unknown workspace %q", principal.Workspace)}
+	}
+	if err != nil {
+		return 0, err
+	}
+	s.ids.Store(workspace.Slug, workspace.ID)
+	return workspace.ID, nil
+}
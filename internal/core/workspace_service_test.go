@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"sf_test/internal/auth"
+	"sf_test/internal/db"
+	"sf_test/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeWorkspaceRepository struct {
+	db.WorkspaceRepository
+	workspaces []*models.Workspace
+	lookups    int
+}
+
+func (r *fakeWorkspaceRepository) GetBySlug(ctx context.Context, slug string) (*models.Workspace, error) {
+	r.lookups++
+	for _, workspace := range r.workspaces {
+		if workspace.Slug == slug {
+			return workspace, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeWorkspaceRepository) List(ctx context.Context) ([]*models.Workspace, error) {
+	return r.workspaces, nil
+}
+
+func TestWorkspaceService_Resolve(t *testing.T) {
+	repo := &fakeWorkspaceRepository{workspaces: []*models.Workspace{{ID: 7, Slug: "acme"}}}
+	service := NewWorkspaceService(repo)
+	ctx := context.Background()
+
+	// API keys carry their workspace.
+	id, err := service.Resolve(ctx, &auth.Principal{WorkspaceID: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), id)
+	assert.Equal(t, 0, repo.lookups)
+
+	// Tokens name theirs by slug, which is looked up once.
+	for i := 0; i < 2; i++ {
+		id, err = service.Resolve(ctx, &auth.Principal{Workspace: "acme"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), id)
+	}
+	assert.Equal(t, 1, repo.lookups)
+}
+
+func TestWorkspaceService_ResolveRejectsUnknownWorkspaces(t *testing.T) {
+	service := NewWorkspaceService(&fakeWorkspaceRepository{})
+
+	for _, principal := range []*auth.Principal{{Workspace: "globex"}, {Subject: "user:1"}} {
+		_, err := service.Resolve(context.Background(), principal)
+		var forbidden *ForbiddenError
+		assert.True(t, errors.As(err, &forbidden), "got %v", err)
+	}
+}
+
+func TestWorkspaceService_CreateWorkspaceValidatesSlug(t *testing.T) {
+	service := NewWorkspaceService(&fakeWorkspaceRepository{})
+
+	_, err := service.CreateWorkspace(context.Background(), "Acme Brand", "Acme")
+
+	var validation *ValidationError
+	assert.True(t, errors.As(err, &validation))
+	assert.Equal(t, "slug", validation.Fields[0].Field)
+}
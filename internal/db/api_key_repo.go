@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"sf_test/internal/models"
+
+	"github.com/lib/pq"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) (int64, error)
+	GetByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	List(ctx context.Context) ([]*models.APIKey, error)
+	Revoke(ctx context.Context, id int64) error
+	TouchLastUsed(ctx context.Context, id int64) error
+}
+
+type apiKeyRepo struct {
+	db *DB
+}
+
+func NewAPIKeyRepository(db *DB) APIKeyRepository {
+	return &apiKeyRepo{db: db}
+}
+
+const apiKeyColumns = `id, workspace_id, name, prefix, key_hash, scopes, user_subject, created_at, last_used_at, revoked_at`
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	err := row.Scan(&key.ID, &key.WorkspaceID, &key.Name, &key.Prefix, &key.Hash, pq.Array(&key.Scopes), &key.UserID, &key.CreatedAt, &key.LastUsedAt, &key.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepo) Create(ctx context.Context, key *models.APIKey) (int64, error) {
+	query := `
+        INSERT INTO api_keys (workspace_id, name, prefix, key_hash, scopes, user_subject, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW()) RETURNING id, workspace_id, created_at
+    `
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		return r.db.querier(ctx).QueryRowContext(ctx, query, workspaceID, key.Name, key.Prefix, key.Hash, pq.Array(key.Scopes), key.UserID).Scan(&key.ID, &key.WorkspaceID, &key.CreatedAt)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return key.ID, nil
+}
+
+// GetByHash returns the key stored under hash, including revoked keys, from
+// any workspace. Authentication uses it to find the workspace of a request.
+func (r *apiKeyRepo) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	ctx, cancel := r.db.withQueryTimeout(ctx)
+	defer cancel()
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+	return scanAPIKey(r.db.querier(ctx).QueryRowContext(ctx, query, hash))
+}
+
+func (r *apiKeyRepo) List(ctx context.Context) ([]*models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE workspace_id = $1 ORDER BY created_at DESC, id DESC`
+	keys := []*models.APIKey{}
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		rows, err := r.db.querier(ctx).QueryContext(ctx, query, workspaceID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			key, err := scanAPIKey(rows)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, key)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepo) Revoke(ctx context.Context, id int64) error {
+	query := `
+        UPDATE api_keys
+        SET revoked_at = NOW()
+        WHERE id = $1 AND workspace_id = $2 AND revoked_at IS NULL
+    `
+	return r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		result, err := r.db.querier(ctx).ExecContext(ctx, query, id, workspaceID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// TouchLastUsed records that the key was used. It writes at most once a
+// minute per key so authentication does not turn every request into a write.
+func (r *apiKeyRepo) TouchLastUsed(ctx context.Context, id int64) error {
+	ctx, cancel := r.db.withQueryTimeout(ctx)
+	defer cancel()
+	query := `
+        UPDATE api_keys
+        SET last_used_at = NOW()
+        WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+    `
+	_, err := r.db.querier(ctx).ExecContext(ctx, query, id)
+	return err
+}
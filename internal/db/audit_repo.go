@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"sf_test/internal/models"
+)
+
+// AuditRepository stores the append-only audit log. Appending inside a
+// transaction records the entry only if the audited change commits.
+type AuditRepository interface {
+	Append(ctx context.Context, entry *models.AuditEntry) error
+	List(ctx context.Context, opts models.ListOptions) ([]*models.AuditEntry, string, error)
+}
+
+var auditSortFields = map[string]sortField[*models.AuditEntry]{
+	"createdAt": {column: "created_at", value: func(e *models.AuditEntry) string { return formatCursorTime(e.CreatedAt) }},
+}
+
+type auditRepo struct {
+	db *DB
+}
+
+func NewAuditRepository(db *DB) AuditRepository {
+	return &auditRepo{db: db}
+}
+
+func (r *auditRepo) Append(ctx context.Context, entry *models.AuditEntry) error {
+	query := `
+        INSERT INTO audit_log (workspace_id, actor, action, resource_type, resource_id, changes, request_id, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, NOW()) RETURNING id, created_at
+    `
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+	return r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		return r.db.querier(ctx).QueryRowContext(ctx, query, workspaceID, entry.Actor, entry.Action, entry.ResourceType, entry.ResourceID, changes, entry.RequestID).Scan(&entry.ID, &entry.CreatedAt)
+	})
+}
+
+// List returns one page of audit entries, newest first by default, and the
+// cursor for the next page.
+func (r *auditRepo) List(ctx context.Context, opts models.ListOptions) ([]*models.AuditEntry, string, error) {
+	q := &pageQuery[*models.AuditEntry]{
+		selectFrom:  `SELECT id, actor, action, resource_type, resource_id, changes, request_id, created_at FROM audit_log`,
+		idColumn:    "id",
+		sortFields:  auditSortFields,
+		defaultSort: "-createdAt",
+	}
+	filters := opts.Filters
+	if filters.Actor != "" {
+		q.filter("actor = ?", filters.Actor)
+	}
+	if filters.Action != "" {
+		q.filter("action = ?", filters.Action)
+	}
+	if filters.ResourceType != "" {
+		q.filter("resource_type = ?", filters.ResourceType)
+	}
+	if filters.ResourceID != 0 {
+		q.filter("resource_id = ?", filters.ResourceID)
+	}
+	if filters.RequestID != "" {
+		q.filter("request_id = ?", filters.RequestID)
+	}
+	q.filterCreatedAt("created_at", filters)
+
+	entries := []*models.AuditEntry{}
+	err := r.db.readInWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		entries = []*models.AuditEntry{}
+		query, args, err := q.withFilter("workspace_id = ?", workspaceID).build(opts)
+		if err != nil {
+			return err
+		}
+		rows, err := r.db.querier(ctx).QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			entry := &models.AuditEntry{}
+			var changes []byte
+			if err := rows.Scan(&entry.ID, &entry.Actor, &entry.Action, &entry.ResourceType, &entry.ResourceID, &changes, &entry.RequestID, &entry.CreatedAt); err != nil {
+				return err
+			}
+			if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	entries, next := q.page(entries, opts, func(e *models.AuditEntry) int64 { return e.ID })
+	return entries, next, nil
+}
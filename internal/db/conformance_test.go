@@ -0,0 +1,336 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sf_test/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// repositories is one implementation of the repositories under test. The
+// conformance tests run against every implementation, so they only assert
+// behavior the services rely on.
+type repositories struct {
+	tx        Transactor
+	sequences SequenceRepository
+	steps     StepRepository
+	// workspace returns a context bound to a new, empty workspace.
+	workspace func(t *testing.T) context.Context
+}
+
+func runConformanceTests(t *testing.T, repos repositories) {
+	t.Run("sequences", func(t *testing.T) { testSequenceRepository(t, repos) })
+	t.Run("steps", func(t *testing.T) { testStepRepository(t, repos) })
+	t.Run("transactions", func(t *testing.T) { testTransactions(t, repos) })
+}
+
+func newSequence(name string, steps ...string) *models.Sequence {
+	sequence := &models.Sequence{Name: name, OpenTrackingEnabled: true}
+	for i, subject := range steps {
+		sequence.Steps = append(sequence.Steps, models.Step{Subject: subject, Content: "Content of " + subject, StepOrder: i, WaitDays: i})
+	}
+	return sequence
+}
+
+func createSequence(t *testing.T, repos repositories, ctx context.Context, sequence *models.Sequence) int64 {
+	t.Helper()
+	id, err := repos.sequences.Create(ctx, sequence)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return id
+}
+
+func testSequenceRepository(t *testing.T, repos repositories) {
+	t.Run("create and get", func(t *testing.T) {
+		ctx := repos.workspace(t)
+		sequence := newSequence("onboarding", "second", "first")
+		sequence.Steps[0].StepOrder, sequence.Steps[1].StepOrder = 1, 0
+		id := createSequence(t, repos, ctx, sequence)
+		assert.NotZero(t, sequence.Steps[0].ID)
+		assert.Equal(t, id, sequence.Steps[0].SequenceID)
+
+		got, err := repos.sequences.Get(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, id, got.ID)
+		assert.Equal(t, "onboarding", got.Name)
+		assert.True(t, got.OpenTrackingEnabled)
+		assert.False(t, got.Active)
+		assert.Equal(t, int64(1), got.Version)
+		assert.False(t, got.CreatedAt.IsZero())
+		if assert.Len(t, got.Steps, 2) {
+			assert.Equal(t, "first", got.Steps[0].Subject)
+			assert.Equal(t, "second", got.Steps[1].Subject)
+			assert.Equal(t, int64(1), got.Steps[0].Version)
+		}
+	})
+
+	t.Run("get missing", func(t *testing.T) {
+		_, err := repos.sequences.Get(repos.workspace(t), 1<<40)
+		assert.True(t, errors.Is(err, sql.ErrNoRows))
+	})
+
+	t.Run("requires a workspace", func(t *testing.T) {
+		_, err := repos.sequences.Create(context.Background(), newSequence("orphan"))
+		assert.True(t, errors.Is(err, ErrNoWorkspace))
+	})
+
+	t.Run("isolates workspaces", func(t *testing.T) {
+		ctx, other := repos.workspace(t), repos.workspace(t)
+		id := createSequence(t, repos, ctx, newSequence("private", "hello"))
+
+		_, err := repos.sequences.Get(other, id)
+		assert.True(t, errors.Is(err, sql.ErrNoRows))
+		_, err = repos.sequences.SetActive(other, id, true, 0)
+		assert.True(t, errors.Is(err, ErrNotFound))
+		assert.True(t, errors.Is(repos.sequences.Delete(other, id, 0), ErrNotFound))
+		sequences, _, err := repos.sequences.List(other, models.ListOptions{})
+		assert.NoError(t, err)
+		assert.Empty(t, sequences)
+	})
+
+	t.Run("conditional writes", func(t *testing.T) {
+		ctx := repos.workspace(t)
+		id := createSequence(t, repos, ctx, newSequence("tracked"))
+
+		version, err := repos.sequences.UpdateTracking(ctx, id, false, true, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), version)
+		_, err = repos.sequences.UpdateTracking(ctx, id, true, true, 1)
+		assert.True(t, errors.Is(err, ErrVersionMismatch))
+		version, err = repos.sequences.SetActive(ctx, id, true, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), version)
+		_, err = repos.sequences.SetActive(ctx, 1<<40, true, 0)
+		assert.True(t, errors.Is(err, ErrNotFound))
+
+		got, err := repos.sequences.Get(ctx, id)
+		assert.NoError(t, err)
+		assert.False(t, got.OpenTrackingEnabled)
+		assert.True(t, got.ClickTrackingEnabled)
+		assert.True(t, got.Active)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		ctx := repos.workspace(t)
+		id := createSequence(t, repos, ctx, newSequence("before"))
+		sequence := &models.Sequence{ID: id, Name: "after", ExternalKey: "after", Version: 1}
+		assert.NoError(t, repos.sequences.Update(ctx, sequence))
+		assert.Equal(t, int64(2), sequence.Version)
+
+		sequence.Name = "stale"
+		sequence.Version = 1
+		assert.True(t, errors.Is(repos.sequences.Update(ctx, sequence), ErrVersionMismatch))
+
+		got, err := repos.sequences.GetByExternalKey(ctx, "after")
+		assert.NoError(t, err)
+		assert.Equal(t, "after", got.Name)
+		_, err = repos.sequences.GetByExternalKey(ctx, "missing")
+		assert.True(t, errors.Is(err, sql.ErrNoRows))
+	})
+
+	t.Run("external keys are unique per workspace", func(t *testing.T) {
+		ctx, other := repos.workspace(t), repos.workspace(t)
+		keyed := newSequence("keyed")
+		keyed.ExternalKey = "shared"
+		createSequence(t, repos, ctx, keyed)
+
+		_, err := repos.sequences.Create(ctx, &models.Sequence{Name: "duplicate", ExternalKey: "shared"})
+		assert.True(t, IsUniqueViolation(err))
+		_, err = repos.sequences.Create(other, &models.Sequence{Name: "elsewhere", ExternalKey: "shared"})
+		assert.NoError(t, err)
+
+		id := createSequence(t, repos, ctx, newSequence("renamed"))
+		err = repos.sequences.Update(ctx, &models.Sequence{ID: id, Name: "renamed", ExternalKey: "shared"})
+		assert.True(t, IsUniqueViolation(err))
+	})
+
+	t.Run("list with external key", func(t *testing.T) {
+		ctx := repos.workspace(t)
+		for _, key := range []string{"bravo", "alpha", ""} {
+			sequence := newSequence("sequence " + key)
+			sequence.ExternalKey = key
+			createSequence(t, repos, ctx, sequence)
+		}
+		sequences, err := repos.sequences.ListWithExternalKey(ctx)
+		assert.NoError(t, err)
+		if assert.Len(t, sequences, 2) {
+			assert.Equal(t, "alpha", sequences[0].ExternalKey)
+			assert.Equal(t, "bravo", sequences[1].ExternalKey)
+		}
+	})
+
+	t.Run("delete removes steps", func(t *testing.T) {
+		ctx := repos.workspace(t)
+		sequence := newSequence("doomed", "only")
+		id := createSequence(t, repos, ctx, sequence)
+
+		assert.True(t, errors.Is(repos.sequences.Delete(ctx, id, 7), ErrVersionMismatch))
+		assert.NoError(t, repos.sequences.Delete(ctx, id, 1))
+		_, err := repos.sequences.Get(ctx, id)
+		assert.True(t, errors.Is(err, sql.ErrNoRows))
+		_, err = repos.steps.Get(ctx, sequence.Steps[0].ID)
+		assert.True(t, errors.Is(err, sql.ErrNoRows))
+		assert.True(t, errors.Is(repos.sequences.Delete(ctx, id, 0), ErrNotFound))
+	})
+
+	t.Run("list", func(t *testing.T) {
+		ctx := repos.workspace(t)
+		for _, name := range []string{"delta", "alpha", "echo", "charlie", "bravo"} {
+			createSequence(t, repos, ctx, newSequence(name, "step"))
+		}
+
+		var names []string
+		opts := models.ListOptions{Limit: 2, Sort: "name"}
+		for page := 0; page < 5; page++ {
+			sequences, next, err := repos.sequences.List(ctx, opts)
+			if !assert.NoError(t, err) {
+				return
+			}
+			for _, sequence := range sequences {
+				assert.Empty(t, sequence.Steps)
+				names = append(names, sequence.Name)
+			}
+			if next == "" {
+				break
+			}
+			opts.Cursor = next
+		}
+		assert.Equal(t, []string{"alpha", "bravo", "charlie", "delta", "echo"}, names)
+
+		sequences, next, err := repos.sequences.List(ctx, models.ListOptions{Sort: "-name", Limit: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, "echo", sequences[0].Name)
+		_, _, err = repos.sequences.List(ctx, models.ListOptions{Sort: "name", Cursor: next})
+		assert.True(t, errors.Is(err, models.ErrInvalidCursor))
+
+		sequences, _, err = repos.sequences.List(ctx, models.ListOptions{Filters: models.ListFilters{NameContains: "HARL"}})
+		assert.NoError(t, err)
+		if assert.Len(t, sequences, 1) {
+			assert.Equal(t, "charlie", sequences[0].Name)
+		}
+
+		_, _, err = repos.sequences.List(ctx, models.ListOptions{Sort: "steps"})
+		assert.True(t, errors.Is(err, models.ErrInvalidSort))
+	})
+}
+
+func testStepRepository(t *testing.T, repos repositories) {
+	t.Run("create and get", func(t *testing.T) {
+		ctx := repos.workspace(t)
+		sequenceID := createSequence(t, repos, ctx, newSequence("parent"))
+
+		id, err := repos.steps.Create(ctx, &models.Step{SequenceID: sequenceID, Subject: "hello", Content: "Hi there", StepOrder: 0, WaitDays: 3})
+		assert.NoError(t, err)
+		step, err := repos.steps.Get(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, sequenceID, step.SequenceID)
+		assert.Equal(t, "hello", step.Subject)
+		assert.Equal(t, 3, step.WaitDays)
+		assert.Equal(t, models.WaitUnitDays, step.WaitUnit, "steps wait in calendar days by default")
+		assert.Equal(t, int64(1), step.Version)
+
+		sequence, err := repos.sequences.Get(ctx, sequenceID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), sequence.Version, "a new step bumps its sequence's version")
+	})
+
+	t.Run("requires an existing sequence", func(t *testing.T) {
+		ctx, other := repos.workspace(t), repos.workspace(t)
+		sequenceID := createSequence(t, repos, other, newSequence("foreign"))
+
+		_, err := repos.steps.Create(ctx, &models.Step{SequenceID: sequenceID, Subject: "hello", Content: "Hi there"})
+		assert.True(t, IsForeignKeyViolation(err))
+		_, err = repos.steps.Get(ctx, 1<<40)
+		assert.True(t, errors.Is(err, sql.ErrNoRows))
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		ctx := repos.workspace(t)
+		sequence := newSequence("parent", "first")
+		sequenceID := createSequence(t, repos, ctx, sequence)
+
+		step := sequence.Steps[0]
+		step.Subject, step.WaitUnit, step.Version = "changed", models.WaitUnitBusinessDays, 1
+		assert.NoError(t, repos.steps.Update(ctx, &step))
+		assert.Equal(t, int64(2), step.Version)
+		step.Version = 1
+		assert.True(t, errors.Is(repos.steps.Update(ctx, &step), ErrVersionMismatch))
+
+		got, err := repos.steps.Get(ctx, step.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "changed", got.Subject)
+		assert.Equal(t, models.WaitUnitBusinessDays, got.WaitUnit)
+
+		assert.True(t, errors.Is(repos.steps.Delete(ctx, step.ID, 1), ErrVersionMismatch))
+		assert.NoError(t, repos.steps.Delete(ctx, step.ID, 2))
+		assert.True(t, errors.Is(repos.steps.Delete(ctx, step.ID, 0), ErrNotFound))
+
+		parent, err := repos.sequences.Get(ctx, sequenceID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), parent.Version)
+		assert.Empty(t, parent.Steps)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		ctx := repos.workspace(t)
+		sequence := newSequence("parent", "zero", "one", "two", "three")
+		sequenceID := createSequence(t, repos, ctx, sequence)
+		createSequence(t, repos, ctx, newSequence("sibling", "unrelated"))
+
+		steps, err := repos.steps.ListBySequenceID(ctx, sequenceID)
+		assert.NoError(t, err)
+		if assert.Len(t, steps, 4) {
+			assert.Equal(t, "zero", steps[0].Subject)
+			assert.Equal(t, "three", steps[3].Subject)
+		}
+
+		page, next, err := repos.steps.List(ctx, sequenceID, models.ListOptions{Limit: 3, Sort: "-stepOrder"})
+		assert.NoError(t, err)
+		if assert.Len(t, page, 3) {
+			assert.Equal(t, "three", page[0].Subject)
+		}
+		page, next, err = repos.steps.List(ctx, sequenceID, models.ListOptions{Limit: 3, Sort: "-stepOrder", Cursor: next})
+		assert.NoError(t, err)
+		assert.Empty(t, next)
+		if assert.Len(t, page, 1) {
+			assert.Equal(t, "zero", page[0].Subject)
+		}
+
+		page, _, err = repos.steps.List(ctx, sequenceID, models.ListOptions{Filters: models.ListFilters{NameContains: "T"}})
+		assert.NoError(t, err)
+		assert.Len(t, page, 2)
+	})
+}
+
+func testTransactions(t *testing.T, repos repositories) {
+	t.Run("rollback discards writes", func(t *testing.T) {
+		ctx := repos.workspace(t)
+		failure := errors.New("abort")
+		var id int64
+		err := repos.tx.WithinTx(ctx, func(ctx context.Context) error {
+			id = createSequence(t, repos, ctx, newSequence("rolled back", "step"))
+			return failure
+		})
+		assert.Equal(t, failure, err)
+		_, err = repos.sequences.Get(ctx, id)
+		assert.True(t, errors.Is(err, sql.ErrNoRows))
+	})
+
+	t.Run("commit keeps writes", func(t *testing.T) {
+		ctx := repos.workspace(t)
+		var id int64
+		err := repos.tx.WithinTx(ctx, func(ctx context.Context) error {
+			id = createSequence(t, repos, ctx, newSequence("committed"))
+			_, err := repos.sequences.SetActive(ctx, id, true, 0)
+			return err
+		})
+		assert.NoError(t, err)
+		got, err := repos.sequences.Get(ctx, id)
+		assert.NoError(t, err)
+		assert.True(t, got.Active)
+	})
+}
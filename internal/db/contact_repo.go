@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sf_test/internal/models"
+
+	"github.com/lib/pq"
+)
+
+type ContactRepository interface {
+	// Create inserts the contact unless the workspace already has one with
+	// its email, such as one inserted by a concurrent import. It returns the
+	// ID of the new or existing contact and whether it was created.
+	Create(ctx context.Context, contact *models.Contact) (int64, bool, error)
+	FindIDsByEmail(ctx context.Context, emails []string) (map[string]int64, error)
+}
+
+type contactRepo struct {
+	db *DB
+}
+
+func NewContactRepository(db *DB) ContactRepository {
+	return &contactRepo{db: db}
+}
+
+func (r *contactRepo) Create(ctx context.Context, contact *models.Contact) (int64, bool, error) {
+	query := `
+        INSERT INTO contacts (workspace_id, email, first_name, last_name, company, country, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+        ON CONFLICT (workspace_id, email) DO NOTHING
+        RETURNING id
+    `
+	var id int64
+	created := true
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		err := r.db.querier(ctx).QueryRowContext(ctx, query, workspaceID, contact.Email, contact.FirstName, contact.LastName, contact.Company, contact.Country).Scan(&id)
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		// The insert waited for the transaction that added the email to
+		// commit, so the existing contact is visible to the next statement.
+		created = false
+		return r.db.querier(ctx).QueryRowContext(ctx, `SELECT id FROM contacts WHERE workspace_id = $1 AND email = $2`, workspaceID, contact.Email).Scan(&id)
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return id, created, nil
+}
+
+// FindIDsByEmail returns the IDs of the contacts with the given emails, keyed by email.
+func (r *contactRepo) FindIDsByEmail(ctx context.Context, emails []string) (map[string]int64, error) {
+	query := `
+        SELECT id, email
+        FROM contacts
+        WHERE email = ANY($1) AND workspace_id = $2
+    `
+	ids := make(map[string]int64)
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		rows, err := r.db.querier(ctx).QueryContext(ctx, query, pq.Array(emails), workspaceID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id int64
+			var email string
+			if err := rows.Scan(&id, &email); err != nil {
+				return err
+			}
+			ids[email] = id
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -1,27 +1,135 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	_ "github.com/lib/pq" // PostgreSQL driver
 )
 
+const (
+	connectMinBackoff = 250 * time.Millisecond
+	connectMaxBackoff = 5 * time.Second
+)
+
+// Options tunes the connection pool and timeouts. Zero values keep the
+// database/sql defaults and leave queries without a timeout.
+type Options struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// ConnectTimeout is how long NewDB keeps retrying a database that does
+	// not accept connections yet. With zero it tries once.
+	ConnectTimeout time.Duration
+	// QueryTimeout bounds each repository call whose context has no deadline.
+	QueryTimeout time.Duration
+	// Replicas are connection strings of read replicas, which serve
+	// read-only repository calls while they answer health checks made every
+	// ReplicaCheckInterval.
+	Replicas             []string
+	ReplicaCheckInterval time.Duration
+}
+
 type DB struct {
 	Conn *sql.DB
+
+	connectionString string
+	queryTimeout     time.Duration
+	mu               sync.Mutex
+	listeners        []*Listener
+
+	replicas    []*replica
+	nextReplica atomic.Uint64
+	stop        chan struct{}
 }
 
-func NewDB(connectionString string) (*DB, error) {
+// NewDB opens a connection pool, retrying with backoff until the database
+// answers or opts.ConnectTimeout has passed.
+func NewDB(connectionString string, opts Options) (*DB, error) {
 	conn, err := sql.Open("postgres", connectionString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
-	if err = conn.Ping(); err != nil {
+	configurePool(conn, opts)
+
+	if err := ping(conn, opts.ConnectTimeout); err != nil {
+		conn.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
-	return &DB{Conn: conn}, nil
+	db := &DB{Conn: conn, connectionString: connectionString, queryTimeout: opts.QueryTimeout}
+	if err := db.openReplicas(opts.Replicas, opts); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open replica: %w", err)
+	}
+	return db, nil
+}
+
+func configurePool(conn *sql.DB, opts Options) {
+	conn.SetMaxOpenConns(opts.MaxOpenConns)
+	if opts.MaxIdleConns > 0 {
+		conn.SetMaxIdleConns(opts.MaxIdleConns)
+	}
+	conn.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	conn.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
 }
 
+// ping waits for the database to answer, doubling the wait between attempts.
+func ping(conn *sql.DB, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	backoff := connectMinBackoff
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), connectMaxBackoff)
+		err := conn.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return err
+		}
+		log.Printf("Database is not ready, retrying in %s: %v", backoff, err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, connectMaxBackoff)
+	}
+}
+
+// withQueryTimeout bounds ctx by the query timeout unless it already has a
+// deadline.
+func (db *DB) withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || db.queryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, db.queryTimeout)
+}
+
+// BypassesRowSecurity reports whether the role the pool connects as is a
+// superuser or has BYPASSRLS, which exempts it from row-level security.
+func (db *DB) BypassesRowSecurity(ctx context.Context) (bool, error) {
+	var bypasses bool
+	err := db.Conn.QueryRowContext(ctx, `SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user`).Scan(&bypasses)
+	return bypasses, err
+}
+
+// Close closes the connection pools and every listener.
 func (db *DB) Close() error {
+	db.mu.Lock()
+	listeners := db.listeners
+	db.listeners = nil
+	db.mu.Unlock()
+	for _, listener := range listeners {
+		listener.Close()
+	}
+	if db.stop != nil {
+		close(db.stop)
+	}
+	for _, replica := range db.replicas {
+		replica.conn.Close()
+	}
 	return db.Conn.Close()
 }
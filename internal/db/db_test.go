@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDB_RetriesUntilConnectTimeout(t *testing.T) {
+	start := time.Now()
+	_, err := NewDB("postgres://postgres@127.0.0.1:1/none?sslmode=disable", Options{ConnectTimeout: time.Second})
+	assert.Error(t, err)
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, connectMinBackoff, "should retry at least once")
+	assert.Less(t, elapsed, 2*time.Second)
+}
+
+func TestWithQueryTimeout(t *testing.T) {
+	db := &DB{queryTimeout: time.Minute}
+
+	ctx, cancel := db.withQueryTimeout(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+
+	// An earlier deadline set by the caller is kept.
+	short, cancelShort := context.WithTimeout(context.Background(), time.Second)
+	defer cancelShort()
+	ctx, cancel = db.withQueryTimeout(short)
+	defer cancel()
+	deadline, _ = ctx.Deadline()
+	assert.WithinDuration(t, time.Now().Add(time.Second), deadline, time.Second)
+
+	db.queryTimeout = 0
+	ctx, cancel = db.withQueryTimeout(context.Background())
+	defer cancel()
+	_, ok = ctx.Deadline()
+	assert.False(t, ok)
+}
+
+func TestIsSerializationFailure(t *testing.T) {
+	assert.True(t, IsSerializationFailure(&pq.Error{Code: serializationFailure}))
+	assert.True(t, IsSerializationFailure(fmt.Errorf("applying plan: %w", &pq.Error{Code: deadlockDetected})))
+	assert.False(t, IsSerializationFailure(&pq.Error{Code: uniqueViolation}))
+	assert.False(t, IsSerializationFailure(errors.New("serialization failure")))
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"sf_test/internal/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type EnrollmentRepository interface {
+	EnrollContacts(ctx context.Context, sequenceID int64, contactIDs []int64, firstStepID int64, dueAt func(country string) (time.Time, error)) (int, error)
+	Complete(ctx context.Context, id int64) error
+	Get(ctx context.Context, id int64) (*models.Enrollment, error)
+}
+
+type enrollmentRepo struct {
+	db *DB
+}
+
+func NewEnrollmentRepository(db *DB) EnrollmentRepository {
+	return &enrollmentRepo{db: db}
+}
+
+// EnrollContacts enrolls the contacts into the sequence, skipping contacts
+// that are already enrolled, and returns how many enrollments were created.
+// Each new enrollment's first step, unless firstStepID is zero because the
+// sequence has none, is scheduled at the time dueAt returns for the
+// contact's country.
+func (r *enrollmentRepo) EnrollContacts(ctx context.Context, sequenceID int64, contactIDs []int64, firstStepID int64, dueAt func(country string) (time.Time, error)) (int, error) {
+	query := `
+        WITH enrolled AS (
+            INSERT INTO enrollments (workspace_id, sequence_id, contact_id, status, created_at, updated_at)
+            SELECT $1, $2, contact_id, 'active', NOW(), NOW()
+            FROM UNNEST($3::BIGINT[]) AS contact_id
+            ON CONFLICT (sequence_id, contact_id) DO NOTHING
+            RETURNING id, contact_id
+        ), due AS (
+            SELECT * FROM UNNEST($5::TEXT[], $6::TIMESTAMPTZ[]) AS due (country, due_at)
+        ), scheduled AS (
+            INSERT INTO sends (workspace_id, enrollment_id, step_id, due_at, created_at, updated_at)
+            SELECT $1, enrolled.id, $4::BIGINT, due.due_at, NOW(), NOW()
+            FROM enrolled
+            JOIN contacts c ON c.id = enrolled.contact_id AND c.workspace_id = $1
+            JOIN due ON due.country = c.country
+            WHERE $4::BIGINT <> 0
+        )
+        SELECT COUNT(*) FROM enrolled
+    `
+	var enrolled int
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		var countries, dueAts []string
+		if firstStepID != 0 {
+			var err error
+			if countries, err = r.countries(ctx, contactIDs, workspaceID); err != nil {
+				return err
+			}
+			for _, country := range countries {
+				at, err := dueAt(country)
+				if err != nil {
+					return err
+				}
+				dueAts = append(dueAts, at.UTC().Format(time.RFC3339Nano))
+			}
+		}
+		return r.db.querier(ctx).QueryRowContext(ctx, query, workspaceID, sequenceID, pq.Array(contactIDs), firstStepID, pq.Array(countries), pq.Array(dueAts)).Scan(&enrolled)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return enrolled, nil
+}
+
+// countries returns the distinct countries of the contacts.
+func (r *enrollmentRepo) countries(ctx context.Context, contactIDs []int64, workspaceID int64) ([]string, error) {
+	query := `SELECT DISTINCT country FROM contacts WHERE id = ANY($1) AND workspace_id = $2`
+	rows, err := r.db.querier(ctx).QueryContext(ctx, query, pq.Array(contactIDs), workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var countries []string
+	for rows.Next() {
+		var country string
+		if err := rows.Scan(&country); err != nil {
+			return nil, err
+		}
+		countries = append(countries, country)
+	}
+	return countries, rows.Err()
+}
+
+// Complete marks the enrollment completed once its last step was sent.
+func (r *enrollmentRepo) Complete(ctx context.Context, id int64) error {
+	query := `
+        UPDATE enrollments
+        SET status = 'completed', updated_at = NOW()
+        WHERE id = $1 AND workspace_id = $2
+    `
+	return r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		result, err := r.db.querier(ctx).ExecContext(ctx, query, id, workspaceID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *enrollmentRepo) Get(ctx context.Context, id int64) (*models.Enrollment, error) {
+	query := `
+        SELECT e.id, e.sequence_id, e.contact_id, c.country, e.status, e.created_at, e.updated_at
+        FROM enrollments e
+        JOIN contacts c ON c.id = e.contact_id AND c.workspace_id = e.workspace_id
+        WHERE e.id = $1 AND e.workspace_id = $2
+    `
+	enrollment := &models.Enrollment{}
+	err := r.db.readInWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		return r.db.querier(ctx).QueryRowContext(ctx, query, id, workspaceID).Scan(
+			&enrollment.ID, &enrollment.SequenceID, &enrollment.ContactID, &enrollment.ContactCountry, &enrollment.Status, &enrollment.CreatedAt, &enrollment.UpdatedAt,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return enrollment, nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"sf_test/internal/tenant"
+
+	"github.com/lib/pq"
+)
+
+// ErrNotFound is returned when an update or delete matches no rows.
+var ErrNotFound = errors.New("no matching rows")
+
+// ErrVersionMismatch is returned when a conditional write names a version
+// that is no longer the stored one.
+var ErrVersionMismatch = errors.New("version does not match")
+
+// PostgreSQL error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	foreignKeyViolation  = "23503"
+	uniqueViolation      = "23505"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// IsUniqueViolation reports whether err was caused by a unique constraint.
+func IsUniqueViolation(err error) bool {
+	return hasCode(err, uniqueViolation)
+}
+
+// IsForeignKeyViolation reports whether err was caused by a foreign key constraint.
+func IsForeignKeyViolation(err error) bool {
+	return hasCode(err, foreignKeyViolation)
+}
+
+// IsSerializationFailure reports whether err was caused by a transaction
+// conflicting with a concurrent one, which succeeds when retried.
+func IsSerializationFailure(err error) bool {
+	return hasCode(err, serializationFailure) || hasCode(err, deadlockDetected)
+}
+
+func hasCode(err error, code string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && string(pqErr.Code) == code
+}
+
+// notFoundOrStale explains why a conditional write to table matched no rows.
+// Rows of other workspaces are reported as not found.
+func (db *DB) notFoundOrStale(ctx context.Context, table string, id int64) error {
+	workspaceID, ok := tenant.WorkspaceFrom(ctx)
+	if !ok {
+		return ErrNoWorkspace
+	}
+	var exists bool
+	err := db.querier(ctx).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1 AND workspace_id = $2)`, id, workspaceID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrVersionMismatch
+	}
+	return ErrNotFound
+}
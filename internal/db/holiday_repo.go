@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"sf_test/internal/models"
+	"time"
+)
+
+type HolidayRepository interface {
+	// Replace replaces the country's holidays, or the workspace-wide ones
+	// when country is empty.
+	Replace(ctx context.Context, country string, holidays []*models.Holiday) error
+	// List returns every holiday of the workspace by date.
+	List(ctx context.Context) ([]*models.Holiday, error)
+	// ListForCountry returns the holidays applying to contacts in the
+	// country: the workspace-wide ones and the country's own.
+	ListForCountry(ctx context.Context, country string) ([]*models.Holiday, error)
+}
+
+type holidayRepo struct {
+	db *DB
+}
+
+func NewHolidayRepository(db *DB) HolidayRepository {
+	return &holidayRepo{db: db}
+}
+
+const holidayColumns = `id, country, date, name, created_at`
+
+func scanHoliday(row rowScanner) (*models.Holiday, error) {
+	holiday := &models.Holiday{}
+	if err := row.Scan(&holiday.ID, &holiday.Country, &holiday.Date, &holiday.Name, &holiday.CreatedAt); err != nil {
+		return nil, err
+	}
+	year, month, day := holiday.Date.Date()
+	holiday.Date = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return holiday, nil
+}
+
+func (r *holidayRepo) Replace(ctx context.Context, country string, holidays []*models.Holiday) error {
+	deleteQuery := `DELETE FROM holidays WHERE workspace_id = $1 AND country = $2`
+	insertQuery := `
+        INSERT INTO holidays (workspace_id, country, date, name, created_at)
+        VALUES ($1, $2, $3, $4, NOW()) RETURNING id, created_at
+    `
+	return r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		q := r.db.querier(ctx)
+		if _, err := q.ExecContext(ctx, deleteQuery, workspaceID, country); err != nil {
+			return err
+		}
+		for _, holiday := range holidays {
+			holiday.Country = country
+			err := q.QueryRowContext(ctx, insertQuery, workspaceID, country, holiday.Date.Format("2006-01-02"), holiday.Name).Scan(&holiday.ID, &holiday.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *holidayRepo) List(ctx context.Context) ([]*models.Holiday, error) {
+	query := `SELECT ` + holidayColumns + ` FROM holidays WHERE workspace_id = $1 ORDER BY date, country`
+	return r.list(ctx, query)
+}
+
+func (r *holidayRepo) ListForCountry(ctx context.Context, country string) ([]*models.Holiday, error) {
+	query := `SELECT ` + holidayColumns + ` FROM holidays WHERE workspace_id = $1 AND country IN ('', $2) ORDER BY date, country`
+	return r.list(ctx, query, country)
+}
+
+func (r *holidayRepo) list(ctx context.Context, query string, args ...interface{}) ([]*models.Holiday, error) {
+	holidays := []*models.Holiday{}
+	err := r.db.readInWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		holidays = []*models.Holiday{}
+		rows, err := r.db.querier(ctx).QueryContext(ctx, query, append([]interface{}{workspaceID}, args...)...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			holiday, err := scanHoliday(rows)
+			if err != nil {
+				return err
+			}
+			holidays = append(holidays, holiday)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return holidays, nil
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sf_test/internal/models"
+	"time"
+)
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, record *models.IdempotencyRecord, ttl, lockTimeout time.Duration) (bool, error)
+	Get(ctx context.Context, scope, key string) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, record *models.IdempotencyRecord) error
+	Delete(ctx context.Context, scope, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type idempotencyRepo struct {
+	db *DB
+}
+
+func NewIdempotencyRepository(db *DB) IdempotencyRepository {
+	return &idempotencyRepo{db: db}
+}
+
+// Reserve claims the key for a new request and reports whether it succeeded.
+// An existing record is only taken over once it has expired, or when it was
+// never completed and its request has been running longer than lockTimeout.
+func (r *idempotencyRepo) Reserve(ctx context.Context, record *models.IdempotencyRecord, ttl, lockTimeout time.Duration) (bool, error) {
+	ctx, cancel := r.db.withQueryTimeout(ctx)
+	defer cancel()
+	query := `
+        INSERT INTO idempotency_keys (scope, key, request_hash, created_at, expires_at)
+        VALUES ($1, $2, $3, NOW(), NOW() + $4 * INTERVAL '1 second')
+        ON CONFLICT (scope, key) DO UPDATE
+        SET request_hash = EXCLUDED.request_hash, status_code = NULL, response_headers = NULL, response_body = NULL,
+            created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+        WHERE idempotency_keys.expires_at < NOW()
+           OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at < NOW() - $5 * INTERVAL '1 second')
+        RETURNING created_at, expires_at
+    `
+	err := r.db.querier(ctx).QueryRowContext(ctx, query, record.Scope, record.Key, record.RequestHash, ttl.Seconds(), lockTimeout.Seconds()).
+		Scan(&record.CreatedAt, &record.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *idempotencyRepo) Get(ctx context.Context, scope, key string) (*models.IdempotencyRecord, error) {
+	ctx, cancel := r.db.withQueryTimeout(ctx)
+	defer cancel()
+	query := `
+        SELECT scope, key, request_hash, status_code, response_headers, response_body, created_at, expires_at
+        FROM idempotency_keys
+        WHERE scope = $1 AND key = $2 AND expires_at >= NOW()
+    `
+	record := &models.IdempotencyRecord{}
+	var statusCode sql.NullInt32
+	var headers []byte
+	err := r.db.querier(ctx).QueryRowContext(ctx, query, scope, key).Scan(
+		&record.Scope, &record.Key, &record.RequestHash, &statusCode, &headers, &record.Body, &record.CreatedAt, &record.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	record.StatusCode = int(statusCode.Int32)
+	if headers != nil {
+		if err := json.Unmarshal(headers, &record.Headers); err != nil {
+			return nil, err
+		}
+	}
+	return record, nil
+}
+
+// Complete stores the response of the request that reserved the key.
+func (r *idempotencyRepo) Complete(ctx context.Context, record *models.IdempotencyRecord) error {
+	ctx, cancel := r.db.withQueryTimeout(ctx)
+	defer cancel()
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return err
+	}
+	query := `
+        UPDATE idempotency_keys
+        SET status_code = $1, response_headers = $2, response_body = $3
+        WHERE scope = $4 AND key = $5 AND request_hash = $6 AND status_code IS NULL
+    `
+	result, err := r.db.querier(ctx).ExecContext(ctx, query, record.StatusCode, headers, record.Body, record.Scope, record.Key, record.RequestHash)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *idempotencyRepo) Delete(ctx context.Context, scope, key string) error {
+	ctx, cancel := r.db.withQueryTimeout(ctx)
+	defer cancel()
+	_, err := r.db.querier(ctx).ExecContext(ctx, `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2`, scope, key)
+	return err
+}
+
+// DeleteExpired removes expired records and returns how many were removed.
+func (r *idempotencyRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	ctx, cancel := r.db.withQueryTimeout(ctx)
+	defer cancel()
+	result, err := r.db.querier(ctx).ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
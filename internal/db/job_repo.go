@@ -0,0 +1,205 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"sf_test/internal/models"
+)
+
+type JobRepository interface {
+	Create(ctx context.Context, job *models.Job) (int64, error)
+	Get(ctx context.Context, id int64) (*models.Job, error)
+	UpdateProgress(ctx context.Context, job *models.Job) error
+	AddItemErrors(ctx context.Context, jobID int64, itemErrors []models.JobItemError) error
+	ListItemErrors(ctx context.Context, jobID int64) ([]models.JobItemError, error)
+	List(ctx context.Context, opts models.ListOptions) ([]*models.Job, string, error)
+}
+
+var jobSortFields = map[string]sortField[*models.Job]{
+	"createdAt": {column: "created_at", value: func(j *models.Job) string { return formatCursorTime(j.CreatedAt) }},
+	"updatedAt": {column: "updated_at", value: func(j *models.Job) string { return formatCursorTime(j.UpdatedAt) }},
+}
+
+type jobRepo struct {
+	db *DB
+}
+
+func NewJobRepository(db *DB) JobRepository {
+	return &jobRepo{db: db}
+}
+
+func (r *jobRepo) Create(ctx context.Context, job *models.Job) (int64, error) {
+	query := `
+        INSERT INTO jobs (workspace_id, type, status, total_items, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, NOW(), NOW()) RETURNING id
+    `
+	var id int64
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		return r.db.querier(ctx).QueryRowContext(ctx, query, workspaceID, job.Type, job.Status, job.TotalItems).Scan(&id)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+const jobColumns = `id, type, status, total_items, processed_items, succeeded_items, skipped_items, failed_items, error, created_at, updated_at, finished_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanJob(row rowScanner) (*models.Job, error) {
+	job := &models.Job{}
+	err := row.Scan(
+		&job.ID, &job.Type, &job.Status, &job.TotalItems, &job.ProcessedItems, &job.SucceededItems,
+		&job.SkippedItems, &job.FailedItems, &job.Error, &job.CreatedAt, &job.UpdatedAt, &job.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *jobRepo) Get(ctx context.Context, id int64) (*models.Job, error) {
+	query := `
+        SELECT ` + jobColumns + `
+        FROM jobs
+        WHERE id = $1 AND workspace_id = $2
+    `
+	var job *models.Job
+	err := r.db.readInWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		var err error
+		job, err = scanJob(r.db.querier(ctx).QueryRowContext(ctx, query, id, workspaceID))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// List returns one page of jobs and the cursor for the next page.
+func (r *jobRepo) List(ctx context.Context, opts models.ListOptions) ([]*models.Job, string, error) {
+	q := &pageQuery[*models.Job]{
+		selectFrom:  `SELECT ` + jobColumns + ` FROM jobs`,
+		idColumn:    "id",
+		sortFields:  jobSortFields,
+		defaultSort: "-createdAt",
+	}
+	if opts.Filters.Status != "" {
+		q.filter("status = ?", opts.Filters.Status)
+	}
+	if opts.Filters.Type != "" {
+		q.filter("type = ?", opts.Filters.Type)
+	}
+	q.filterCreatedAt("created_at", opts.Filters)
+
+	jobs := []*models.Job{}
+	err := r.db.readInWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		jobs = []*models.Job{}
+		query, args, err := q.withFilter("workspace_id = ?", workspaceID).build(opts)
+		if err != nil {
+			return err
+		}
+		rows, err := r.db.querier(ctx).QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			job, err := scanJob(rows)
+			if err != nil {
+				return err
+			}
+			jobs = append(jobs, job)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	jobs, next := q.page(jobs, opts, func(j *models.Job) int64 { return j.ID })
+	return jobs, next, nil
+}
+
+// UpdateProgress stores the job's status, counters, error and finish time.
+func (r *jobRepo) UpdateProgress(ctx context.Context, job *models.Job) error {
+	query := `
+        UPDATE jobs
+        SET status = $1, total_items = $2, processed_items = $3, succeeded_items = $4, skipped_items = $5,
+            failed_items = $6, error = $7, finished_at = $8, updated_at = NOW()
+        WHERE id = $9 AND workspace_id = $10
+    `
+	return r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		result, err := r.db.querier(ctx).ExecContext(ctx, query, job.Status, job.TotalItems, job.ProcessedItems,
+			job.SucceededItems, job.SkippedItems, job.FailedItems, job.Error, job.FinishedAt, job.ID, workspaceID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *jobRepo) AddItemErrors(ctx context.Context, jobID int64, itemErrors []models.JobItemError) error {
+	query := `
+        INSERT INTO job_item_errors (workspace_id, job_id, item, reason, data)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+	return r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		for _, itemError := range itemErrors {
+			data, err := json.Marshal(itemError.Data)
+			if err != nil {
+				return err
+			}
+			if _, err := r.db.querier(ctx).ExecContext(ctx, query, workspaceID, jobID, itemError.Item, itemError.Reason, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *jobRepo) ListItemErrors(ctx context.Context, jobID int64) ([]models.JobItemError, error) {
+	query := `
+        SELECT item, reason, data
+        FROM job_item_errors
+        WHERE job_id = $1 AND workspace_id = $2
+        ORDER BY item
+    `
+	var itemErrors []models.JobItemError
+	err := r.db.readInWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		itemErrors = nil
+		rows, err := r.db.querier(ctx).QueryContext(ctx, query, jobID, workspaceID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var itemError models.JobItemError
+			var data []byte
+			if err := rows.Scan(&itemError.Item, &itemError.Reason, &data); err != nil {
+				return err
+			}
+			if err := json.Unmarshal(data, &itemError.Data); err != nil {
+				return err
+			}
+			itemErrors = append(itemErrors, itemError)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return itemErrors, nil
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	listenerMinReconnect = 100 * time.Millisecond
+	listenerMaxReconnect = time.Minute
+	// listenerPingInterval is how long an idle listener waits before checking
+	// its connection, which would otherwise go unnoticed until the next
+	// notification is lost.
+	listenerPingInterval = 90 * time.Second
+)
+
+// Notification is a message sent with NOTIFY on a channel being listened to.
+type Notification struct {
+	Channel string
+	Payload string
+	// Reconnected is set instead of a channel and payload after the listener
+	// reconnected. Notifications sent while it was disconnected are lost, so
+	// receivers should check for the work they announce.
+	Reconnected bool
+}
+
+// Listener receives notifications on a dedicated connection outside the
+// pool, reconnecting with backoff whenever the connection drops.
+type Listener struct {
+	listener      *pq.Listener
+	notifications chan Notification
+	closeOnce     sync.Once
+	done          chan struct{}
+}
+
+// Listen starts listening on the channels. The listener is closed with the
+// DB if it is not closed earlier.
+func (db *DB) Listen(channels ...string) (*Listener, error) {
+	pqListener := pq.NewListener(db.connectionString, listenerMinReconnect, listenerMaxReconnect, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Database listener: %v", err)
+		}
+	})
+	for _, channel := range channels {
+		if err := pqListener.Listen(channel); err != nil {
+			pqListener.Close()
+			return nil, fmt.Errorf("listening on %s: %w", channel, err)
+		}
+	}
+
+	listener := &Listener{
+		listener:      pqListener,
+		notifications: make(chan Notification, 64),
+		done:          make(chan struct{}),
+	}
+	go listener.run()
+
+	db.mu.Lock()
+	db.listeners = append(db.listeners, listener)
+	db.mu.Unlock()
+	return listener, nil
+}
+
+// Notifications returns the channel notifications are delivered on. It is
+// closed when the listener is closed.
+func (l *Listener) Notifications() <-chan Notification {
+	return l.notifications
+}
+
+// Close stops listening and closes the connection.
+func (l *Listener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.done)
+		err = l.listener.Close()
+	})
+	return err
+}
+
+func (l *Listener) run() {
+	defer close(l.notifications)
+	for {
+		select {
+		case <-l.done:
+			return
+		case n := <-l.listener.Notify:
+			// pq sends nil after re-establishing a dropped connection.
+			notification := Notification{Reconnected: true}
+			if n != nil {
+				notification = Notification{Channel: n.Channel, Payload: n.Extra}
+			}
+			select {
+			case l.notifications <- notification:
+			case <-l.done:
+				return
+			}
+		case <-time.After(listenerPingInterval):
+			go l.listener.Ping()
+		}
+	}
+}
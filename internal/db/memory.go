@@ -0,0 +1,196 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sf_test/internal/models"
+	"sf_test/internal/tenant"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MemoryStore holds the data of the in-memory repositories, which stand in
+// for the PostgreSQL ones in tests. They keep the workspace isolation,
+// optimistic versioning and constraint errors of the PostgreSQL
+// repositories, so services behave the same on either; the conformance
+// tests hold both to the same behavior. Teams are not stored, so team IDs
+// are not checked.
+//
+// MemoryStore is also a Transactor. Transactions run one at a time and roll
+// back every change made through the store when they fail.
+type MemoryStore struct {
+	txMu sync.Mutex // held by the running transaction
+	mu   sync.Mutex // guards the fields below
+
+	nextID    int64
+	sequences map[int64]*memRow[models.Sequence]
+	steps     map[int64]*memRow[models.Step]
+}
+
+// memRow is a stored row and the workspace it belongs to.
+type memRow[T any] struct {
+	workspaceID int64
+	value       T
+}
+
+type memTxKey struct{}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sequences: map[int64]*memRow[models.Sequence]{},
+		steps:     map[int64]*memRow[models.Step]{},
+	}
+}
+
+// WithinTx runs fn in a transaction. Nested calls join the outer one.
+func (s *MemoryStore) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(memTxKey{}) == s {
+		return fn(ctx)
+	}
+
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.mu.Lock()
+	sequences, steps, nextID := cloneRows(s.sequences), cloneRows(s.steps), s.nextID
+	s.mu.Unlock()
+
+	if err := fn(context.WithValue(ctx, memTxKey{}, s)); err != nil {
+		s.mu.Lock()
+		s.sequences, s.steps, s.nextID = sequences, steps, nextID
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// inWorkspace runs fn in a transaction restricted to the workspace bound to
+// ctx, holding the lock on the store's data.
+func (s *MemoryStore) inWorkspace(ctx context.Context, fn func(workspaceID int64) error) error {
+	workspaceID, ok := tenant.WorkspaceFrom(ctx)
+	if !ok {
+		return ErrNoWorkspace
+	}
+	return s.WithinTx(ctx, func(ctx context.Context) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return fn(workspaceID)
+	})
+}
+
+func (s *MemoryStore) newID() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+// now returns the current time at the precision PostgreSQL stores.
+func (s *MemoryStore) now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func cloneRows[T any](rows map[int64]*memRow[T]) map[int64]*memRow[T] {
+	clone := make(map[int64]*memRow[T], len(rows))
+	for id, row := range rows {
+		copied := *row
+		clone[id] = &copied
+	}
+	return clone
+}
+
+// memStale explains why a conditional write matched no rows, like
+// notFoundOrStale.
+func memStale[T any](rows map[int64]*memRow[T], id, workspaceID int64) error {
+	if row, ok := rows[id]; ok && row.workspaceID == workspaceID {
+		return ErrVersionMismatch
+	}
+	return ErrNotFound
+}
+
+// memConstraintError mimics the error PostgreSQL returns for a violated constraint.
+func memConstraintError(code, constraint string) error {
+	return &pq.Error{Code: pq.ErrorCode(code), Constraint: constraint, Message: fmt.Sprintf("violates constraint %q", constraint)}
+}
+
+// containsFold reports whether s contains substr, ignoring case, like ILIKE
+// with a containsPattern.
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// inCreatedRange applies the created-at range filters, like filterCreatedAt.
+func inCreatedRange(createdAt time.Time, filters models.ListFilters) bool {
+	if filters.CreatedAfter != nil && createdAt.Before(*filters.CreatedAfter) {
+		return false
+	}
+	if filters.CreatedBefore != nil && !createdAt.Before(*filters.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+// memSortKeys map a sort field to a string that orders like the column.
+var (
+	memTimeKey = func(t time.Time) string { return t.UTC().Format("2006-01-02T15:04:05.000000") }
+	memIntKey  = func(n int) string { return fmt.Sprintf("%020d", n) }
+)
+
+// memPage sorts items and returns one page and the cursor for the next, like
+// pageQuery. Its cursors hold the sort key rather than the column value, so
+// they are only valid for the in-memory repositories.
+func memPage[T any](items []T, opts models.ListOptions, sortKeys map[string]func(T) string, defaultSort string, id func(T) int64) ([]T, string, error) {
+	sortKey := opts.Sort
+	if sortKey == "" {
+		sortKey = defaultSort
+	}
+	key, ok := sortKeys[strings.TrimPrefix(sortKey, "-")]
+	if !ok {
+		return nil, "", fmt.Errorf("%w %q", models.ErrInvalidSort, strings.TrimPrefix(sortKey, "-"))
+	}
+	descending := strings.HasPrefix(sortKey, "-")
+	compare := func(aKey string, aID int64, bKey string, bID int64) int {
+		c := strings.Compare(aKey, bKey)
+		if c == 0 && aID != bID {
+			c = 1
+			if aID < bID {
+				c = -1
+			}
+		}
+		if descending {
+			return -c
+		}
+		return c
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return compare(key(items[i]), id(items[i]), key(items[j]), id(items[j])) < 0
+	})
+
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		if c.Sort != sortKey {
+			return nil, "", models.ErrInvalidCursor
+		}
+		after := items[:0]
+		for _, item := range items {
+			if compare(key(item), id(item), c.Value, c.ID) > 0 {
+				after = append(after, item)
+			}
+		}
+		items = after
+	}
+
+	limit := opts.PageLimit()
+	if len(items) <= limit {
+		return items, "", nil
+	}
+	items = items[:limit]
+	last := items[len(items)-1]
+	return items, encodeCursor(cursor{Sort: sortKey, Value: key(last), ID: id(last)}), nil
+}
@@ -0,0 +1,230 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sf_test/internal/models"
+	"sort"
+)
+
+var memSequenceSortKeys = map[string]func(*models.Sequence) string{
+	"name":      func(s *models.Sequence) string { return s.Name },
+	"createdAt": func(s *models.Sequence) string { return memTimeKey(s.CreatedAt) },
+	"updatedAt": func(s *models.Sequence) string { return memTimeKey(s.UpdatedAt) },
+}
+
+type memorySequenceRepo struct {
+	store *MemoryStore
+}
+
+// NewMemorySequenceRepository returns a SequenceRepository keeping its data in store.
+func NewMemorySequenceRepository(store *MemoryStore) SequenceRepository {
+	return &memorySequenceRepo{store: store}
+}
+
+func (r *memorySequenceRepo) Create(ctx context.Context, sequence *models.Sequence) (int64, error) {
+	var id int64
+	err := r.store.inWorkspace(ctx, func(workspaceID int64) error {
+		if sequence.ExternalKey != "" && r.findByExternalKey(workspaceID, sequence.ExternalKey) != nil {
+			return memConstraintError(uniqueViolation, "sequences_workspace_external_key_idx")
+		}
+
+		now := r.store.now()
+		id = r.store.newID()
+		stored := *sequence
+		stored.ID, stored.Active, stored.Version = id, false, 1
+		stored.CreatedAt, stored.UpdatedAt, stored.DeletedAt = now, now, nil
+		stored.Steps = nil
+		r.store.sequences[id] = &memRow[models.Sequence]{workspaceID: workspaceID, value: stored}
+
+		for i := range sequence.Steps {
+			step := &sequence.Steps[i]
+			step.ID = r.store.newID()
+			step.SequenceID = id
+			storedStep := *step
+			storedStep.Version = 1
+			storedStep.WaitUnit = step.EffectiveWaitUnit()
+			storedStep.CreatedAt, storedStep.UpdatedAt, storedStep.DeletedAt = now, now, nil
+			r.store.steps[step.ID] = &memRow[models.Step]{workspaceID: workspaceID, value: storedStep}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *memorySequenceRepo) UpdateTracking(ctx context.Context, id int64, openTracking, clickTracking bool, version int64) (int64, error) {
+	var newVersion int64
+	err := r.store.inWorkspace(ctx, func(workspaceID int64) error {
+		row, err := r.writable(workspaceID, id, version)
+		if err != nil {
+			return err
+		}
+		row.value.OpenTrackingEnabled, row.value.ClickTrackingEnabled = openTracking, clickTracking
+		newVersion = r.bump(row)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return newVersion, nil
+}
+
+func (r *memorySequenceRepo) SetActive(ctx context.Context, id int64, active bool, version int64) (int64, error) {
+	var newVersion int64
+	err := r.store.inWorkspace(ctx, func(workspaceID int64) error {
+		row, err := r.writable(workspaceID, id, version)
+		if err != nil {
+			return err
+		}
+		row.value.Active = active
+		newVersion = r.bump(row)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return newVersion, nil
+}
+
+func (r *memorySequenceRepo) Get(ctx context.Context, id int64) (*models.Sequence, error) {
+	var sequence *models.Sequence
+	err := r.store.inWorkspace(ctx, func(workspaceID int64) error {
+		row, ok := r.store.sequences[id]
+		if !ok || row.workspaceID != workspaceID {
+			return sql.ErrNoRows
+		}
+		sequence = r.withSteps(row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sequence, nil
+}
+
+func (r *memorySequenceRepo) GetByExternalKey(ctx context.Context, key string) (*models.Sequence, error) {
+	var sequence *models.Sequence
+	err := r.store.inWorkspace(ctx, func(workspaceID int64) error {
+		row := r.findByExternalKey(workspaceID, key)
+		if row == nil {
+			return sql.ErrNoRows
+		}
+		sequence = r.withSteps(row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sequence, nil
+}
+
+func (r *memorySequenceRepo) ListWithExternalKey(ctx context.Context) ([]*models.Sequence, error) {
+	var sequences []*models.Sequence
+	err := r.store.inWorkspace(ctx, func(workspaceID int64) error {
+		for _, row := range r.store.sequences {
+			if row.workspaceID == workspaceID && row.value.ExternalKey != "" {
+				sequence := row.value
+				sequences = append(sequences, &sequence)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(sequences, func(i, j int) bool { return sequences[i].ExternalKey < sequences[j].ExternalKey })
+	return sequences, nil
+}
+
+func (r *memorySequenceRepo) Update(ctx context.Context, sequence *models.Sequence) error {
+	return r.store.inWorkspace(ctx, func(workspaceID int64) error {
+		row, err := r.writable(workspaceID, sequence.ID, sequence.Version)
+		if err != nil {
+			return err
+		}
+		if sequence.ExternalKey != "" {
+			if other := r.findByExternalKey(workspaceID, sequence.ExternalKey); other != nil && other.value.ID != sequence.ID {
+				return memConstraintError(uniqueViolation, "sequences_workspace_external_key_idx")
+			}
+		}
+		row.value.Name, row.value.ExternalKey = sequence.Name, sequence.ExternalKey
+		row.value.OpenTrackingEnabled, row.value.ClickTrackingEnabled = sequence.OpenTrackingEnabled, sequence.ClickTrackingEnabled
+		sequence.Version = r.bump(row)
+		return nil
+	})
+}
+
+func (r *memorySequenceRepo) Delete(ctx context.Context, id int64, version int64) error {
+	return r.store.inWorkspace(ctx, func(workspaceID int64) error {
+		if _, err := r.writable(workspaceID, id, version); err != nil {
+			return err
+		}
+		delete(r.store.sequences, id)
+		for stepID, step := range r.store.steps {
+			if step.value.SequenceID == id {
+				delete(r.store.steps, stepID)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *memorySequenceRepo) List(ctx context.Context, opts models.ListOptions) ([]*models.Sequence, string, error) {
+	sequences := []*models.Sequence{}
+	err := r.store.inWorkspace(ctx, func(workspaceID int64) error {
+		for _, row := range r.store.sequences {
+			if row.workspaceID != workspaceID || !inCreatedRange(row.value.CreatedAt, opts.Filters) {
+				continue
+			}
+			if opts.Filters.NameContains != "" && !containsFold(row.value.Name, opts.Filters.NameContains) {
+				continue
+			}
+			sequence := row.value
+			sequences = append(sequences, &sequence)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return memPage(sequences, opts, memSequenceSortKeys, "createdAt", func(s *models.Sequence) int64 { return s.ID })
+}
+
+// writable returns the sequence a conditional write with version applies to.
+func (r *memorySequenceRepo) writable(workspaceID, id, version int64) (*memRow[models.Sequence], error) {
+	row, ok := r.store.sequences[id]
+	if !ok || row.workspaceID != workspaceID || (version != 0 && row.value.Version != version) {
+		return nil, memStale(r.store.sequences, id, workspaceID)
+	}
+	return row, nil
+}
+
+func (r *memorySequenceRepo) bump(row *memRow[models.Sequence]) int64 {
+	row.value.Version++
+	row.value.UpdatedAt = r.store.now()
+	return row.value.Version
+}
+
+func (r *memorySequenceRepo) findByExternalKey(workspaceID int64, key string) *memRow[models.Sequence] {
+	for _, row := range r.store.sequences {
+		if row.workspaceID == workspaceID && row.value.ExternalKey == key {
+			return row
+		}
+	}
+	return nil
+}
+
+// withSteps returns a copy of the sequence with its steps in order.
+func (r *memorySequenceRepo) withSteps(row *memRow[models.Sequence]) *models.Sequence {
+	sequence := row.value
+	for _, step := range r.store.steps {
+		if step.workspaceID == row.workspaceID && step.value.SequenceID == sequence.ID {
+			sequence.Steps = append(sequence.Steps, step.value)
+		}
+	}
+	sort.Slice(sequence.Steps, func(i, j int) bool { return sequence.Steps[i].StepOrder < sequence.Steps[j].StepOrder })
+	return &sequence
+}
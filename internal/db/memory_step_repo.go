@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sf_test/internal/models"
+	"sort"
+)
+
+var memStepSortKeys = map[string]func(*models.Step) string{
+	"stepOrder": func(s *models.Step) string { return memIntKey(s.StepOrder) },
+	"createdAt": func(s *models.Step) string { return memTimeKey(s.CreatedAt) },
+	"updatedAt": func(s *models.Step) string { return memTimeKey(s.UpdatedAt) },
+}
+
+type memoryStepRepo struct {
+	store *MemoryStore
+}
+
+// NewMemoryStepRepository returns a StepRepository keeping its data in store.
+func NewMemoryStepRepository(store *MemoryStore) StepRepository {
+	return &memoryStepRepo{store: store}
+}
+
+func (r *memoryStepRepo) Create(ctx context.Context, step *models.Step) (int64, error) {
+	var id int64
+	err := r.store.inWorkspace(ctx, func(workspaceID int64) error {
+		sequence, ok := r.store.sequences[step.SequenceID]
+		if !ok || sequence.workspaceID != workspaceID {
+			return memConstraintError(foreignKeyViolation, "steps_workspace_sequence_fkey")
+		}
+
+		now := r.store.now()
+		id = r.store.newID()
+		stored := *step
+		stored.ID, stored.Version = id, 1
+		stored.WaitUnit = step.EffectiveWaitUnit()
+		stored.CreatedAt, stored.UpdatedAt, stored.DeletedAt = now, now, nil
+		r.store.steps[id] = &memRow[models.Step]{workspaceID: workspaceID, value: stored}
+		sequence.value.Version++
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *memoryStepRepo) Get(ctx context.Context, id int64) (*models.Step, error) {
+	var step *models.Step
+	err := r.store.inWorkspace(ctx, func(workspaceID int64) error {
+		row, ok := r.store.steps[id]
+		if !ok || row.workspaceID != workspaceID {
+			return sql.ErrNoRows
+		}
+		stored := row.value
+		step = &stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return step, nil
+}
+
+func (r *memoryStepRepo) Update(ctx context.Context, step *models.Step) error {
+	return r.store.inWorkspace(ctx, func(workspaceID int64) error {
+		row, err := r.writable(workspaceID, step.ID, step.Version)
+		if err != nil {
+			return err
+		}
+		row.value.Subject, row.value.Content = step.Subject, step.Content
+		row.value.StepOrder, row.value.WaitDays = step.StepOrder, step.WaitDays
+		row.value.WaitUnit = step.EffectiveWaitUnit()
+		row.value.Version++
+		row.value.UpdatedAt = r.store.now()
+		step.Version = row.value.Version
+		r.touchSequence(row.value.SequenceID)
+		return nil
+	})
+}
+
+func (r *memoryStepRepo) Delete(ctx context.Context, id int64, version int64) error {
+	return r.store.inWorkspace(ctx, func(workspaceID int64) error {
+		row, err := r.writable(workspaceID, id, version)
+		if err != nil {
+			return err
+		}
+		delete(r.store.steps, id)
+		r.touchSequence(row.value.SequenceID)
+		return nil
+	})
+}
+
+func (r *memoryStepRepo) ListBySequenceID(ctx context.Context, sequenceID int64) ([]*models.Step, error) {
+	var steps []*models.Step
+	err := r.store.inWorkspace(ctx, func(workspaceID int64) error {
+		steps = r.bySequence(workspaceID, sequenceID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].StepOrder < steps[j].StepOrder })
+	return steps, nil
+}
+
+func (r *memoryStepRepo) List(ctx context.Context, sequenceID int64, opts models.ListOptions) ([]*models.Step, string, error) {
+	steps := []*models.Step{}
+	err := r.store.inWorkspace(ctx, func(workspaceID int64) error {
+		for _, step := range r.bySequence(workspaceID, sequenceID) {
+			if !inCreatedRange(step.CreatedAt, opts.Filters) {
+				continue
+			}
+			if opts.Filters.NameContains != "" && !containsFold(step.Subject, opts.Filters.NameContains) {
+				continue
+			}
+			steps = append(steps, step)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return memPage(steps, opts, memStepSortKeys, "stepOrder", func(s *models.Step) int64 { return s.ID })
+}
+
+// writable returns the step a conditional write with version applies to.
+func (r *memoryStepRepo) writable(workspaceID, id, version int64) (*memRow[models.Step], error) {
+	row, ok := r.store.steps[id]
+	if !ok || row.workspaceID != workspaceID || (version != 0 && row.value.Version != version) {
+		return nil, memStale(r.store.steps, id, workspaceID)
+	}
+	return row, nil
+}
+
+func (r *memoryStepRepo) bySequence(workspaceID, sequenceID int64) []*models.Step {
+	var steps []*models.Step
+	for _, row := range r.store.steps {
+		if row.workspaceID == workspaceID && row.value.SequenceID == sequenceID {
+			step := row.value
+			steps = append(steps, &step)
+		}
+	}
+	return steps
+}
+
+// touchSequence bumps the version of the sequence owning a changed step, like
+// the PostgreSQL repository.
+func (r *memoryStepRepo) touchSequence(sequenceID int64) {
+	if sequence, ok := r.store.sequences[sequenceID]; ok {
+		sequence.value.Version++
+	}
+}
@@ -0,0 +1,21 @@
+package db
+
+import (
+	"context"
+	"sf_test/internal/tenant"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMemoryRepositories(t *testing.T) {
+	store := NewMemoryStore()
+	var workspaces atomic.Int64
+	runConformanceTests(t, repositories{
+		tx:        store,
+		sequences: NewMemorySequenceRepository(store),
+		steps:     NewMemoryStepRepository(store),
+		workspace: func(t *testing.T) context.Context {
+			return tenant.WithWorkspace(context.Background(), workspaces.Add(1))
+		},
+	})
+}
@@ -0,0 +1,370 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// migrationLockID is the key of the advisory lock held while migrating, so
+// replicas starting together do not apply the same migration twice.
+const migrationLockID = 7_305_112_466
+
+const createMigrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+// migrationFilePattern matches migration files such as 0003_workspaces.up.sql.
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// ErrChecksumMismatch is returned when an applied migration's file has been
+// edited since it was applied. Change the schema with a new migration instead.
+var ErrChecksumMismatch = errors.New("migration has changed since it was applied")
+
+// Migration is a numbered schema change and the statements undoing it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Checksum identifies the contents of the migration's up statements.
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// MigrationStatus describes a migration known to the binary or the database.
+type MigrationStatus struct {
+	Migration
+	// AppliedAt is nil for pending migrations.
+	AppliedAt *time.Time
+	// Modified is set when the applied checksum differs from the file's.
+	Modified bool
+	// Unknown is set for applied migrations this binary has no file for,
+	// such as those applied by a newer release.
+	Unknown bool
+}
+
+type appliedMigration struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// LoadMigrations reads the migrations in fsys, ordered by version. Every
+// migration needs both an up and a down file.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		if version == 0 {
+			return nil, fmt.Errorf("invalid migration file name %q: versions start at 1", entry.Name())
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has files named %q and %q", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %s needs both an up and a down file", migration)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator applies and reverts schema migrations, recording the applied ones
+// in the schema_migrations table.
+type Migrator struct {
+	db         *DB
+	migrations []Migration
+}
+
+// NewMigrator returns a migrator for the migrations embedded in the binary.
+func NewMigrator(db *DB) (*Migrator, error) {
+	fsys, err := fs.Sub(embeddedMigrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up applies every pending migration in order and returns those applied.
+// Each runs in its own transaction, so a failure leaves the earlier ones
+// applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.UpTo(ctx, math.MaxInt)
+}
+
+// UpTo is Up for the pending migrations up to and including version.
+func (m *Migrator) UpTo(ctx context.Context, version int) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		current, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		pending, err := pendingMigrations(m.migrations, current)
+		if err != nil {
+			return err
+		}
+		for _, migration := range pending {
+			if migration.Version > version {
+				break
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+					migration.Version, migration.Name, migration.Checksum())
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("applying migration %s: %w", migration, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last n applied migrations, newest first, and returns
+// those reverted.
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		current, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		versions := make([]int, 0, len(current))
+		for version := range current {
+			versions = append(versions, version)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+		if n < len(versions) {
+			versions = versions[:n]
+		}
+
+		for _, version := range versions {
+			migration, ok := m.find(version)
+			if !ok {
+				return fmt.Errorf("cannot revert migration %d: this binary has no file for it", version)
+			}
+			if current[version].checksum != migration.Checksum() {
+				return fmt.Errorf("cannot revert migration %s: %w", migration, ErrChecksumMismatch)
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("reverting migration %s: %w", migration, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists every migration known to the binary or the database.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	current, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return migrationStatuses(m.migrations, current), nil
+}
+
+// Pending returns the migrations the database still needs. Like Up, it
+// returns ErrChecksumMismatch when an applied migration has been edited.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	current, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pendingMigrations(m.migrations, current)
+}
+
+// applied reads the applied migrations without taking the migration lock.
+func (m *Migrator) applied(ctx context.Context) (map[int]appliedMigration, error) {
+	conn, err := m.db.Conn.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, createMigrationsTable); err != nil {
+		return nil, err
+	}
+	return loadApplied(ctx, conn)
+}
+
+func (m *Migrator) find(version int) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// locked runs fn on a single connection holding the migration advisory lock.
+// Other migrators wait for the lock, then find the work already done.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	if _, err := conn.ExecContext(ctx, createMigrationsTable); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func loadApplied(ctx context.Context, conn *sql.Conn) (map[int]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]appliedMigration{}
+	for rows.Next() {
+		var version int
+		var migration appliedMigration
+		if err := rows.Scan(&version, &migration.name, &migration.checksum, &migration.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = migration
+	}
+	return applied, rows.Err()
+}
+
+// pendingMigrations returns the migrations not yet applied, after checking
+// that the applied ones are unchanged and that none is missing in between.
+func pendingMigrations(migrations []Migration, applied map[int]appliedMigration) ([]Migration, error) {
+	var pending []Migration
+	for _, migration := range migrations {
+		record, ok := applied[migration.Version]
+		if !ok {
+			pending = append(pending, migration)
+			continue
+		}
+		if record.checksum != migration.Checksum() {
+			return nil, fmt.Errorf("migration %s: %w", migration, ErrChecksumMismatch)
+		}
+		if len(pending) > 0 {
+			return nil, fmt.Errorf("migration %s is applied but earlier migration %s is not", migration, pending[0])
+		}
+	}
+	return pending, nil
+}
+
+func migrationStatuses(migrations []Migration, applied map[int]appliedMigration) []MigrationStatus {
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	known := map[int]bool{}
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		status := MigrationStatus{Migration: migration}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.appliedAt
+			status.AppliedAt = &appliedAt
+			status.Modified = record.checksum != migration.Checksum()
+		}
+		statuses = append(statuses, status)
+	}
+	for version, record := range applied {
+		if !known[version] {
+			appliedAt := record.appliedAt
+			statuses = append(statuses, MigrationStatus{
+				Migration: Migration{Version: version, Name: record.name},
+				AppliedAt: &appliedAt,
+				Unknown:   true,
+			})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("orders by version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"0002_users.up.sql":     {Data: []byte("CREATE TABLE users ();")},
+			"0002_users.down.sql":   {Data: []byte("DROP TABLE users;")},
+			"0001_initial.up.sql":   {Data: []byte("CREATE TABLE things ();")},
+			"0001_initial.down.sql": {Data: []byte("DROP TABLE things;")},
+		}
+		migrations, err := LoadMigrations(fsys)
+		assert.NoError(t, err)
+		assert.Len(t, migrations, 2)
+		assert.Equal(t, 1, migrations[0].Version)
+		assert.Equal(t, "initial", migrations[0].Name)
+		assert.Equal(t, "DROP TABLE things;", migrations[0].Down)
+		assert.Equal(t, "0002_users", migrations[1].String())
+	})
+
+	t.Run("requires a down file", func(t *testing.T) {
+		fsys := fstest.MapFS{"0001_initial.up.sql": {Data: []byte("CREATE TABLE things ();")}}
+		_, err := LoadMigrations(fsys)
+		assert.ErrorContains(t, err, "needs both an up and a down file")
+	})
+
+	t.Run("rejects bad names", func(t *testing.T) {
+		fsys := fstest.MapFS{"initial.sql": {Data: []byte("SELECT 1;")}}
+		_, err := LoadMigrations(fsys)
+		assert.ErrorContains(t, err, "invalid migration file name")
+	})
+
+	t.Run("rejects two names for one version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"0001_initial.up.sql": {Data: []byte("SELECT 1;")},
+			"0001_other.down.sql": {Data: []byte("SELECT 1;")},
+		}
+		_, err := LoadMigrations(fsys)
+		assert.ErrorContains(t, err, "has files named")
+	})
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrator, err := NewMigrator(nil)
+	assert.NoError(t, err)
+	for i, migration := range migrator.migrations {
+		assert.Equal(t, i+1, migration.Version, "migration versions must be contiguous")
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Name: "initial", Up: "CREATE TABLE a ();", Down: "DROP TABLE a;"},
+		{Version: 2, Name: "second", Up: "CREATE TABLE b ();", Down: "DROP TABLE b;"},
+		{Version: 3, Name: "third", Up: "CREATE TABLE c ();", Down: "DROP TABLE c;"},
+	}
+	applied := func(versions ...int) map[int]appliedMigration {
+		records := map[int]appliedMigration{}
+		for _, version := range versions {
+			m := migrations[version-1]
+			records[version] = appliedMigration{name: m.Name, checksum: m.Checksum(), appliedAt: time.Now()}
+		}
+		return records
+	}
+
+	t.Run("returns unapplied migrations in order", func(t *testing.T) {
+		pending, err := pendingMigrations(migrations, applied(1))
+		assert.NoError(t, err)
+		assert.Equal(t, migrations[1:], pending)
+	})
+
+	t.Run("rejects modified migrations", func(t *testing.T) {
+		records := applied(1, 2)
+		records[2] = appliedMigration{name: "second", checksum: "edited"}
+		_, err := pendingMigrations(migrations, records)
+		assert.True(t, errors.Is(err, ErrChecksumMismatch))
+	})
+
+	t.Run("rejects gaps", func(t *testing.T) {
+		_, err := pendingMigrations(migrations, applied(1, 3))
+		assert.ErrorContains(t, err, "earlier migration 0002_second is not")
+	})
+
+	t.Run("status reports unknown migrations", func(t *testing.T) {
+		records := applied(1, 2, 3)
+		records[4] = appliedMigration{name: "newer", checksum: "x", appliedAt: time.Now()}
+		statuses := migrationStatuses(migrations, records)
+		assert.Len(t, statuses, 4)
+		assert.True(t, statuses[3].Unknown)
+		assert.False(t, statuses[0].Modified)
+		assert.NotNil(t, statuses[0].AppliedAt)
+	})
+}
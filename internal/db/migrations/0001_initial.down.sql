@@ -0,0 +1 @@
+DROP TABLE IF EXISTS idempotency_keys, job_item_errors, jobs, enrollments, contacts, steps, sequences;
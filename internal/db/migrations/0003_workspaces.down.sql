@@ -0,0 +1,17 @@
+-- Rows of every workspace are merged. External keys and contact emails are
+-- no longer unique afterwards, as they may clash between workspaces.
+DROP INDEX IF EXISTS contacts_workspace_email_idx;
+DROP INDEX IF EXISTS sequences_workspace_external_key_idx;
+
+ALTER TABLE steps DROP CONSTRAINT IF EXISTS steps_workspace_sequence_fkey;
+ALTER TABLE enrollments DROP CONSTRAINT IF EXISTS enrollments_workspace_sequence_fkey;
+ALTER TABLE enrollments DROP CONSTRAINT IF EXISTS enrollments_workspace_contact_fkey;
+ALTER TABLE job_item_errors DROP CONSTRAINT IF EXISTS job_item_errors_workspace_job_fkey;
+
+SELECT disable_workspace_isolation(t)
+FROM unnest(ARRAY['job_item_errors', 'jobs', 'enrollments', 'contacts', 'steps', 'sequences']) AS t;
+DROP FUNCTION IF EXISTS enable_workspace_isolation(TEXT);
+DROP FUNCTION IF EXISTS disable_workspace_isolation(TEXT);
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS workspace_id;
+DROP TABLE IF EXISTS workspaces;
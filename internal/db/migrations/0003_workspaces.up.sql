@@ -0,0 +1,70 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+    id BIGSERIAL PRIMARY KEY,
+    slug VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Rows created before workspaces existed belong to the default workspace.
+INSERT INTO workspaces (id, slug, name) VALUES (1, 'default', 'Default') ON CONFLICT (id) DO NOTHING;
+SELECT setval(pg_get_serial_sequence('workspaces', 'id'), (SELECT MAX(id) FROM workspaces));
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS workspace_id BIGINT NOT NULL DEFAULT 1 REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE api_keys ALTER COLUMN workspace_id DROP DEFAULT;
+
+-- Every table holding workspace data carries a workspace_id that defaults to
+-- the workspace bound to the transaction, and a row-level security policy
+-- hiding other workspaces' rows. The policies do not apply to superusers, so
+-- the application must connect as an ordinary role to be covered by them.
+-- Later migrations call these functions for the tables they add.
+CREATE OR REPLACE FUNCTION enable_workspace_isolation(t TEXT) RETURNS void AS $$
+BEGIN
+    EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS workspace_id BIGINT NOT NULL DEFAULT 1 REFERENCES workspaces(id) ON DELETE CASCADE', t);
+    EXECUTE format('ALTER TABLE %I ALTER COLUMN workspace_id SET DEFAULT NULLIF(current_setting(''app.workspace_id'', true), '''')::BIGINT', t);
+    EXECUTE format('CREATE UNIQUE INDEX IF NOT EXISTS %I ON %I (workspace_id, id)', t || '_workspace_id_idx', t);
+    EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+    EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+    EXECUTE format('DROP POLICY IF EXISTS workspace_isolation ON %I', t);
+    EXECUTE format('CREATE POLICY workspace_isolation ON %I USING (workspace_id = NULLIF(current_setting(''app.workspace_id'', true), '''')::BIGINT)', t);
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION disable_workspace_isolation(t TEXT) RETURNS void AS $$
+BEGIN
+    EXECUTE format('DROP POLICY IF EXISTS workspace_isolation ON %I', t);
+    EXECUTE format('ALTER TABLE %I NO FORCE ROW LEVEL SECURITY', t);
+    EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', t);
+    EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS workspace_id', t);
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT enable_workspace_isolation(t)
+FROM unnest(ARRAY['sequences', 'steps', 'contacts', 'enrollments', 'jobs', 'job_item_errors']) AS t;
+
+-- References between workspace data include the workspace, so a row can never
+-- point at another workspace's row. Foreign key checks bypass row-level security.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'steps_workspace_sequence_fkey') THEN
+        ALTER TABLE steps ADD CONSTRAINT steps_workspace_sequence_fkey
+            FOREIGN KEY (workspace_id, sequence_id) REFERENCES sequences (workspace_id, id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'enrollments_workspace_sequence_fkey') THEN
+        ALTER TABLE enrollments ADD CONSTRAINT enrollments_workspace_sequence_fkey
+            FOREIGN KEY (workspace_id, sequence_id) REFERENCES sequences (workspace_id, id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'enrollments_workspace_contact_fkey') THEN
+        ALTER TABLE enrollments ADD CONSTRAINT enrollments_workspace_contact_fkey
+            FOREIGN KEY (workspace_id, contact_id) REFERENCES contacts (workspace_id, id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'job_item_errors_workspace_job_fkey') THEN
+        ALTER TABLE job_item_errors ADD CONSTRAINT job_item_errors_workspace_job_fkey
+            FOREIGN KEY (workspace_id, job_id) REFERENCES jobs (workspace_id, id) ON DELETE CASCADE;
+    END IF;
+END $$;
+
+-- Unique keys are unique within a workspace.
+DROP INDEX IF EXISTS sequences_external_key_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS sequences_workspace_external_key_idx ON sequences (workspace_id, external_key) WHERE external_key IS NOT NULL;
+ALTER TABLE contacts DROP CONSTRAINT IF EXISTS contacts_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS contacts_workspace_email_idx ON contacts (workspace_id, email);
@@ -0,0 +1,4 @@
+ALTER TABLE sequences DROP CONSTRAINT IF EXISTS sequences_workspace_team_fkey;
+ALTER TABLE sequences DROP COLUMN IF EXISTS team_id;
+ALTER TABLE sequences DROP COLUMN IF EXISTS active;
+DROP TABLE IF EXISTS team_members, teams, users;
@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS users (
+    id BIGSERIAL PRIMARY KEY,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(320) NOT NULL DEFAULT '',
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS teams (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS team_members (
+    id BIGSERIAL PRIMARY KEY,
+    team_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    UNIQUE (team_id, user_id)
+);
+
+SELECT enable_workspace_isolation(t) FROM unnest(ARRAY['users', 'teams', 'team_members']) AS t;
+CREATE UNIQUE INDEX IF NOT EXISTS users_workspace_subject_idx ON users (workspace_id, subject);
+
+-- Sequences may be owned by a team; only its editors and owners change them.
+ALTER TABLE sequences ADD COLUMN IF NOT EXISTS team_id BIGINT;
+ALTER TABLE sequences ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT false;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'team_members_workspace_team_fkey') THEN
+        ALTER TABLE team_members ADD CONSTRAINT team_members_workspace_team_fkey
+            FOREIGN KEY (workspace_id, team_id) REFERENCES teams (workspace_id, id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'team_members_workspace_user_fkey') THEN
+        ALTER TABLE team_members ADD CONSTRAINT team_members_workspace_user_fkey
+            FOREIGN KEY (workspace_id, user_id) REFERENCES users (workspace_id, id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'sequences_workspace_team_fkey') THEN
+        ALTER TABLE sequences ADD CONSTRAINT sequences_workspace_team_fkey
+            FOREIGN KEY (workspace_id, team_id) REFERENCES teams (workspace_id, id);
+    END IF;
+END $$;
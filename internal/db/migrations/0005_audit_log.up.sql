@@ -0,0 +1,25 @@
+-- The audit log records every change made through the sequence and step
+-- services. Entries are written in the transaction of the change they
+-- describe and can never be altered.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    resource_type VARCHAR(32) NOT NULL,
+    resource_id BIGINT NOT NULL,
+    changes JSONB NOT NULL,
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+SELECT enable_workspace_isolation('audit_log');
+CREATE INDEX IF NOT EXISTS audit_log_resource_idx ON audit_log (workspace_id, resource_type, resource_id);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS sequences_notify_sends ON sequences;
+DROP TABLE IF EXISTS sends;
+DROP FUNCTION IF EXISTS notify_sends_due();
@@ -0,0 +1,49 @@
+-- A send delivers one step to one enrolled contact once it is due. Delivering
+-- a step schedules the next one, so each enrollment has at most one pending send.
+CREATE TABLE IF NOT EXISTS sends (
+    id BIGSERIAL PRIMARY KEY,
+    enrollment_id BIGINT NOT NULL,
+    step_id BIGINT NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (enrollment_id, step_id)
+);
+
+SELECT enable_workspace_isolation('sends');
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'sends_workspace_enrollment_fkey') THEN
+        ALTER TABLE sends ADD CONSTRAINT sends_workspace_enrollment_fkey
+            FOREIGN KEY (workspace_id, enrollment_id) REFERENCES enrollments (workspace_id, id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'sends_workspace_step_fkey') THEN
+        ALTER TABLE sends ADD CONSTRAINT sends_workspace_step_fkey
+            FOREIGN KEY (workspace_id, step_id) REFERENCES steps (workspace_id, id) ON DELETE CASCADE;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS sends_pending_idx ON sends (workspace_id, due_at) WHERE status = 'pending';
+
+-- Send workers LISTEN on sends_due and are woken with the workspace ID as
+-- payload when a send is scheduled or its sequence is activated. PostgreSQL
+-- folds identical notifications of a transaction into one.
+CREATE OR REPLACE FUNCTION notify_sends_due() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('sends_due', NEW.workspace_id::TEXT);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS sends_notify ON sends;
+CREATE TRIGGER sends_notify AFTER INSERT OR UPDATE OF due_at, status ON sends
+    FOR EACH ROW WHEN (NEW.status = 'pending') EXECUTE FUNCTION notify_sends_due();
+
+DROP TRIGGER IF EXISTS sequences_notify_sends ON sequences;
+CREATE TRIGGER sequences_notify_sends AFTER UPDATE OF active ON sequences
+    FOR EACH ROW WHEN (NEW.active AND NOT OLD.active) EXECUTE FUNCTION notify_sends_due();
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS holidays;
+ALTER TABLE contacts DROP COLUMN IF EXISTS country;
+ALTER TABLE steps DROP CONSTRAINT IF EXISTS steps_wait_unit_check;
+ALTER TABLE steps DROP COLUMN IF EXISTS wait_unit;
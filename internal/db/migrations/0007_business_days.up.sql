@@ -0,0 +1,28 @@
+-- Steps wait in calendar days, business days or hours.
+ALTER TABLE steps ADD COLUMN IF NOT EXISTS wait_unit VARCHAR(32) NOT NULL DEFAULT 'days';
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'steps_wait_unit_check') THEN
+        ALTER TABLE steps ADD CONSTRAINT steps_wait_unit_check
+            CHECK (wait_unit IN ('days', 'businessDays', 'hours'));
+    END IF;
+END $$;
+
+-- A contact's country, as an ISO 3166-1 alpha-2 code, selects the holidays
+-- its sends skip.
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS country VARCHAR(2) NOT NULL DEFAULT '';
+
+-- Nothing is sent on holidays. Holidays without a country apply to every
+-- contact of the workspace, the others to the contacts in their country.
+CREATE TABLE IF NOT EXISTS holidays (
+    id BIGSERIAL PRIMARY KEY,
+    country VARCHAR(2) NOT NULL DEFAULT '',
+    date DATE NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+SELECT enable_workspace_isolation('holidays');
+
+CREATE UNIQUE INDEX IF NOT EXISTS holidays_workspace_country_date_idx ON holidays (workspace_id, country, date);
@@ -0,0 +1,3 @@
+-- Keys created by a user act as that user, so the user's team roles apply
+-- to them. Keys created from the command line have no user.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS user_subject VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sf_test/internal/models"
+	"strings"
+	"time"
+)
+
+// cursor is the decoded form of an opaque page cursor: the sort key and the
+// sort value and ID of the last item on the previous page.
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, models.ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, models.ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// sortField describes a column a list can be ordered by and how to read the
+// cursor value from the last item of a page.
+type sortField[T any] struct {
+	column string
+	value  func(T) string
+}
+
+// formatCursorTime formats a timestamp for use as a cursor value.
+func formatCursorTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// pageQuery assembles a keyset-paginated SELECT. Items are ordered by the sort
+// column with the id column as a tie breaker so pages never overlap.
+type pageQuery[T any] struct {
+	selectFrom  string
+	idColumn    string
+	sortFields  map[string]sortField[T]
+	defaultSort string
+	where       []string
+	args        []interface{}
+}
+
+// filter adds a condition; each "?" in cond is replaced by the next placeholder.
+func (q *pageQuery[T]) filter(cond string, args ...interface{}) {
+	for _, arg := range args {
+		q.args = append(q.args, arg)
+		cond = strings.Replace(cond, "?", fmt.Sprintf("$%d", len(q.args)), 1)
+	}
+	q.where = append(q.where, cond)
+}
+
+// withFilter returns a copy of q with cond added, leaving q unchanged. Reads
+// build their query inside a function that may run again on the primary, so
+// they add the workspace filter with withFilter rather than filter.
+func (q *pageQuery[T]) withFilter(cond string, args ...interface{}) *pageQuery[T] {
+	c := q.clone()
+	c.filter(cond, args...)
+	return c
+}
+
+func (q *pageQuery[T]) clone() *pageQuery[T] {
+	c := *q
+	c.where = append([]string(nil), q.where...)
+	c.args = append([]interface{}(nil), q.args...)
+	return &c
+}
+
+// filterCreatedAt applies the created-at range filters to column.
+func (q *pageQuery[T]) filterCreatedAt(column string, filters models.ListFilters) {
+	if filters.CreatedAfter != nil {
+		q.filter(column+" >= ?", *filters.CreatedAfter)
+	}
+	if filters.CreatedBefore != nil {
+		q.filter(column+" < ?", *filters.CreatedBefore)
+	}
+}
+
+// build returns the SQL and arguments for one page. It fetches one extra row
+// so the caller can tell whether another page follows. It leaves q unchanged.
+func (q *pageQuery[T]) build(opts models.ListOptions) (string, []interface{}, error) {
+	q = q.clone()
+	sortKey := opts.Sort
+	if sortKey == "" {
+		sortKey = q.defaultSort
+	}
+	field, ok := q.sortFields[strings.TrimPrefix(sortKey, "-")]
+	if !ok {
+		return "", nil, fmt.Errorf("%w %q", models.ErrInvalidSort, strings.TrimPrefix(sortKey, "-"))
+	}
+	direction, comparison := "ASC", ">"
+	if strings.HasPrefix(sortKey, "-") {
+		direction, comparison = "DESC", "<"
+	}
+
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return "", nil, err
+		}
+		if c.Sort != sortKey {
+			return "", nil, models.ErrInvalidCursor
+		}
+		q.filter(fmt.Sprintf("(%s, %s) %s (?, ?)", field.column, q.idColumn, comparison), c.Value, c.ID)
+	}
+
+	query := q.selectFrom
+	if len(q.where) > 0 {
+		query += " WHERE " + strings.Join(q.where, " AND ")
+	}
+	q.args = append(q.args, opts.PageLimit()+1)
+	query += fmt.Sprintf(" ORDER BY %s %s, %s %s LIMIT $%d", field.column, direction, q.idColumn, direction, len(q.args))
+	return query, q.args, nil
+}
+
+// page trims the extra row fetched by build and returns the cursor for the
+// next page, or an empty string on the last page.
+func (q *pageQuery[T]) page(items []T, opts models.ListOptions, id func(T) int64) ([]T, string) {
+	limit := opts.PageLimit()
+	if len(items) <= limit {
+		return items, ""
+	}
+	items = items[:limit]
+	sortKey := opts.Sort
+	if sortKey == "" {
+		sortKey = q.defaultSort
+	}
+	last := items[len(items)-1]
+	field := q.sortFields[strings.TrimPrefix(sortKey, "-")]
+	return items, encodeCursor(cursor{Sort: sortKey, Value: field.value(last), ID: id(last)})
+}
+
+// containsPattern builds an ILIKE pattern matching s anywhere, escaping wildcards.
+func containsPattern(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(s) + "%"
+}
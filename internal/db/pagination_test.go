@@ -0,0 +1,36 @@
+package db
+
+import (
+	"testing"
+
+	"sf_test/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageQuery_BuildsTheSameQueryWhenRetried(t *testing.T) {
+	q := &pageQuery[*models.Sequence]{
+		selectFrom:  "SELECT id FROM sequences",
+		idColumn:    "id",
+		sortFields:  sequenceSortFields,
+		defaultSort: "createdAt",
+	}
+	q.filter("name ILIKE ?", "%a%")
+	opts := models.ListOptions{Cursor: encodeCursor(cursor{Sort: "createdAt", Value: "2026-01-01T00:00:00Z", ID: 7})}
+
+	// readInWorkspace runs its function again on the primary when a replica
+	// fails, so building the query must not change q.
+	fn := func() (string, []interface{}) {
+		query, args, err := q.withFilter("workspace_id = ?", int64(1)).build(opts)
+		assert.NoError(t, err)
+		return query, args
+	}
+	query, args := fn()
+	assert.Equal(t, "SELECT id FROM sequences WHERE name ILIKE $1 AND workspace_id = $2 AND (created_at, id) > ($3, $4) ORDER BY created_at ASC, id ASC LIMIT $5", query)
+	assert.Equal(t, []interface{}{"%a%", int64(1), "2026-01-01T00:00:00Z", int64(7), opts.PageLimit() + 1}, args)
+
+	retriedQuery, retriedArgs := fn()
+	assert.Equal(t, query, retriedQuery)
+	assert.Equal(t, args, retriedArgs)
+	assert.Len(t, q.where, 1)
+}
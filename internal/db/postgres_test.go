@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sf_test/internal/models"
+	"sf_test/internal/tenant"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPostgresRepositories runs the conformance tests against the database
+// at TEST_DATABASE_URL, migrating it first. Each test gets a new workspace,
+// so the database can be reused between runs.
+func TestPostgresRepositories(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	dbConn, err := NewDB(url, Options{QueryTimeout: 10 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbConn.Close()
+
+	migrator, err := NewMigrator(dbConn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("serializable transactions are retried", func(t *testing.T) {
+		attempts := 0
+		err := dbConn.WithinTx(Serializable(context.Background()), func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return &pq.Error{Code: serializationFailure}
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+
+		attempts = 0
+		err = dbConn.WithinTx(context.Background(), func(ctx context.Context) error {
+			attempts++
+			return &pq.Error{Code: serializationFailure}
+		})
+		assert.True(t, IsSerializationFailure(err))
+		assert.Equal(t, 1, attempts, "only serializable transactions are retried")
+	})
+
+	t.Run("nested transactions that cannot restore the workspace fail", func(t *testing.T) {
+		outer := tenant.WithWorkspace(context.Background(), 1)
+		err := dbConn.WithinTx(outer, func(ctx context.Context) error {
+			inner := tenant.WithWorkspace(ctx, 2)
+			// The failed statement aborts the transaction, so switching back
+			// to workspace 1 fails too. The outer function ignores that.
+			_ = dbConn.WithinTx(inner, func(ctx context.Context) error {
+				_, _ = dbConn.querier(ctx).ExecContext(ctx, `SELECT 1 / 0`)
+				return nil
+			})
+			return nil
+		})
+		assert.ErrorContains(t, err, "restoring workspace 1")
+	})
+
+	t.Run("redo re-applies only the reverted migration", func(t *testing.T) {
+		ctx := context.Background()
+		last := migrator.migrations[len(migrator.migrations)-1]
+		reverted, err := migrator.Down(ctx, 2)
+		assert.NoError(t, err)
+		assert.Len(t, reverted, 2)
+
+		applied, err := migrator.UpTo(ctx, reverted[1].Version)
+		assert.NoError(t, err)
+		assert.Equal(t, []Migration{reverted[1]}, applied)
+		pending, err := migrator.Pending(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []Migration{last}, pending)
+
+		_, err = migrator.Up(ctx)
+		assert.NoError(t, err)
+	})
+
+	workspaceRepo := NewWorkspaceRepository(dbConn)
+	run := time.Now().UnixNano()
+	var workspaces atomic.Int64
+	repos := repositories{
+		tx:        dbConn,
+		sequences: NewSequenceRepository(dbConn),
+		steps:     NewStepRepository(dbConn),
+		workspace: func(t *testing.T) context.Context {
+			slug := fmt.Sprintf("conformance-%d-%d", run, workspaces.Add(1))
+			id, err := workspaceRepo.Create(context.Background(), &models.Workspace{Slug: slug, Name: slug})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return tenant.WithWorkspace(context.Background(), id)
+		},
+	}
+	runConformanceTests(t, repos)
+
+	t.Run("search snippets are escaped", func(t *testing.T) {
+		ctx := repos.workspace(t)
+		sequence := newSequence("<img src=x onerror=alert(1)> webinar")
+		sequence.Steps = []models.Step{{
+			Subject: "Join our <b>webinar</b>",
+			Content: `<p onclick="alert(1)">The webinar starts at 5 &amp; runs an hour.</p><script>alert(1)</script>`,
+		}}
+		createSequence(t, repos, ctx, sequence)
+
+		hits, err := NewSearchRepository(dbConn).Search(ctx, "webinar", 10)
+		assert.NoError(t, err)
+		if !assert.Len(t, hits, 2) {
+			return
+		}
+		for _, hit := range hits {
+			assert.Contains(t, hit.Snippet, "<mark>webinar</mark>")
+			assert.NotContains(t, hit.Snippet, "<img")
+			assert.NotContains(t, hit.Snippet, "<b>")
+			assert.NotContains(t, hit.Snippet, "<p")
+			assert.NotContains(t, hit.Snippet, "<script")
+		}
+	})
+
+	t.Run("contacts with an existing email are not created again", func(t *testing.T) {
+		ctx := repos.workspace(t)
+		contacts := NewContactRepository(dbConn)
+		id, created, err := contacts.Create(ctx, &models.Contact{Email: "ada@example.com"})
+		assert.NoError(t, err)
+		assert.True(t, created)
+
+		again, created, err := contacts.Create(ctx, &models.Contact{Email: "ada@example.com", FirstName: "Ada"})
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, id, again)
+	})
+
+	t.Run("row-level security hides rows without a workspace", func(t *testing.T) {
+		ctx := repos.workspace(t)
+		createSequence(t, repos, ctx, newSequence("Hidden"))
+
+		tx, err := dbConn.Conn.BeginTx(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tx.Rollback()
+		// Run as a role like the one the application connects as: neither a
+		// superuser nor exempt from row-level security.
+		bypasses, err := dbConn.BypassesRowSecurity(context.Background())
+		assert.NoError(t, err)
+		if bypasses {
+			role := fmt.Sprintf("rls_test_%d", run)
+			for _, statement := range []string{
+				"CREATE ROLE " + role + " NOLOGIN NOSUPERUSER NOBYPASSRLS",
+				"GRANT SELECT ON sequences TO " + role,
+				"SET LOCAL ROLE " + role,
+			} {
+				if _, err := tx.Exec(statement); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		var count int
+		assert.NoError(t, tx.QueryRow(`SELECT COUNT(*) FROM sequences`).Scan(&count))
+		assert.Zero(t, count)
+	})
+}
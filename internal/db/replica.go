@@ -0,0 +1,171 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"sf_test/internal/tenant"
+
+	"github.com/lib/pq"
+)
+
+const defaultReplicaCheckInterval = 10 * time.Second
+
+// replica is a read-only copy of the database. Read-only repository calls
+// are spread over the replicas that answered their last health check.
+type replica struct {
+	conn    *sql.DB
+	healthy atomic.Bool
+}
+
+type primaryKey struct{}
+
+// WithPrimary marks ctx so that reads made with it go to the primary, for
+// callers that must see their own recent writes.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// ReadsPrimary reports whether ctx was marked with WithPrimary.
+func ReadsPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
+
+// openReplicas opens a pool for each replica and starts checking their
+// health. Replicas that are down do not prevent startup.
+func (db *DB) openReplicas(connectionStrings []string, opts Options) error {
+	for _, connectionString := range connectionStrings {
+		conn, err := sql.Open("postgres", connectionString)
+		if err != nil {
+			return err
+		}
+		configurePool(conn, opts)
+		db.replicas = append(db.replicas, &replica{conn: conn})
+	}
+	if len(db.replicas) == 0 {
+		return nil
+	}
+
+	interval := opts.ReplicaCheckInterval
+	if interval <= 0 {
+		interval = defaultReplicaCheckInterval
+	}
+	db.checkReplicas()
+	db.stop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-db.stop:
+				return
+			case <-ticker.C:
+				db.checkReplicas()
+			}
+		}
+	}()
+	return nil
+}
+
+// checkReplicas pings every replica and records whether it answered.
+func (db *DB) checkReplicas() {
+	for i, replica := range db.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), connectMaxBackoff)
+		err := replica.conn.PingContext(ctx)
+		cancel()
+		if healthy := err == nil; replica.healthy.Swap(healthy) != healthy {
+			if healthy {
+				log.Printf("Database replica %d is healthy", i)
+			} else {
+				log.Printf("Database replica %d is unhealthy: %v", i, err)
+			}
+		}
+	}
+}
+
+// replica returns the next healthy replica, or nil when there is none.
+func (db *DB) replica() *replica {
+	for range db.replicas {
+		replica := db.replicas[db.nextReplica.Add(1)%uint64(len(db.replicas))]
+		if replica.healthy.Load() {
+			return replica
+		}
+	}
+	return nil
+}
+
+// readInWorkspace is inWorkspace for calls that only read. Outside a
+// transaction, and unless ctx asks for the primary, fn runs in a read-only
+// transaction on a healthy replica. If the replica cannot serve it, fn runs
+// again on the primary, so fn must start over each time it is called: it
+// resets its results and does not change state shared between calls.
+func (db *DB) readInWorkspace(ctx context.Context, fn func(ctx context.Context, workspaceID int64) error) error {
+	workspaceID, ok := tenant.WorkspaceFrom(ctx)
+	if !ok {
+		return ErrNoWorkspace
+	}
+	_, inTx := ctx.Value(txKey{}).(*txState)
+	replica := db.replica()
+	if inTx || ReadsPrimary(ctx) || replica == nil {
+		return db.inWorkspace(ctx, fn)
+	}
+
+	replicaCtx, cancel := db.withQueryTimeout(ctx)
+	defer cancel()
+	err := db.runTx(replicaCtx, replica.conn, &sql.TxOptions{ReadOnly: true}, workspaceID, func(ctx context.Context) error {
+		return fn(ctx, workspaceID)
+	})
+	if !replicaFailed(err) || ctx.Err() != nil {
+		return err
+	}
+	if !isServerError(err) {
+		replica.healthy.Store(false)
+	}
+	log.Printf("Reading from replica failed, using primary: %v", err)
+	return db.inWorkspace(ctx, fn)
+}
+
+// replicaFailed reports whether err means the replica could not answer, as
+// opposed to the query failing or finding nothing. Replicas cancel queries
+// that conflict with replaying changes from the primary, which is reported
+// as a serialization failure.
+func replicaFailed(err error) bool {
+	if err == nil || errors.Is(err, sql.ErrNoRows) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return isConnectionError(err)
+	}
+	switch pqErr.Code.Class() {
+	case "08", "57": // connection exception, operator intervention
+		return true
+	}
+	return pqErr.Code == serializationFailure
+}
+
+// isConnectionError reports whether err means the connection to the database
+// could not be made or was lost.
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isServerError reports whether err was reported by a server that was
+// reachable, as opposed to a failed connection.
+func isServerError(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Class() != "08"
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+
+	"sf_test/internal/tenant"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+const unreachableDSN = "postgres://postgres@127.0.0.1:1/none?sslmode=disable&connect_timeout=1"
+
+func TestReplica_SkipsUnhealthy(t *testing.T) {
+	db := &DB{replicas: []*replica{{}, {}, {}}}
+	assert.Nil(t, db.replica())
+
+	db.replicas[0].healthy.Store(true)
+	db.replicas[2].healthy.Store(true)
+	seen := map[*replica]int{}
+	for i := 0; i < 4; i++ {
+		seen[db.replica()]++
+	}
+	assert.Equal(t, map[*replica]int{db.replicas[0]: 2, db.replicas[2]: 2}, seen)
+}
+
+func TestWithPrimary(t *testing.T) {
+	assert.False(t, ReadsPrimary(context.Background()))
+	assert.True(t, ReadsPrimary(WithPrimary(context.Background())))
+}
+
+func TestReplicaFailed(t *testing.T) {
+	assert.False(t, replicaFailed(nil))
+	assert.False(t, replicaFailed(sql.ErrNoRows))
+	assert.False(t, replicaFailed(context.DeadlineExceeded))
+	assert.False(t, replicaFailed(&pq.Error{Code: uniqueViolation}))
+	assert.False(t, replicaFailed(errors.New("sql: Scan error on column index 0")))
+	assert.True(t, replicaFailed(&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}))
+	assert.True(t, replicaFailed(driver.ErrBadConn))
+	assert.True(t, replicaFailed(io.ErrUnexpectedEOF))
+	assert.True(t, replicaFailed(&pq.Error{Code: "57P03"}))
+	assert.True(t, replicaFailed(fmt.Errorf("listing: %w", &pq.Error{Code: serializationFailure})))
+}
+
+func TestReadInWorkspace_FallsBackToPrimary(t *testing.T) {
+	primary, err := sql.Open("postgres", unreachableDSN)
+	assert.NoError(t, err)
+	db := &DB{Conn: primary}
+	defer db.Close()
+	assert.NoError(t, db.openReplicas([]string{unreachableDSN}, Options{}))
+	assert.False(t, db.replicas[0].healthy.Load(), "unreachable replicas start unhealthy")
+
+	// A replica that fails a read is taken out of rotation and the read is
+	// retried on the primary.
+	db.replicas[0].healthy.Store(true)
+	calls := 0
+	err = db.readInWorkspace(tenant.WithWorkspace(context.Background(), 1), func(ctx context.Context, workspaceID int64) error {
+		calls++
+		return nil
+	})
+	assert.Error(t, err)
+	assert.False(t, db.replicas[0].healthy.Load())
+	assert.Equal(t, 0, calls, "no transaction could begin on either database")
+
+	assert.ErrorIs(t, db.readInWorkspace(context.Background(), nil), ErrNoWorkspace)
+}
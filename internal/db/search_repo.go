@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"html"
+	"sf_test/internal/models"
+	"strings"
+)
+
+type SearchRepository interface {
+	Search(ctx context.Context, query string, limit int) ([]*models.SearchHit, error)
+}
+
+type searchRepo struct {
+	db *DB
+}
+
+func NewSearchRepository(db *DB) SearchRepository {
+	return &searchRepo{db: db}
+}
+
+// Snippets are highlighted between these control characters, which are
+// removed from the text beforehand, and escaped before they are marked up.
+const (
+	snippetStart = "\x02"
+	snippetStop  = "\x03"
+)
+
+// Search matches the query against sequence names and step subjects and
+// content, returning hits ordered by rank with the matches marked in the snippet.
+// Snippets are plain text: step content has its tags removed, and the snippet
+// is HTML-escaped apart from the <mark> elements around matches.
+func (r *searchRepo) Search(ctx context.Context, query string, limit int) ([]*models.SearchHit, error) {
+	sqlQuery := `
+        WITH q AS (SELECT websearch_to_tsquery('english', $1) AS query)
+        SELECT 'sequence', s.id, s.name, NULL::BIGINT, NULL::TEXT,
+            ts_rank(s.search_vector, q.query),
+            ts_headline('english', translate(s.name, E'\x02\x03', ''), q.query, E'StartSel=\x02, StopSel=\x03, HighlightAll=true')
+        FROM sequences s, q
+        WHERE s.search_vector @@ q.query AND s.workspace_id = $3
+        UNION ALL
+        SELECT 'step', s.id, s.name, st.id, st.subject,
+            ts_rank(st.search_vector, q.query),
+            ts_headline('english',
+                translate(st.subject || ' ' || regexp_replace(st.content, '<[^>]*>', ' ', 'g'), E'\x02\x03', ''),
+                q.query, E'StartSel=\x02, StopSel=\x03, MaxWords=35, MinWords=15, MaxFragments=2')
+        FROM steps st
+        JOIN sequences s ON s.id = st.sequence_id AND s.workspace_id = st.workspace_id, q
+        WHERE st.search_vector @@ q.query AND st.workspace_id = $3
+        ORDER BY 6 DESC, 2, 4 NULLS FIRST
+        LIMIT $2
+    `
+	hits := []*models.SearchHit{}
+	err := r.db.readInWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		hits = []*models.SearchHit{}
+		rows, err := r.db.querier(ctx).QueryContext(ctx, sqlQuery, query, limit, workspaceID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			hit := &models.SearchHit{}
+			var stepID sql.NullInt64
+			var subject sql.NullString
+			if err := rows.Scan(&hit.Type, &hit.Sequence.ID, &hit.Sequence.Name, &stepID, &subject, &hit.Rank, &hit.Snippet); err != nil {
+				return err
+			}
+			hit.Snippet = markSnippet(hit.Snippet)
+			if stepID.Valid {
+				hit.Step = &models.SearchStep{ID: stepID.Int64, Subject: subject.String}
+			}
+			hits = append(hits, hit)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+// markSnippet escapes a highlighted snippet and wraps its matches in <mark>.
+// Entities left in text taken from HTML are decoded first so they are not
+// escaped twice.
+func markSnippet(snippet string) string {
+	escape := func(text string) string { return html.EscapeString(html.UnescapeString(text)) }
+	parts := strings.Split(snippet, snippetStart)
+	var b strings.Builder
+	b.WriteString(escape(parts[0]))
+	for _, part := range parts[1:] {
+		match, rest, _ := strings.Cut(part, snippetStop)
+		b.WriteString("<mark>" + escape(match) + "</mark>" + escape(rest))
+	}
+	return b.String()
+}
@@ -0,0 +1,23 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkSnippet(t *testing.T) {
+	tests := []struct {
+		snippet string
+		want    string
+	}{
+		{"no matches", "no matches"},
+		{"join our \x02webinar\x03 today", "join our <mark>webinar</mark> today"},
+		{"\x02a\x03 and \x02b\x03", "<mark>a</mark> and <mark>b</mark>"},
+		{"<script>alert(1)</script> \x02<b>x</b>\x03", "&lt;script&gt;alert(1)&lt;/script&gt; <mark>&lt;b&gt;x&lt;/b&gt;</mark>"},
+		{"5 &amp; \x02fish\x03 &lt;3", "5 &amp; <mark>fish</mark> &lt;3"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, markSnippet(tt.snippet), tt.snippet)
+	}
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sf_test/internal/models"
+	"time"
+)
+
+// SendsDueChannel is the channel notified, with the workspace ID as payload,
+// when a send is scheduled or a sequence with pending sends is activated.
+const SendsDueChannel = "sends_due"
+
+type SendRepository interface {
+	ClaimDue(ctx context.Context, now time.Time) (*models.Send, error)
+	MarkSent(ctx context.Context, id int64, sentAt time.Time) error
+	Retry(ctx context.Context, id int64, reason string, dueAt time.Time) error
+	MarkFailed(ctx context.Context, id int64, reason string) error
+	Schedule(ctx context.Context, enrollmentID, stepID int64, dueAt time.Time) error
+	NextDueAt(ctx context.Context) (*time.Time, error)
+	ListByEnrollment(ctx context.Context, enrollmentID int64) ([]*models.Send, error)
+}
+
+type sendRepo struct {
+	db *DB
+}
+
+func NewSendRepository(db *DB) SendRepository {
+	return &sendRepo{db: db}
+}
+
+// ClaimDue locks the earliest pending send of an active sequence due at now and
+// returns it with its message and contact's country, or sql.ErrNoRows when none is due. Sends
+// locked by other workers are skipped. The lock is held until the
+// transaction ends, so call it within one that also records the outcome.
+func (r *sendRepo) ClaimDue(ctx context.Context, now time.Time) (*models.Send, error) {
+	query := `
+        SELECT s.id, s.enrollment_id, e.sequence_id, s.step_id, st.step_order, s.status, s.due_at, s.attempts, s.last_error, s.sent_at,
+            c.email, st.subject, st.content, c.country
+        FROM sends s
+        JOIN enrollments e ON e.id = s.enrollment_id AND e.workspace_id = s.workspace_id
+        JOIN sequences sq ON sq.id = e.sequence_id AND sq.workspace_id = s.workspace_id
+        JOIN steps st ON st.id = s.step_id AND st.workspace_id = s.workspace_id
+        JOIN contacts c ON c.id = e.contact_id AND c.workspace_id = s.workspace_id
+        WHERE s.workspace_id = $1 AND s.status = 'pending' AND s.due_at <= $2 AND sq.active
+        ORDER BY s.due_at, s.id
+        LIMIT 1
+        FOR UPDATE OF s SKIP LOCKED
+    `
+	send := &models.Send{}
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		return r.db.querier(ctx).QueryRowContext(ctx, query, workspaceID, now).Scan(
+			&send.ID, &send.EnrollmentID, &send.SequenceID, &send.StepID, &send.StepOrder, &send.Status, &send.DueAt,
+			&send.Attempts, &send.LastError, &send.SentAt, &send.Recipient, &send.Subject, &send.Content, &send.Country,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return send, nil
+}
+
+// MarkSent records that the send was delivered at sentAt.
+func (r *sendRepo) MarkSent(ctx context.Context, id int64, sentAt time.Time) error {
+	query := `
+        UPDATE sends
+        SET status = 'sent', attempts = attempts + 1, sent_at = $3, updated_at = NOW()
+        WHERE id = $1 AND workspace_id = $2
+    `
+	return r.update(ctx, query, id, sentAt)
+}
+
+// Retry records a failed attempt and makes the send due again at dueAt.
+func (r *sendRepo) Retry(ctx context.Context, id int64, reason string, dueAt time.Time) error {
+	query := `
+        UPDATE sends
+        SET attempts = attempts + 1, last_error = $3, due_at = $4, updated_at = NOW()
+        WHERE id = $1 AND workspace_id = $2
+    `
+	return r.update(ctx, query, id, reason, dueAt)
+}
+
+// MarkFailed records a failed attempt and gives up on the send.
+func (r *sendRepo) MarkFailed(ctx context.Context, id int64, reason string) error {
+	query := `
+        UPDATE sends
+        SET status = 'failed', attempts = attempts + 1, last_error = $3, updated_at = NOW()
+        WHERE id = $1 AND workspace_id = $2
+    `
+	return r.update(ctx, query, id, reason)
+}
+
+func (r *sendRepo) update(ctx context.Context, query string, id int64, args ...interface{}) error {
+	return r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		result, err := r.db.querier(ctx).ExecContext(ctx, query, append([]interface{}{id, workspaceID}, args...)...)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// Schedule makes the step due for the enrollment at dueAt. A step already
+// scheduled for the enrollment is left as it is.
+func (r *sendRepo) Schedule(ctx context.Context, enrollmentID, stepID int64, dueAt time.Time) error {
+	query := `
+        INSERT INTO sends (workspace_id, enrollment_id, step_id, due_at, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, NOW(), NOW())
+        ON CONFLICT (enrollment_id, step_id) DO NOTHING
+    `
+	return r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		_, err := r.db.querier(ctx).ExecContext(ctx, query, workspaceID, enrollmentID, stepID, dueAt)
+		return err
+	})
+}
+
+// NextDueAt returns when the earliest pending send of an active sequence is
+// due, or nil when there is none.
+func (r *sendRepo) NextDueAt(ctx context.Context) (*time.Time, error) {
+	query := `
+        SELECT MIN(s.due_at)
+        FROM sends s
+        JOIN enrollments e ON e.id = s.enrollment_id AND e.workspace_id = s.workspace_id
+        JOIN sequences sq ON sq.id = e.sequence_id AND sq.workspace_id = s.workspace_id
+        WHERE s.workspace_id = $1 AND s.status = 'pending' AND sq.active
+    `
+	var dueAt sql.NullTime
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		return r.db.querier(ctx).QueryRowContext(ctx, query, workspaceID).Scan(&dueAt)
+	})
+	if err != nil || !dueAt.Valid {
+		return nil, err
+	}
+	return &dueAt.Time, nil
+}
+
+// ListByEnrollment returns the sends scheduled for the enrollment in step
+// order, without their message.
+func (r *sendRepo) ListByEnrollment(ctx context.Context, enrollmentID int64) ([]*models.Send, error) {
+	query := `
+        SELECT s.id, s.enrollment_id, e.sequence_id, s.step_id, st.step_order, s.status, s.due_at, s.attempts, s.last_error, s.sent_at
+        FROM sends s
+        JOIN enrollments e ON e.id = s.enrollment_id AND e.workspace_id = s.workspace_id
+        JOIN steps st ON st.id = s.step_id AND st.workspace_id = s.workspace_id
+        WHERE s.enrollment_id = $1 AND s.workspace_id = $2
+        ORDER BY st.step_order
+    `
+	var sends []*models.Send
+	err := r.db.readInWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		sends = nil
+		rows, err := r.db.querier(ctx).QueryContext(ctx, query, enrollmentID, workspaceID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			send := &models.Send{}
+			if err := rows.Scan(&send.ID, &send.EnrollmentID, &send.SequenceID, &send.StepID, &send.StepOrder, &send.Status, &send.DueAt, &send.Attempts, &send.LastError, &send.SentAt); err != nil {
+				return err
+			}
+			sends = append(sends, send)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sends, nil
+}
@@ -9,8 +9,20 @@ import (
 
 type SequenceRepository interface {
 	Create(ctx context.Context, sequence *models.Sequence) (int64, error)
-	UpdateTracking(ctx context.Context, id int64, openTracking, clickTracking bool) error
+	UpdateTracking(ctx context.Context, id int64, openTracking, clickTracking bool, version int64) (int64, error)
+	SetActive(ctx context.Context, id int64, active bool, version int64) (int64, error)
 	Get(ctx context.Context, id int64) (*models.Sequence, error)
+	GetByExternalKey(ctx context.Context, key string) (*models.Sequence, error)
+	ListWithExternalKey(ctx context.Context) ([]*models.Sequence, error)
+	Update(ctx context.Context, sequence *models.Sequence) error
+	Delete(ctx context.Context, id int64, version int64) error
+	List(ctx context.Context, opts models.ListOptions) ([]*models.Sequence, string, error)
+}
+
+var sequenceSortFields = map[string]sortField[*models.Sequence]{
+	"name":      {column: "name", value: func(s *models.Sequence) string { return s.Name }},
+	"createdAt": {column: "created_at", value: func(s *models.Sequence) string { return formatCursorTime(s.CreatedAt) }},
+	"updatedAt": {column: "updated_at", value: func(s *models.Sequence) string { return formatCursorTime(s.UpdatedAt) }},
 }
 
 type sequenceRepo struct {
@@ -22,95 +34,139 @@ func NewSequenceRepository(db *DB) SequenceRepository {
 }
 
 func (r *sequenceRepo) Create(ctx context.Context, sequence *models.Sequence) (int64, error) {
-	tx, err := r.db.Conn.BeginTx(ctx, nil)
-	if err != nil {
-		return 0, err
-	}
-	defer tx.Rollback()
+	var id int64
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		q := r.db.querier(ctx)
 
-	query := `
-        INSERT INTO sequences (name, open_tracking_enabled, click_tracking_enabled, created_at, updated_at)
-        VALUES ($1, $2, $3, NOW(), NOW()) RETURNING id
+		query := `
+        INSERT INTO sequences (workspace_id, name, external_key, open_tracking_enabled, click_tracking_enabled, team_id, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW()) RETURNING id
     `
-	var id int64
-	err = tx.QueryRowContext(ctx, query, sequence.Name, sequence.OpenTrackingEnabled, sequence.ClickTrackingEnabled).Scan(&id)
-	if err != nil {
-		return 0, err
-	}
+		err := q.QueryRowContext(ctx, query, workspaceID, sequence.Name, nullString(sequence.ExternalKey), sequence.OpenTrackingEnabled, sequence.ClickTrackingEnabled, sequence.TeamID).Scan(&id)
+		if err != nil {
+			return err
+		}
 
-	// Insert steps if any exist
-	if len(sequence.Steps) > 0 {
-		stepsQuery := `
-            INSERT INTO steps (sequence_id, subject, content, step_order, wait_days, created_at, updated_at)
-            VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		// Insert steps if any exist
+		if len(sequence.Steps) > 0 {
+			stepsQuery := `
+            INSERT INTO steps (workspace_id, sequence_id, subject, content, step_order, wait_days, wait_unit, created_at, updated_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW()) RETURNING id
         `
-		for _, step := range sequence.Steps {
-			_, err = tx.ExecContext(ctx, stepsQuery, id, step.Subject, step.Content, step.StepOrder, step.WaitDays)
-			if err != nil {
-				return 0, err
+			for i := range sequence.Steps {
+				step := &sequence.Steps[i]
+				err = q.QueryRowContext(ctx, stepsQuery, workspaceID, id, step.Subject, step.Content, step.StepOrder, step.WaitDays, step.EffectiveWaitUnit()).Scan(&step.ID)
+				if err != nil {
+					return err
+				}
+				step.SequenceID = id
 			}
 		}
-	}
-
-	if err = tx.Commit(); err != nil {
+		return nil
+	})
+	if err != nil {
 		return 0, err
 	}
 
 	return id, nil
 }
 
-func (r *sequenceRepo) UpdateTracking(ctx context.Context, id int64, openTracking, clickTracking bool) error {
+// UpdateTracking sets the tracking flags and returns the new version. A
+// non-zero version makes the write conditional on the stored version.
+func (r *sequenceRepo) UpdateTracking(ctx context.Context, id int64, openTracking, clickTracking bool, version int64) (int64, error) {
 	query := `
         UPDATE sequences
-        SET open_tracking_enabled = $1, click_tracking_enabled = $2, updated_at = NOW()
-        WHERE id = $3
+        SET open_tracking_enabled = $1, click_tracking_enabled = $2, version = version + 1, updated_at = NOW()
+        WHERE id = $3 AND workspace_id = $4 AND ($5 = 0 OR version = $5)
+        RETURNING version
     `
-	result, err := r.db.Conn.ExecContext(ctx, query, openTracking, clickTracking, id)
-	if err != nil {
+	var newVersion int64
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		err := r.db.querier(ctx).QueryRowContext(ctx, query, openTracking, clickTracking, id, workspaceID, version).Scan(&newVersion)
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.db.notFoundOrStale(ctx, "sequences", id)
+		}
 		return err
-	}
-	rowsAffected, err := result.RowsAffected()
+	})
 	if err != nil {
-		return err
+		return 0, err
 	}
-	if rowsAffected == 0 {
-		return errors.New("no rows updated")
+	return newVersion, nil
+}
+
+// SetActive activates or deactivates the sequence and returns the new version.
+// A non-zero version makes the write conditional on the stored version.
+func (r *sequenceRepo) SetActive(ctx context.Context, id int64, active bool, version int64) (int64, error) {
+	query := `
+        UPDATE sequences
+        SET active = $1, version = version + 1, updated_at = NOW()
+        WHERE id = $2 AND workspace_id = $3 AND ($4 = 0 OR version = $4)
+        RETURNING version
+    `
+	var newVersion int64
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		err := r.db.querier(ctx).QueryRowContext(ctx, query, active, id, workspaceID, version).Scan(&newVersion)
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.db.notFoundOrStale(ctx, "sequences", id)
+		}
+		return err
+	})
+	if err != nil {
+		return 0, err
 	}
-	return nil
+	return newVersion, nil
 }
 
 func (r *sequenceRepo) Get(ctx context.Context, id int64) (*models.Sequence, error) {
 	query := `
         SELECT 
-            s.id, s.name, s.open_tracking_enabled, s.click_tracking_enabled, s.created_at, s.updated_at, s.deleted_at,
-            st.id, st.sequence_id, st.subject, st.content, st.step_order, st.wait_days, st.created_at, st.updated_at, st.deleted_at
+            s.id, s.name, s.external_key, s.open_tracking_enabled, s.click_tracking_enabled, s.team_id, s.active, s.version, s.created_at, s.updated_at, s.deleted_at,
+            st.id, st.sequence_id, st.subject, st.content, st.step_order, st.wait_days, st.wait_unit, st.version, st.created_at, st.updated_at, st.deleted_at
         FROM sequences s
-        LEFT JOIN steps st ON s.id = st.sequence_id
-        WHERE s.id = $1
+        LEFT JOIN steps st ON s.id = st.sequence_id AND st.workspace_id = s.workspace_id
+        WHERE s.id = $1 AND s.workspace_id = $2
         ORDER BY st.step_order ASC
     `
+	var sequence *models.Sequence
+	err := r.db.readInWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		var err error
+		sequence, err = r.get(ctx, query, id, workspaceID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sequence, nil
+}
+
+func (r *sequenceRepo) get(ctx context.Context, query string, id, workspaceID int64) (*models.Sequence, error) {
 	sequence := &models.Sequence{}
-	rows, err := r.db.Conn.QueryContext(ctx, query, id)
+	rows, err := r.db.querier(ctx).QueryContext(ctx, query, id, workspaceID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	var steps []models.Step
-	first := true
+	var externalKey sql.NullString
 
 	for rows.Next() {
 		var step models.Step
 		var stepID, sequenceID sql.NullInt64
-		var subject, content sql.NullString
+		var subject, content, waitUnit sql.NullString
 		var stepOrder, waitDays sql.NullInt32
+		var stepVersion sql.NullInt64
 		var stepCreatedAt, stepUpdatedAt, stepDeletedAt sql.NullTime
 
 		err := rows.Scan(
 			&sequence.ID,
 			&sequence.Name,
+			&externalKey,
 			&sequence.OpenTrackingEnabled,
 			&sequence.ClickTrackingEnabled,
+			&sequence.TeamID,
+			&sequence.Active,
+			&sequence.Version,
 			&sequence.CreatedAt,
 			&sequence.UpdatedAt,
 			&sequence.DeletedAt,
@@ -120,6 +176,8 @@ func (r *sequenceRepo) Get(ctx context.Context, id int64) (*models.Sequence, err
 			&content,
 			&stepOrder,
 			&waitDays,
+			&waitUnit,
+			&stepVersion,
 			&stepCreatedAt,
 			&stepUpdatedAt,
 			&stepDeletedAt,
@@ -128,11 +186,6 @@ func (r *sequenceRepo) Get(ctx context.Context, id int64) (*models.Sequence, err
 			return nil, err
 		}
 
-		// Only set sequence fields on first row
-		if first {
-			first = false
-		}
-
 		// If we have a valid step ID, add the step
 		if stepID.Valid {
 			step.ID = stepID.Int64
@@ -141,6 +194,8 @@ func (r *sequenceRepo) Get(ctx context.Context, id int64) (*models.Sequence, err
 			step.Content = content.String
 			step.StepOrder = int(stepOrder.Int32)
 			step.WaitDays = int(waitDays.Int32)
+			step.WaitUnit = waitUnit.String
+			step.Version = stepVersion.Int64
 			step.CreatedAt = stepCreatedAt.Time
 			step.UpdatedAt = stepUpdatedAt.Time
 			if stepDeletedAt.Valid {
@@ -153,7 +208,155 @@ func (r *sequenceRepo) Get(ctx context.Context, id int64) (*models.Sequence, err
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
+	if sequence.ID == 0 {
+		return nil, sql.ErrNoRows
+	}
 
+	sequence.ExternalKey = externalKey.String
 	sequence.Steps = steps
 	return sequence, nil
 }
+
+func (r *sequenceRepo) GetByExternalKey(ctx context.Context, key string) (*models.Sequence, error) {
+	var sequence *models.Sequence
+	err := r.db.readInWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		var id int64
+		err := r.db.querier(ctx).QueryRowContext(ctx, `SELECT id FROM sequences WHERE external_key = $1 AND workspace_id = $2`, key, workspaceID).Scan(&id)
+		if err != nil {
+			return err
+		}
+		sequence, err = r.Get(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sequence, nil
+}
+
+func (r *sequenceRepo) ListWithExternalKey(ctx context.Context) ([]*models.Sequence, error) {
+	query := `
+        SELECT id, name, external_key, open_tracking_enabled, click_tracking_enabled, team_id, active, version, created_at, updated_at, deleted_at
+        FROM sequences
+        WHERE external_key IS NOT NULL AND workspace_id = $1
+        ORDER BY external_key
+    `
+	var sequences []*models.Sequence
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		rows, err := r.db.querier(ctx).QueryContext(ctx, query, workspaceID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			sequence := &models.Sequence{}
+			if err := rows.Scan(&sequence.ID, &sequence.Name, &sequence.ExternalKey, &sequence.OpenTrackingEnabled, &sequence.ClickTrackingEnabled, &sequence.TeamID, &sequence.Active, &sequence.Version, &sequence.CreatedAt, &sequence.UpdatedAt, &sequence.DeletedAt); err != nil {
+				return err
+			}
+			sequences = append(sequences, sequence)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sequences, nil
+}
+
+// Update saves the sequence without its steps. A non-zero sequence.Version
+// makes the write conditional on the stored version; on success
+// sequence.Version holds the new version.
+func (r *sequenceRepo) Update(ctx context.Context, sequence *models.Sequence) error {
+	query := `
+        UPDATE sequences
+        SET name = $1, external_key = $2, open_tracking_enabled = $3, click_tracking_enabled = $4, version = version + 1, updated_at = NOW()
+        WHERE id = $5 AND workspace_id = $6 AND ($7 = 0 OR version = $7)
+        RETURNING version
+    `
+	return r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		var version int64
+		err := r.db.querier(ctx).QueryRowContext(ctx, query, sequence.Name, nullString(sequence.ExternalKey), sequence.OpenTrackingEnabled, sequence.ClickTrackingEnabled, sequence.ID, workspaceID, sequence.Version).Scan(&version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.db.notFoundOrStale(ctx, "sequences", sequence.ID)
+		}
+		if err != nil {
+			return err
+		}
+		sequence.Version = version
+		return nil
+	})
+}
+
+// Delete removes the sequence and its steps. A non-zero version makes the
+// delete conditional on the stored version.
+func (r *sequenceRepo) Delete(ctx context.Context, id int64, version int64) error {
+	query := `
+        DELETE FROM sequences
+        WHERE id = $1 AND workspace_id = $2 AND ($3 = 0 OR version = $3)
+    `
+	return r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		result, err := r.db.querier(ctx).ExecContext(ctx, query, id, workspaceID, version)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return r.db.notFoundOrStale(ctx, "sequences", id)
+		}
+		return nil
+	})
+}
+
+// List returns one page of sequences, without their steps, and the cursor for the next page.
+func (r *sequenceRepo) List(ctx context.Context, opts models.ListOptions) ([]*models.Sequence, string, error) {
+	q := &pageQuery[*models.Sequence]{
+		selectFrom:  `SELECT id, name, external_key, open_tracking_enabled, click_tracking_enabled, team_id, active, version, created_at, updated_at, deleted_at FROM sequences`,
+		idColumn:    "id",
+		sortFields:  sequenceSortFields,
+		defaultSort: "createdAt",
+	}
+	if opts.Filters.NameContains != "" {
+		q.filter("name ILIKE ?", containsPattern(opts.Filters.NameContains))
+	}
+	q.filterCreatedAt("created_at", opts.Filters)
+
+	sequences := []*models.Sequence{}
+	err := r.db.readInWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		sequences = []*models.Sequence{}
+		query, args, err := q.withFilter("workspace_id = ?", workspaceID).build(opts)
+		if err != nil {
+			return err
+		}
+		rows, err := r.db.querier(ctx).QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			sequence := &models.Sequence{}
+			var externalKey sql.NullString
+			if err := rows.Scan(&sequence.ID, &sequence.Name, &externalKey, &sequence.OpenTrackingEnabled, &sequence.ClickTrackingEnabled, &sequence.TeamID, &sequence.Active, &sequence.Version, &sequence.CreatedAt, &sequence.UpdatedAt, &sequence.DeletedAt); err != nil {
+				return err
+			}
+			sequence.ExternalKey = externalKey.String
+			sequences = append(sequences, sequence)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	sequences, next := q.page(sequences, opts, func(s *models.Sequence) int64 { return s.ID })
+	return sequences, next, nil
+}
+
+// nullString maps an empty string to SQL NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
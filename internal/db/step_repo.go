@@ -2,15 +2,25 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"sf_test/internal/models"
+	"strconv"
 )
 
 type StepRepository interface {
 	Create(ctx context.Context, step *models.Step) (int64, error)
+	Get(ctx context.Context, id int64) (*models.Step, error)
 	Update(ctx context.Context, step *models.Step) error
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64, version int64) error
 	ListBySequenceID(ctx context.Context, sequenceID int64) ([]*models.Step, error)
+	List(ctx context.Context, sequenceID int64, opts models.ListOptions) ([]*models.Step, string, error)
+}
+
+var stepSortFields = map[string]sortField[*models.Step]{
+	"stepOrder": {column: "step_order", value: func(s *models.Step) string { return strconv.Itoa(s.StepOrder) }},
+	"createdAt": {column: "created_at", value: func(s *models.Step) string { return formatCursorTime(s.CreatedAt) }},
+	"updatedAt": {column: "updated_at", value: func(s *models.Step) string { return formatCursorTime(s.UpdatedAt) }},
 }
 
 type stepRepo struct {
@@ -23,76 +33,161 @@ func NewStepRepository(db *DB) StepRepository {
 
 func (r *stepRepo) Create(ctx context.Context, step *models.Step) (int64, error) {
 	query := `
-        INSERT INTO steps (sequence_id, subject, content, step_order, wait_days, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) RETURNING id
+        INSERT INTO steps (workspace_id, sequence_id, subject, content, step_order, wait_days, wait_unit, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW()) RETURNING id
     `
 	var id int64
-	err := r.db.Conn.QueryRowContext(ctx, query, step.SequenceID, step.Subject, step.Content, step.StepOrder, step.WaitDays).Scan(&id)
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		err := r.db.querier(ctx).QueryRowContext(ctx, query, workspaceID, step.SequenceID, step.Subject, step.Content, step.StepOrder, step.WaitDays, step.EffectiveWaitUnit()).Scan(&id)
+		if err != nil {
+			return err
+		}
+		return r.touchSequence(ctx, workspaceID, step.SequenceID)
+	})
 	if err != nil {
 		return 0, err
 	}
 	return id, nil
 }
 
-func (r *stepRepo) Update(ctx context.Context, step *models.Step) error {
+func (r *stepRepo) Get(ctx context.Context, id int64) (*models.Step, error) {
 	query := `
-        UPDATE steps
-        SET subject = $1, content = $2, updated_at = NOW()
-        WHERE id = $3
+        SELECT id, sequence_id, subject, content, step_order, wait_days, wait_unit, version, created_at, updated_at, deleted_at
+        FROM steps
+        WHERE id = $1 AND workspace_id = $2
     `
-	result, err := r.db.Conn.ExecContext(ctx, query, step.Subject, step.Content, step.ID)
+	step := &models.Step{}
+	err := r.db.readInWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		return r.db.querier(ctx).QueryRowContext(ctx, query, id, workspaceID).Scan(&step.ID, &step.SequenceID, &step.Subject, &step.Content, &step.StepOrder, &step.WaitDays, &step.WaitUnit, &step.Version, &step.CreatedAt, &step.UpdatedAt, &step.DeletedAt)
+	})
 	if err != nil {
-		return err
-	}
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if rowsAffected == 0 {
-		return errors.New("no rows updated")
+		return nil, err
 	}
-	return nil
+	return step, nil
+}
+
+// Update saves the step. A non-zero step.Version makes the write conditional
+// on the stored version; on success step.Version holds the new version.
+func (r *stepRepo) Update(ctx context.Context, step *models.Step) error {
+	query := `
+        UPDATE steps
+        SET subject = $1, content = $2, step_order = $3, wait_days = $4, wait_unit = $5, version = version + 1, updated_at = NOW()
+        WHERE id = $6 AND workspace_id = $7 AND ($8 = 0 OR version = $8)
+        RETURNING sequence_id, version
+    `
+	return r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		var sequenceID, version int64
+		err := r.db.querier(ctx).QueryRowContext(ctx, query, step.Subject, step.Content, step.StepOrder, step.WaitDays, step.EffectiveWaitUnit(), step.ID, workspaceID, step.Version).Scan(&sequenceID, &version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.db.notFoundOrStale(ctx, "steps", step.ID)
+		}
+		if err != nil {
+			return err
+		}
+		step.Version = version
+		return r.touchSequence(ctx, workspaceID, sequenceID)
+	})
 }
 
-func (r *stepRepo) Delete(ctx context.Context, id int64) error {
+// Delete removes the step. A non-zero version makes the delete conditional
+// on the stored version.
+func (r *stepRepo) Delete(ctx context.Context, id int64, version int64) error {
 	query := `
         DELETE FROM steps
-        WHERE id = $1
+        WHERE id = $1 AND workspace_id = $2 AND ($3 = 0 OR version = $3)
+        RETURNING sequence_id
     `
-	result, err := r.db.Conn.ExecContext(ctx, query, id)
-	if err != nil {
-		return err
-	}
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if rowsAffected == 0 {
-		return errors.New("no rows deleted")
-	}
-	return nil
+	return r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		var sequenceID int64
+		err := r.db.querier(ctx).QueryRowContext(ctx, query, id, workspaceID, version).Scan(&sequenceID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.db.notFoundOrStale(ctx, "steps", id)
+		}
+		if err != nil {
+			return err
+		}
+		return r.touchSequence(ctx, workspaceID, sequenceID)
+	})
+}
+
+// touchSequence bumps the version of the sequence owning a changed step, as
+// the sequence representation includes its steps.
+func (r *stepRepo) touchSequence(ctx context.Context, workspaceID, sequenceID int64) error {
+	_, err := r.db.querier(ctx).ExecContext(ctx, `UPDATE sequences SET version = version + 1 WHERE id = $1 AND workspace_id = $2`, sequenceID, workspaceID)
+	return err
 }
 
 func (r *stepRepo) ListBySequenceID(ctx context.Context, sequenceID int64) ([]*models.Step, error) {
 	query := `
-        SELECT id, sequence_id, subject, content, step_order, wait_days, created_at, updated_at, deleted_at
+        SELECT id, sequence_id, subject, content, step_order, wait_days, wait_unit, version, created_at, updated_at, deleted_at
         FROM steps
-        WHERE sequence_id = $1
+        WHERE sequence_id = $1 AND workspace_id = $2
         ORDER BY step_order
     `
-	rows, err := r.db.Conn.QueryContext(ctx, query, sequenceID)
+	var steps []*models.Step
+	err := r.db.readInWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		steps = nil
+		rows, err := r.db.querier(ctx).QueryContext(ctx, query, sequenceID, workspaceID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			step := &models.Step{}
+			if err := rows.Scan(&step.ID, &step.SequenceID, &step.Subject, &step.Content, &step.StepOrder, &step.WaitDays, &step.WaitUnit, &step.Version, &step.CreatedAt, &step.UpdatedAt, &step.DeletedAt); err != nil {
+				return err
+			}
+			steps = append(steps, step)
+		}
+		return rows.Err()
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return steps, nil
+}
 
-	var steps []*models.Step
-	for rows.Next() {
-		step := &models.Step{}
-		if err := rows.Scan(&step.ID, &step.SequenceID, &step.Subject, &step.Content, &step.StepOrder, &step.WaitDays, &step.CreatedAt, &step.UpdatedAt, &step.DeletedAt); err != nil {
-			return nil, err
+// List returns one page of the sequence's steps and the cursor for the next page.
+func (r *stepRepo) List(ctx context.Context, sequenceID int64, opts models.ListOptions) ([]*models.Step, string, error) {
+	q := &pageQuery[*models.Step]{
+		selectFrom:  `SELECT id, sequence_id, subject, content, step_order, wait_days, wait_unit, version, created_at, updated_at, deleted_at FROM steps`,
+		idColumn:    "id",
+		sortFields:  stepSortFields,
+		defaultSort: "stepOrder",
+	}
+	q.filter("sequence_id = ?", sequenceID)
+	if opts.Filters.NameContains != "" {
+		q.filter("subject ILIKE ?", containsPattern(opts.Filters.NameContains))
+	}
+	q.filterCreatedAt("created_at", opts.Filters)
+
+	steps := []*models.Step{}
+	err := r.db.readInWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		steps = []*models.Step{}
+		query, args, err := q.withFilter("workspace_id = ?", workspaceID).build(opts)
+		if err != nil {
+			return err
+		}
+		rows, err := r.db.querier(ctx).QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
 		}
-		steps = append(steps, step)
+		defer rows.Close()
+
+		for rows.Next() {
+			step := &models.Step{}
+			if err := rows.Scan(&step.ID, &step.SequenceID, &step.Subject, &step.Content, &step.StepOrder, &step.WaitDays, &step.WaitUnit, &step.Version, &step.CreatedAt, &step.UpdatedAt, &step.DeletedAt); err != nil {
+				return err
+			}
+			steps = append(steps, step)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, "", err
 	}
-	return steps, nil
+
+	steps, next := q.page(steps, opts, func(s *models.Step) int64 { return s.ID })
+	return steps, next, nil
 }
@@ -0,0 +1,183 @@
+package db
+
+import (
+	"context"
+	"sf_test/internal/models"
+)
+
+type TeamRepository interface {
+	Create(ctx context.Context, team *models.Team) (int64, error)
+	Get(ctx context.Context, id int64) (*models.Team, error)
+	List(ctx context.Context) ([]*models.Team, error)
+	SetMember(ctx context.Context, member *models.TeamMember) error
+	RemoveMember(ctx context.Context, teamID, userID int64) error
+	ListMembers(ctx context.Context, teamID int64) ([]*models.TeamMember, error)
+	// MembershipsOf returns the teams the user signing in as subject belongs
+	// to, without the user.
+	MembershipsOf(ctx context.Context, subject string) ([]*models.TeamMember, error)
+}
+
+type teamRepo struct {
+	db *DB
+}
+
+func NewTeamRepository(db *DB) TeamRepository {
+	return &teamRepo{db: db}
+}
+
+const teamColumns = `id, name, created_at`
+
+func scanTeam(row rowScanner) (*models.Team, error) {
+	team := &models.Team{}
+	if err := row.Scan(&team.ID, &team.Name, &team.CreatedAt); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+func (r *teamRepo) Create(ctx context.Context, team *models.Team) (int64, error) {
+	query := `
+        INSERT INTO teams (workspace_id, name, created_at)
+        VALUES ($1, $2, NOW()) RETURNING id, created_at
+    `
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		return r.db.querier(ctx).QueryRowContext(ctx, query, workspaceID, team.Name).Scan(&team.ID, &team.CreatedAt)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return team.ID, nil
+}
+
+func (r *teamRepo) Get(ctx context.Context, id int64) (*models.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams WHERE id = $1 AND workspace_id = $2`
+	var team *models.Team
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		var err error
+		team, err = scanTeam(r.db.querier(ctx).QueryRowContext(ctx, query, id, workspaceID))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+func (r *teamRepo) List(ctx context.Context) ([]*models.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams WHERE workspace_id = $1 ORDER BY name, id`
+	teams := []*models.Team{}
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		rows, err := r.db.querier(ctx).QueryContext(ctx, query, workspaceID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			team, err := scanTeam(rows)
+			if err != nil {
+				return err
+			}
+			teams = append(teams, team)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+// SetMember adds the user to the team, or changes their role if they are
+// already a member.
+func (r *teamRepo) SetMember(ctx context.Context, member *models.TeamMember) error {
+	query := `
+        INSERT INTO team_members (workspace_id, team_id, user_id, role)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (team_id, user_id) DO UPDATE SET role = EXCLUDED.role
+    `
+	return r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		_, err := r.db.querier(ctx).ExecContext(ctx, query, workspaceID, member.TeamID, member.UserID, member.Role)
+		return err
+	})
+}
+
+func (r *teamRepo) RemoveMember(ctx context.Context, teamID, userID int64) error {
+	query := `DELETE FROM team_members WHERE team_id = $1 AND user_id = $2 AND workspace_id = $3`
+	return r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		result, err := r.db.querier(ctx).ExecContext(ctx, query, teamID, userID, workspaceID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *teamRepo) ListMembers(ctx context.Context, teamID int64) ([]*models.TeamMember, error) {
+	query := `
+        SELECT m.team_id, m.user_id, m.role, u.id, u.subject, u.email, u.name, u.created_at
+        FROM team_members m
+        JOIN users u ON u.id = m.user_id AND u.workspace_id = m.workspace_id
+        WHERE m.team_id = $1 AND m.workspace_id = $2
+        ORDER BY u.subject
+    `
+	members := []*models.TeamMember{}
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		rows, err := r.db.querier(ctx).QueryContext(ctx, query, teamID, workspaceID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			member := &models.TeamMember{User: &models.User{}}
+			user := member.User
+			if err := rows.Scan(&member.TeamID, &member.UserID, &member.Role, &user.ID, &user.Subject, &user.Email, &user.Name, &user.CreatedAt); err != nil {
+				return err
+			}
+			members = append(members, member)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *teamRepo) MembershipsOf(ctx context.Context, subject string) ([]*models.TeamMember, error) {
+	query := `
+        SELECT m.team_id, m.user_id, m.role
+        FROM team_members m
+        JOIN users u ON u.id = m.user_id AND u.workspace_id = m.workspace_id
+        WHERE u.subject = $1 AND m.workspace_id = $2
+    `
+	members := []*models.TeamMember{}
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		rows, err := r.db.querier(ctx).QueryContext(ctx, query, subject, workspaceID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			member := &models.TeamMember{}
+			if err := rows.Scan(&member.TeamID, &member.UserID, &member.Role); err != nil {
+				return err
+			}
+			members = append(members, member)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
@@ -0,0 +1,168 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"sf_test/internal/tenant"
+)
+
+const (
+	// maxTxAttempts is how often a serializable transaction is tried before
+	// its serialization failure is returned.
+	maxTxAttempts = 5
+	txRetryDelay  = 10 * time.Millisecond
+)
+
+// ErrNoWorkspace is returned when workspace data is accessed with a context
+// that is not bound to a workspace.
+var ErrNoWorkspace = errors.New("no workspace bound to context")
+
+// Transactor runs a function inside a database transaction.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// querier is the subset of methods shared by *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txKey struct{}
+
+type serializableKey struct{}
+
+// Serializable marks ctx so that the transaction WithinTx starts with it runs
+// at the serializable isolation level. Such a transaction is run again when
+// it fails with a serialization failure or deadlock, so its function must be
+// safe to repeat: it should not keep state from an earlier attempt. Inside a
+// running transaction the mark has no effect.
+func Serializable(ctx context.Context) context.Context {
+	return context.WithValue(ctx, serializableKey{}, true)
+}
+
+// txState is the transaction bound to a context and the workspace its
+// row-level security policies currently admit.
+type txState struct {
+	tx          *sql.Tx
+	workspaceID int64
+	// broken is set when the workspace could not be restored after a nested
+	// call, and keeps the transaction from committing.
+	broken error
+}
+
+// WithinTx runs fn in a transaction. Repository calls made with the context
+// passed to fn join the transaction, and nested calls reuse the outer one.
+// The transaction is committed if fn returns nil and rolled back otherwise.
+// When ctx is bound to a workspace, row-level security restricts the
+// transaction to that workspace's rows. A nested call bound to another
+// workspace switches to it until it returns; if switching back fails, the
+// outer transaction is rolled back. Transactions started with a
+// Serializable context are retried on serialization failures.
+func (db *DB) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	workspaceID, _ := tenant.WorkspaceFrom(ctx)
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		if state.workspaceID == workspaceID {
+			return fn(ctx)
+		}
+		inner := &txState{tx: state.tx, workspaceID: workspaceID}
+		if err := inner.bindWorkspace(ctx); err != nil {
+			return err
+		}
+		err := fn(context.WithValue(ctx, txKey{}, inner))
+		if rebindErr := state.bindWorkspace(ctx); rebindErr != nil {
+			// The rest of the outer transaction would run in the inner
+			// workspace, so it must not commit.
+			state.broken = fmt.Errorf("restoring workspace %d: %w", state.workspaceID, rebindErr)
+			if err == nil {
+				err = state.broken
+			}
+		}
+		return err
+	}
+
+	serializable, _ := ctx.Value(serializableKey{}).(bool)
+	if !serializable {
+		return db.runTx(ctx, db.Conn, nil, workspaceID, fn)
+	}
+
+	opts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	for attempt := 1; ; attempt++ {
+		err := db.runTx(ctx, db.Conn, opts, workspaceID, fn)
+		if attempt == maxTxAttempts || !IsSerializationFailure(err) {
+			return err
+		}
+		// Back off with jitter so the conflicting transactions spread out.
+		delay := txRetryDelay<<(attempt-1) + time.Duration(rand.Int63n(int64(txRetryDelay)))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// runTx runs fn in one new transaction on conn.
+func (db *DB) runTx(ctx context.Context, conn *sql.DB, opts *sql.TxOptions, workspaceID int64, fn func(ctx context.Context) error) error {
+	tx, err := conn.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	state := &txState{tx: tx, workspaceID: workspaceID}
+	if workspaceID != 0 {
+		if err := state.bindWorkspace(ctx); err != nil {
+			return err
+		}
+	}
+	if err := fn(context.WithValue(ctx, txKey{}, state)); err != nil {
+		return err
+	}
+	if state.broken != nil {
+		return state.broken
+	}
+	return tx.Commit()
+}
+
+// bindWorkspace sets the workspace read by the row-level security policies
+// for the rest of the transaction.
+func (s *txState) bindWorkspace(ctx context.Context) error {
+	setting := ""
+	if s.workspaceID != 0 {
+		setting = strconv.FormatInt(s.workspaceID, 10)
+	}
+	_, err := s.tx.ExecContext(ctx, `SELECT set_config('app.workspace_id', $1, true)`, setting)
+	return err
+}
+
+// inWorkspace runs fn in a transaction restricted to the workspace bound to
+// ctx, bounded by the query timeout. Queries made by fn must still filter on
+// the workspace ID they are passed; row-level security only catches the ones
+// that do not.
+func (db *DB) inWorkspace(ctx context.Context, fn func(ctx context.Context, workspaceID int64) error) error {
+	workspaceID, ok := tenant.WorkspaceFrom(ctx)
+	if !ok {
+		return ErrNoWorkspace
+	}
+	ctx, cancel := db.withQueryTimeout(ctx)
+	defer cancel()
+	return db.WithinTx(ctx, func(ctx context.Context) error {
+		return fn(ctx, workspaceID)
+	})
+}
+
+// querier returns the transaction bound to ctx, or the connection pool.
+func (db *DB) querier(ctx context.Context) querier {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx
+	}
+	return db.Conn
+}
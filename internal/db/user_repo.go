@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"sf_test/internal/models"
+)
+
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) (int64, error)
+	Get(ctx context.Context, id int64) (*models.User, error)
+	GetBySubject(ctx context.Context, subject string) (*models.User, error)
+	List(ctx context.Context) ([]*models.User, error)
+}
+
+type userRepo struct {
+	db *DB
+}
+
+func NewUserRepository(db *DB) UserRepository {
+	return &userRepo{db: db}
+}
+
+const userColumns = `id, subject, email, name, created_at`
+
+func scanUser(row rowScanner) (*models.User, error) {
+	user := &models.User{}
+	if err := row.Scan(&user.ID, &user.Subject, &user.Email, &user.Name, &user.CreatedAt); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *userRepo) Create(ctx context.Context, user *models.User) (int64, error) {
+	query := `
+        INSERT INTO users (workspace_id, subject, email, name, created_at)
+        VALUES ($1, $2, $3, $4, NOW()) RETURNING id, created_at
+    `
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		return r.db.querier(ctx).QueryRowContext(ctx, query, workspaceID, user.Subject, user.Email, user.Name).Scan(&user.ID, &user.CreatedAt)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return user.ID, nil
+}
+
+func (r *userRepo) Get(ctx context.Context, id int64) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND workspace_id = $2`
+	var user *models.User
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		var err error
+		user, err = scanUser(r.db.querier(ctx).QueryRowContext(ctx, query, id, workspaceID))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *userRepo) GetBySubject(ctx context.Context, subject string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE subject = $1 AND workspace_id = $2`
+	var user *models.User
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		var err error
+		user, err = scanUser(r.db.querier(ctx).QueryRowContext(ctx, query, subject, workspaceID))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *userRepo) List(ctx context.Context) ([]*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE workspace_id = $1 ORDER BY subject`
+	users := []*models.User{}
+	err := r.db.inWorkspace(ctx, func(ctx context.Context, workspaceID int64) error {
+		rows, err := r.db.querier(ctx).QueryContext(ctx, query, workspaceID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			user, err := scanUser(rows)
+			if err != nil {
+				return err
+			}
+			users = append(users, user)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"sf_test/internal/models"
+)
+
+// WorkspaceRepository stores the workspaces themselves, which are not
+// scoped to a workspace.
+type WorkspaceRepository interface {
+	Create(ctx context.Context, workspace *models.Workspace) (int64, error)
+	Get(ctx context.Context, id int64) (*models.Workspace, error)
+	GetBySlug(ctx context.Context, slug string) (*models.Workspace, error)
+	List(ctx context.Context) ([]*models.Workspace, error)
+}
+
+type workspaceRepo struct {
+	db *DB
+}
+
+func NewWorkspaceRepository(db *DB) WorkspaceRepository {
+	return &workspaceRepo{db: db}
+}
+
+const workspaceColumns = `id, slug, name, created_at`
+
+func scanWorkspace(row rowScanner) (*models.Workspace, error) {
+	workspace := &models.Workspace{}
+	if err := row.Scan(&workspace.ID, &workspace.Slug, &workspace.Name, &workspace.CreatedAt); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+func (r *workspaceRepo) Create(ctx context.Context, workspace *models.Workspace) (int64, error) {
+	ctx, cancel := r.db.withQueryTimeout(ctx)
+	defer cancel()
+	query := `
+        INSERT INTO workspaces (slug, name, created_at)
+        VALUES ($1, $2, NOW()) RETURNING id, created_at
+    `
+	err := r.db.querier(ctx).QueryRowContext(ctx, query, workspace.Slug, workspace.Name).Scan(&workspace.ID, &workspace.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return workspace.ID, nil
+}
+
+func (r *workspaceRepo) Get(ctx context.Context, id int64) (*models.Workspace, error) {
+	ctx, cancel := r.db.withQueryTimeout(ctx)
+	defer cancel()
+	query := `SELECT ` + workspaceColumns + ` FROM workspaces WHERE id = $1`
+	return scanWorkspace(r.db.querier(ctx).QueryRowContext(ctx, query, id))
+}
+
+func (r *workspaceRepo) GetBySlug(ctx context.Context, slug string) (*models.Workspace, error) {
+	ctx, cancel := r.db.withQueryTimeout(ctx)
+	defer cancel()
+	query := `SELECT ` + workspaceColumns + ` FROM workspaces WHERE slug = $1`
+	return scanWorkspace(r.db.querier(ctx).QueryRowContext(ctx, query, slug))
+}
+
+func (r *workspaceRepo) List(ctx context.Context) ([]*models.Workspace, error) {
+	ctx, cancel := r.db.withQueryTimeout(ctx)
+	defer cancel()
+	query := `SELECT ` + workspaceColumns + ` FROM workspaces ORDER BY slug`
+	rows, err := r.db.querier(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workspaces := []*models.Workspace{}
+	for rows.Next() {
+		workspace, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	return workspaces, rows.Err()
+}
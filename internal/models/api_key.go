@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// APIKey is a credential for machine clients. Only the hash of the key is
+// stored; Prefix is kept in clear so the key can be recognised in listings.
+type APIKey struct {
+	ID          int64    `json:"id"`
+	WorkspaceID int64    `json:"workspaceId"`
+	Name        string   `json:"name" validate:"required,max=255"`
+	Prefix      string   `json:"prefix"`
+	Hash        string   `json:"-"`
+	Scopes      []string `json:"scopes" validate:"required,min=1"`
+	// UserID is the user the key acts as, if it was created by one.
+	UserID     string     `json:"userId,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// Validate validates the APIKey struct.
+func (k *APIKey) Validate() error {
+	validate := validator.New()
+	return validate.Struct(k)
+}
+
+// CreatedAPIKey is returned once when a key is created. Key is the only time
+// the full secret is available.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
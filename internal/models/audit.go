@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audited actions and resource types.
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+
+	AuditResourceSequence = "sequence"
+	AuditResourceStep     = "step"
+)
+
+// AuditEntry records a single change made through the API or the services.
+// Entries are never updated or deleted.
+type AuditEntry struct {
+	ID int64 `json:"id"`
+	// Actor is the subject of the principal that made the change, or
+	// "system" for changes made without one.
+	Actor        string `json:"actor"`
+	Action       string `json:"action"`
+	ResourceType string `json:"resourceType"`
+	ResourceID   int64  `json:"resourceId"`
+	// Changes maps each changed field to its value before and after the
+	// change. Creates have no before values and deletes no after values.
+	Changes   map[string]AuditChange `json:"changes"`
+	RequestID string                 `json:"requestId,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+// AuditChange is the value of a field before and after a change.
+type AuditChange struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import "encoding/json"
+
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpPatch  = "patch"
+	BatchOpDelete = "delete"
+
+	BatchResourceSequence = "sequence"
+	BatchResourceStep     = "step"
+
+	// MaxBatchOperations is the largest number of operations in one batch.
+	MaxBatchOperations = 100
+)
+
+// BatchOperation is a single change in a batch.
+type BatchOperation struct {
+	Op       string `json:"op"`
+	Resource string `json:"resource"`
+	// ID identifies the resource for update, patch and delete.
+	ID int64 `json:"id,omitempty"`
+	// Version makes the operation conditional, like an If-Match header.
+	Version int64 `json:"version,omitempty"`
+	// SequenceRef is the index of an earlier sequence create whose ID
+	// becomes the sequenceId of a created step.
+	SequenceRef *int `json:"sequenceRef,omitempty"`
+	// Body is the resource for create and update, or a merge patch for patch.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// BatchRequest is an ordered list of operations applied all or nothing.
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchResult is the outcome of one successful batch operation.
+type BatchResult struct {
+	Index    int    `json:"index"`
+	Op       string `json:"op"`
+	Resource string `json:"resource"`
+	ID       int64  `json:"id"`
+	Version  int64  `json:"version,omitempty"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// ContactFieldMaxLength is the longest first name, last name or company a
+// contact may have, in characters.
+const ContactFieldMaxLength = 255
+
+type Contact struct {
+	ID        int64  `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+	Company   string `json:"company,omitempty"`
+	// Country is an ISO 3166-1 alpha-2 code selecting the holidays the
+	// contact's sends skip.
+	Country   string     `json:"country,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// NormalizeEmail validates an email address and returns it trimmed and
+// lower-cased, without any display name.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", errors.New("email is required")
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return "", errors.New("invalid email address")
+	}
+	if len(address.Address) > 320 {
+		return "", errors.New("email address is too long")
+	}
+	return strings.ToLower(address.Address), nil
+}
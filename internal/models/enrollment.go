@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	EnrollmentStatusActive    = "active"
+	EnrollmentStatusCompleted = "completed"
+)
+
+// Enrollment places a contact into a sequence.
+type Enrollment struct {
+	ID         int64 `json:"id"`
+	SequenceID int64 `json:"sequenceId"`
+	ContactID  int64 `json:"contactId"`
+	// ContactCountry is the contact's country, read with the enrollment.
+	ContactCountry string    `json:"contactCountry,omitempty"`
+	Status         string    `json:"status"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
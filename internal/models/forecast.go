@@ -0,0 +1,56 @@
+package models
+
+import "github.com/go-playground/validator/v10"
+
+// ForecastDateLayout is the layout of the dates in forecasts.
+const ForecastDateLayout = "2006-01-02"
+
+// ForecastRequest describes the audience and mailboxes to forecast a
+// sequence's sends for.
+type ForecastRequest struct {
+	ContactCount int `json:"contactCount" validate:"required,min=1,max=10000000"`
+	// StartDate is the day the contacts are enrolled; today when empty.
+	StartDate string            `json:"startDate,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Mailboxes []ForecastMailbox `json:"mailboxes" validate:"required,min=1,dive"`
+	// Country is the contacts' country, whose holidays nothing is sent on
+	// besides the workspace's.
+	Country string `json:"country,omitempty" validate:"omitempty,iso3166_1_alpha2"`
+}
+
+// ForecastMailbox is a mailbox sends go out from and how many it may send a day.
+type ForecastMailbox struct {
+	Email      string `json:"email" validate:"required,email"`
+	DailyLimit int    `json:"dailyLimit" validate:"required,min=1"`
+}
+
+// Validate validates the ForecastRequest struct.
+func (r *ForecastRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+// Forecast is when a sequence's sends would go out for an audience.
+type Forecast struct {
+	SequenceID int64  `json:"sequenceId"`
+	StartDate  string `json:"startDate"`
+	// FinishDate is the day the last contact is sent the last step, or
+	// empty when the forecast ends first.
+	FinishDate    string `json:"finishDate,omitempty"`
+	DailyCapacity int    `json:"dailyCapacity"`
+	// Days lists the days anything is sent, in order.
+	Days     []ForecastDay `json:"days"`
+	Warnings []string      `json:"warnings"`
+}
+
+// ForecastDay is what is sent on one day.
+type ForecastDay struct {
+	Date  string              `json:"date"`
+	Total int                 `json:"total"`
+	Steps []ForecastStepSends `json:"steps"`
+}
+
+// ForecastStepSends is how many contacts are sent a step on a day.
+type ForecastStepSends struct {
+	StepID    int64 `json:"stepId"`
+	StepOrder int   `json:"stepOrder"`
+	Sends     int   `json:"sends"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Holiday is a day nothing is sent on. Holidays without a country apply to
+// every contact of the workspace, the others to the contacts in their country.
+type Holiday struct {
+	ID      int64  `json:"id"`
+	Country string `json:"country,omitempty"`
+	// Date is midnight UTC of the holiday.
+	Date      time.Time `json:"date"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// HolidayImportResult reports how many holidays an iCalendar import replaced
+// the country's holidays with.
+type HolidayImportResult struct {
+	Country  string `json:"country,omitempty"`
+	Imported int    `json:"imported"`
+}
+
+// NormalizeCountry validates an ISO 3166-1 alpha-2 country code and returns
+// it upper-cased. An empty code stays empty.
+func NormalizeCountry(country string) (string, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "" {
+		return "", nil
+	}
+	if len(country) != 2 || country[0] < 'A' || country[0] > 'Z' || country[1] < 'A' || country[1] > 'Z' {
+		return "", errors.New("country must be an ISO 3166-1 alpha-2 code")
+	}
+	return country, nil
+}
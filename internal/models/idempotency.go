@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// IdempotencyRecord stores the outcome of a request made with an
+// Idempotency-Key so that retries can be answered with the original response.
+type IdempotencyRecord struct {
+	Key         string
+	Scope       string
+	RequestHash string
+	// StatusCode is zero while the original request is still in progress.
+	StatusCode int
+	Headers    map[string]string
+	Body       []byte
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+}
+
+// Completed reports whether the original response has been stored.
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	JobTypeContactImport = "contact_import"
+
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// Job tracks the progress of a background task.
+type Job struct {
+	ID             int64      `json:"id"`
+	Type           string     `json:"type"`
+	Status         string     `json:"status"`
+	TotalItems     int        `json:"totalItems"`
+	ProcessedItems int        `json:"processedItems"`
+	SucceededItems int        `json:"succeededItems"`
+	SkippedItems   int        `json:"skippedItems"`
+	FailedItems    int        `json:"failedItems"`
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+	FinishedAt     *time.Time `json:"finishedAt,omitempty"`
+}
+
+// JobItemError records why a single item of a job was rejected.
+type JobItemError struct {
+	Item   int      `json:"item"`
+	Reason string   `json:"reason"`
+	Data   []string `json:"data"`
+}
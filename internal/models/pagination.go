@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// ErrInvalidCursor is returned when a cursor cannot be decoded or does not
+// match the requested sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInvalidSort is returned when a list is sorted by an unsupported field.
+var ErrInvalidSort = errors.New("invalid sort field")
+
+// ListOptions controls paging, filtering and sorting of list queries.
+type ListOptions struct {
+	// Limit is the maximum number of items to return.
+	Limit int
+	// Cursor is the opaque position returned with the previous page.
+	Cursor string
+	// Sort names the field to order by, prefixed with "-" for descending order.
+	Sort    string
+	Filters ListFilters
+}
+
+// ListFilters narrows a list query. Zero values are ignored, and each list
+// endpoint applies the filters that make sense for its resource.
+type ListFilters struct {
+	NameContains  string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Status        string
+	Type          string
+	// Audit log filters.
+	Actor        string
+	Action       string
+	ResourceType string
+	ResourceID   int64
+	RequestID    string
+}
+
+// PageLimit returns the effective limit, applying the default and maximum.
+func (o ListOptions) PageLimit() int {
+	switch {
+	case o.Limit <= 0:
+		return DefaultPageLimit
+	case o.Limit > MaxPageLimit:
+		return MaxPageLimit
+	}
+	return o.Limit
+}
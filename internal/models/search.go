@@ -0,0 +1,25 @@
+package models
+
+const (
+	SearchHitSequence = "sequence"
+	SearchHitStep     = "step"
+)
+
+// SearchHit is a ranked full-text search match on a sequence or one of its steps.
+type SearchHit struct {
+	Type     string         `json:"type"`
+	Rank     float64        `json:"rank"`
+	Snippet  string         `json:"snippet"`
+	Sequence SearchSequence `json:"sequence"`
+	Step     *SearchStep    `json:"step,omitempty"`
+}
+
+type SearchSequence struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type SearchStep struct {
+	ID      int64  `json:"id"`
+	Subject string `json:"subject"`
+}
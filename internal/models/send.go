@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	SendStatusPending = "pending"
+	SendStatusSent    = "sent"
+	SendStatusFailed  = "failed"
+)
+
+// Send delivers one step of a sequence to an enrolled contact once it is due.
+type Send struct {
+	ID           int64      `json:"id"`
+	EnrollmentID int64      `json:"enrollmentId"`
+	SequenceID   int64      `json:"sequenceId"`
+	StepID       int64      `json:"stepId"`
+	StepOrder    int        `json:"stepOrder"`
+	Status       string     `json:"status"`
+	DueAt        time.Time  `json:"dueAt"`
+	Attempts     int        `json:"attempts"`
+	LastError    string     `json:"lastError,omitempty"`
+	SentAt       *time.Time `json:"sentAt,omitempty"`
+	// The message to deliver, read from the contact and step.
+	Recipient string `json:"recipient"`
+	Subject   string `json:"subject"`
+	Content   string `json:"content"`
+	// Country is the contact's, whose holidays the next step skips.
+	Country string `json:"country,omitempty"`
+}
@@ -10,9 +10,13 @@ import (
 type Sequence struct {
 	ID                   int64      `json:"id"`
 	Name                 string     `json:"name" validate:"required,min=3,max=255"`
+	ExternalKey          string     `json:"externalKey,omitempty" validate:"omitempty,max=255"`
 	OpenTrackingEnabled  bool       `json:"openTrackingEnabled"`
 	ClickTrackingEnabled bool       `json:"clickTrackingEnabled"`
+	TeamID               *int64     `json:"teamId,omitempty"`
+	Active               bool       `json:"active"`
 	Steps                []Step     `json:"steps" validate:"dive"`
+	Version              int64      `json:"version"`
 	CreatedAt            time.Time  `json:"createdAt"`
 	UpdatedAt            time.Time  `json:"updatedAt"`
 	DeletedAt            *time.Time `json:"deletedAt,omitempty"`
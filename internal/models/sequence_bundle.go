@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+)
+
+// SequenceBundleSchemaVersion is the bundle format written by exports and
+// accepted by imports.
+const SequenceBundleSchemaVersion = 1
+
+// SequenceBundle is a self-contained, portable export of a sequence and its steps.
+type SequenceBundle struct {
+	SchemaVersion int            `json:"schemaVersion"`
+	ExportedAt    time.Time      `json:"exportedAt"`
+	Sequence      BundleSequence `json:"sequence"`
+}
+
+type BundleSequence struct {
+	SourceID    int64          `json:"sourceId,omitempty"`
+	Name        string         `json:"name"`
+	ExternalKey string         `json:"externalKey,omitempty"`
+	Settings    BundleSettings `json:"settings"`
+	Steps       []BundleStep   `json:"steps"`
+}
+
+type BundleSettings struct {
+	OpenTrackingEnabled  bool `json:"openTrackingEnabled"`
+	ClickTrackingEnabled bool `json:"clickTrackingEnabled"`
+}
+
+type BundleStep struct {
+	SourceID  int64  `json:"sourceId,omitempty"`
+	Subject   string `json:"subject"`
+	Content   string `json:"content"`
+	StepOrder int    `json:"stepOrder"`
+	WaitDays  int    `json:"waitDays"`
+	WaitUnit  string `json:"waitUnit,omitempty"`
+}
+
+// NewSequenceBundle builds a bundle from a stored sequence.
+func NewSequenceBundle(sequence *Sequence, exportedAt time.Time) *SequenceBundle {
+	bundle := &SequenceBundle{
+		SchemaVersion: SequenceBundleSchemaVersion,
+		ExportedAt:    exportedAt.UTC(),
+		Sequence: BundleSequence{
+			SourceID:    sequence.ID,
+			Name:        sequence.Name,
+			ExternalKey: sequence.ExternalKey,
+			Settings: BundleSettings{
+				OpenTrackingEnabled:  sequence.OpenTrackingEnabled,
+				ClickTrackingEnabled: sequence.ClickTrackingEnabled,
+			},
+			Steps: []BundleStep{},
+		},
+	}
+	for _, step := range sequence.Steps {
+		bundle.Sequence.Steps = append(bundle.Sequence.Steps, BundleStep{
+			SourceID:  step.ID,
+			Subject:   step.Subject,
+			Content:   step.Content,
+			StepOrder: step.StepOrder,
+			WaitDays:  step.WaitDays,
+			WaitUnit:  step.WaitUnit,
+		})
+	}
+	return bundle
+}
+
+// ToSequence converts the bundle into a new, unsaved sequence. Source IDs are dropped.
+func (b *SequenceBundle) ToSequence() *Sequence {
+	sequence := &Sequence{
+		Name:                 b.Sequence.Name,
+		ExternalKey:          b.Sequence.ExternalKey,
+		OpenTrackingEnabled:  b.Sequence.Settings.OpenTrackingEnabled,
+		ClickTrackingEnabled: b.Sequence.Settings.ClickTrackingEnabled,
+	}
+	for _, step := range b.Sequence.Steps {
+		sequence.Steps = append(sequence.Steps, Step{
+			Subject:   step.Subject,
+			Content:   step.Content,
+			StepOrder: step.StepOrder,
+			WaitDays:  step.WaitDays,
+			WaitUnit:  step.WaitUnit,
+		})
+	}
+	return sequence
+}
+
+// ImportResult reports what an import created, or would create on a dry run,
+// mapping the bundle's source IDs to the new IDs.
+type ImportResult struct {
+	DryRun   bool               `json:"dryRun"`
+	Sequence ImportedResource   `json:"sequence"`
+	Steps    []ImportedResource `json:"steps"`
+}
+
+type ImportedResource struct {
+	SourceID int64  `json:"sourceId,omitempty"`
+	ID       int64  `json:"id,omitempty"`
+	Name     string `json:"name"`
+}
@@ -6,6 +6,13 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
+// Units a step's WaitDays is counted in, despite its name.
+const (
+	WaitUnitDays         = "days"
+	WaitUnitBusinessDays = "businessDays"
+	WaitUnitHours        = "hours"
+)
+
 type Step struct {
 	ID         int64      `json:"id"`
 	SequenceID int64      `json:"sequenceId"`
@@ -13,6 +20,8 @@ type Step struct {
 	Content    string     `json:"content" validate:"required"`
 	StepOrder  int        `json:"stepOrder" validate:"gte=0"`
 	WaitDays   int        `json:"waitDays" validate:"gte=0"`
+	WaitUnit   string     `json:"waitUnit" validate:"omitempty,oneof=days businessDays hours"`
+	Version    int64      `json:"version"`
 	CreatedAt  time.Time  `json:"createdAt"`
 	UpdatedAt  time.Time  `json:"updatedAt"`
 	DeletedAt  *time.Time `json:"deletedAt,omitempty"`
@@ -23,3 +32,12 @@ func (s *Step) Validate() error {
 	validate := validator.New()
 	return validate.Struct(s)
 }
+
+// EffectiveWaitUnit returns the unit of the step's wait, calendar days when
+// none is set.
+func (s *Step) EffectiveWaitUnit() string {
+	if s.WaitUnit == "" {
+		return WaitUnitDays
+	}
+	return s.WaitUnit
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Team roles, from least to most privileged. Viewers can read sequences,
+// editors can change their team's sequences, and owners can also activate
+// and delete them.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleOwner  = "owner"
+)
+
+// RoleRank orders roles by privilege. Unknown roles rank below viewers.
+func RoleRank(role string) int {
+	switch role {
+	case RoleViewer:
+		return 1
+	case RoleEditor:
+		return 2
+	case RoleOwner:
+		return 3
+	}
+	return 0
+}
+
+// User is a person acting through tokens issued by the identity provider.
+// Subject is the token subject the user signs in with.
+type User struct {
+	ID        int64     `json:"id"`
+	Subject   string    `json:"subject" validate:"required,max=255"`
+	Email     string    `json:"email" validate:"omitempty,email,max=320"`
+	Name      string    `json:"name" validate:"max=255"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Validate validates the User struct.
+func (u *User) Validate() error {
+	validate := validator.New()
+	return validate.Struct(u)
+}
+
+// Team groups users. Sequences owned by a team can only be changed by its
+// editors and owners.
+type Team struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name" validate:"required,max=255"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Validate validates the Team struct.
+func (t *Team) Validate() error {
+	validate := validator.New()
+	return validate.Struct(t)
+}
+
+// TeamMember is a user's membership in a team.
+type TeamMember struct {
+	TeamID int64  `json:"teamId"`
+	UserID int64  `json:"userId"`
+	Role   string `json:"role" validate:"required,oneof=viewer editor owner"`
+	User   *User  `json:"user,omitempty"`
+}
+
+// Validate validates the TeamMember struct.
+func (m *TeamMember) Validate() error {
+	validate := validator.New()
+	return validate.Struct(m)
+}
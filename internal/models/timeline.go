@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+const (
+	// TimelineStatusPlanned marks a step that is not scheduled yet; its time
+	// is projected from the step before it.
+	TimelineStatusPlanned = "planned"
+	// TimelineStatusUnscheduled marks a step that will not be sent because
+	// the enrollment ended or an earlier send failed.
+	TimelineStatusUnscheduled = "unscheduled"
+)
+
+// Timeline is when each step of a sequence is, or was, sent to a contact.
+type Timeline struct {
+	// EnrollmentID, ContactID and Status are empty when previewing the
+	// timeline of a contact enrolled now.
+	EnrollmentID int64  `json:"enrollmentId,omitempty"`
+	SequenceID   int64  `json:"sequenceId"`
+	ContactID    int64  `json:"contactId,omitempty"`
+	Status       string `json:"status,omitempty"`
+	// Country is the contact's country, whose holidays are skipped.
+	Country  string         `json:"country,omitempty"`
+	Timezone string         `json:"timezone"`
+	Steps    []TimelineStep `json:"steps"`
+	// Warnings lists what the planned times do not account for.
+	Warnings []string `json:"warnings"`
+}
+
+// TimelineStep is the send of one step. Status is a send status for steps
+// already scheduled, and a timeline status otherwise.
+type TimelineStep struct {
+	StepID    int64  `json:"stepId"`
+	StepOrder int    `json:"stepOrder"`
+	Subject   string `json:"subject"`
+	WaitDays  int    `json:"waitDays"`
+	WaitUnit  string `json:"waitUnit"`
+	Status    string `json:"status"`
+	// PlannedAt is when a step not sent yet is expected to go out.
+	PlannedAt *time.Time `json:"plannedAt,omitempty"`
+	SentAt    *time.Time `json:"sentAt,omitempty"`
+	Attempts  int        `json:"attempts,omitempty"`
+	LastError string     `json:"lastError,omitempty"`
+}
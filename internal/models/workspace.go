@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Workspace is a tenant. Every sequence, step, contact and job belongs to
+// exactly one workspace and is invisible to the others.
+type Workspace struct {
+	ID        int64     `json:"id"`
+	Slug      string    `json:"slug" validate:"required,max=64"`
+	Name      string    `json:"name" validate:"required,max=255"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Validate validates the Workspace struct.
+func (w *Workspace) Validate() error {
+	validate := validator.New()
+	return validate.Struct(w)
+}
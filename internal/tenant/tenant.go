@@ -0,0 +1,18 @@
+// Package tenant carries the workspace a request operates in. Repositories
+// only read and write the data of the workspace bound to their context.
+package tenant
+
+import "context"
+
+type workspaceKey struct{}
+
+// WithWorkspace returns a context bound to the workspace.
+func WithWorkspace(ctx context.Context, workspaceID int64) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, workspaceID)
+}
+
+// WorkspaceFrom returns the workspace bound to ctx, if any.
+func WorkspaceFrom(ctx context.Context) (int64, bool) {
+	workspaceID, ok := ctx.Value(workspaceKey{}).(int64)
+	return workspaceID, ok && workspaceID != 0
+}